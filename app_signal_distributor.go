@@ -24,6 +24,7 @@ type AppSignalDistributor struct {
 	trendAnalyzer *TrendAnalyzer
 	pushService   *NotificationService
 	aggregator    *SignalAggregator
+	clock         Clock
 
 	PersistenceSecs int
 	CooldownMins    int
@@ -56,6 +57,7 @@ func NewAppSignalDistributor(ta *TrendAnalyzer, ns *NotificationService) *AppSig
 		lastPushTime:    make(map[string]time.Time),
 		trendAnalyzer:   ta,
 		pushService:     ns,
+		clock:           RealClock,
 		PersistenceSecs: 5,  // Fast persistence check
 		CooldownMins:    15, // Cooldown
 	}
@@ -63,6 +65,16 @@ func NewAppSignalDistributor(ta *TrendAnalyzer, ns *NotificationService) *AppSig
 	return dist
 }
 
+// SetClock swaps the time source (and the aggregator's)
+func (d *AppSignalDistributor) SetClock(c Clock) {
+	d.mu.Lock()
+	d.clock = clockOrReal(c)
+	d.mu.Unlock()
+	if d.aggregator != nil {
+		d.aggregator.SetClock(c)
+	}
+}
+
 // ProcessSignal is the entry point
 func (d *AppSignalDistributor) ProcessSignal(sig Signal) {
 	d.mu.Lock()
@@ -96,7 +108,7 @@ func (d *AppSignalDistributor) ProcessSignal(sig Signal) {
 	}
 
 	// 4. SIGNAL LOCK (60s Rule)
-	now := time.Now()
+	now := d.clock.Now()
 
 	// Check if we already have an active signal for this symbol
 	if active, ok := d.activeMap[sig.Symbol]; ok {
@@ -106,7 +118,7 @@ func (d *AppSignalDistributor) ProcessSignal(sig Signal) {
 		}
 
 		// If < 60s old, we CANNOT replace it yet.
-		if time.Since(active.PublishTime).Seconds() < 60 {
+		if now.Sub(active.PublishTime).Seconds() < 60 {
 			// If direction opposes, we must ignore the new one (Trend Lock + Stability)
 			if active.Side != sig.Side {
 				return
@@ -133,25 +145,25 @@ func (d *AppSignalDistributor) ProcessSignal(sig Signal) {
 	candidate.Signal = sig
 
 	// Check Persistence
-	if time.Since(candidate.FirstSeen).Seconds() >= float64(d.PersistenceSecs) {
+	if now.Sub(candidate.FirstSeen).Seconds() >= float64(d.PersistenceSecs) {
 		// Check Cooldown (unless it's an update to Active?)
 		// Logic: If Active exists and < 60s, we don't push *new* push.
 		// Actually, let's treat "Distribute" as "Push to Feed".
 
 		if active, isActive := d.activeMap[sig.Symbol]; isActive {
-			if active.Side != sig.Side && time.Since(active.PublishTime).Seconds() < 60 {
+			if active.Side != sig.Side && now.Sub(active.PublishTime).Seconds() < 60 {
 				return // Still locked
 			}
 		} else {
 			// No Active. Check Cooldown.
 			if lastPush, ok := d.lastPushTime[sig.Symbol]; ok {
-				if time.Since(lastPush) < time.Duration(d.CooldownMins)*time.Minute {
+				if now.Sub(lastPush) < time.Duration(d.CooldownMins)*time.Minute {
 					return
 				}
 			}
 		}
 
-		d.distribute(candidate.Signal)
+		d.distribute(candidate.Signal, now)
 
 		// Mark Active
 		d.activeMap[sig.Symbol] = &ActiveSignal{
@@ -166,7 +178,7 @@ func (d *AppSignalDistributor) ProcessSignal(sig Signal) {
 }
 
 // distribute builds the payload
-func (d *AppSignalDistributor) distribute(sig Signal) {
+func (d *AppSignalDistributor) distribute(sig Signal, now time.Time) {
 	stars := 1
 	// Rating Logic
 	if (sig.Side == "LONG" && sig.Trend15M == "BULLISH 🟢") || (sig.Side == "SHORT" && sig.Trend15M == "BEARISH 🔴") {
@@ -193,7 +205,7 @@ func (d *AppSignalDistributor) distribute(sig Signal) {
 	zone := fmt.Sprintf("$%.2f - $%.2f", minEntry, maxEntry)
 
 	// Next Update Timestamp (Publish Time + 60s)
-	nextUpdate := time.Now().Add(60 * time.Second).Unix()
+	nextUpdate := now.Add(60 * time.Second).Unix()
 
 	pubSig := PublicSignal{
 		Symbol:     sig.Symbol,
@@ -201,7 +213,7 @@ func (d *AppSignalDistributor) distribute(sig Signal) {
 		EntryZone:  zone,
 		Stars:      stars,
 		Volatility: "NORMAL", // Simplified
-		Timestamp:  time.Now().Unix(),
+		Timestamp:  now.Unix(),
 		NextUpdate: nextUpdate,
 	}
 
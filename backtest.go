@@ -0,0 +1,689 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// BACKTEST HARNESS (Analyzer + SignalFilter + Predator, Simulated Fills)
+// ============================================================================
+// Drives recorded (FeedRecorder) or synthetic frames through the live parsers
+// on one goroutine, so a run is repeatable. A SimClock follows the frame
+// receive times and is injected into every strategy component; candles for
+// TrendAnalyzer are built from the tape and served by a FakeGateway.
+//
+//   - ANALYZER: ICEBERG signals that pass SignalFilter.Validate and the spoof
+//     re-check (event time, signalSink), sized so the stop loses RiskPerTrade.
+//   - PREDATOR: walls that persist predatorConfirmDelay and pass
+//     evaluateCandidate, bracketed exactly like executeTrade.
+//
+// Fills: entries at the signal price (taker), TP at its limit (maker), stops
+// and timeouts at the trade that crossed them (taker). Only Binance trades
+// drive candles and fills (the venue both engines trade).
+
+const (
+	StrategyAnalyzer = "ANALYZER"
+	StrategyPredator = "PREDATOR"
+
+	backtestMaxCandles = 120
+)
+
+var backtestIntervals = []struct {
+	Name string
+	Ms   int64
+}{
+	{"1m", 60000},
+	{"5m", 300000},
+	{"15m", 900000},
+	{"1h", 3600000},
+}
+
+// BacktestConfig controls a run
+type BacktestConfig struct {
+	Source         string        // Recording dir, or "synthetic"
+	OutDir         string        // backtest.json + CSVs ("" = no files)
+	Symbols        []string      // Universe (liquidation filter)
+	StartingEquity float64       // USDT
+	RiskPerTrade   float64       // ANALYZER: $ lost at the stop
+	TakerFee       float64       // e.g. 0.0005
+	MakerFee       float64       // e.g. 0.0002
+	MaxHold        time.Duration // Still open after this: exit at market (0 = never)
+	Cascade        CascadeConfig
+	Funding        FundingConfig // Entry gate on recorded mark price frames
+	Synthetic      SyntheticConfig
+}
+
+// BacktestTrade is one simulated round trip
+type BacktestTrade struct {
+	ID         int     `json:"id"`
+	Strategy   string  `json:"strategy"`
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Tier       string  `json:"tier"`
+	EntryTime  int64   `json:"entry_time"` // Unix ms
+	ExitTime   int64   `json:"exit_time"`
+	Entry      float64 `json:"entry"`
+	Exit       float64 `json:"exit"`
+	Qty        float64 `json:"qty"`
+	StopLoss   float64 `json:"stop_loss"`
+	TakeProfit float64 `json:"take_profit"`
+	Fees       float64 `json:"fees"`
+	PnL        float64 `json:"pnl"`    // Net of fees
+	Reason     string  `json:"reason"` // TP, SL, TIMEOUT, END
+}
+
+// EquityPoint is the account after a trade closed
+type EquityPoint struct {
+	Time     int64   `json:"time"`
+	Equity   float64 `json:"equity"`
+	Drawdown float64 `json:"drawdown"` // $ below the running peak
+}
+
+// BacktestStats summarizes a set of trades
+type BacktestStats struct {
+	Trades         int     `json:"trades"`
+	Wins           int     `json:"wins"`
+	Losses         int     `json:"losses"`
+	WinRate        float64 `json:"win_rate"` // 0-1
+	NetPnL         float64 `json:"net_pnl"`
+	GrossProfit    float64 `json:"gross_profit"`
+	GrossLoss      float64 `json:"gross_loss"`
+	Expectancy     float64 `json:"expectancy"`    // Mean net PnL per trade
+	ProfitFactor   float64 `json:"profit_factor"` // 0 when there are no losses
+	MaxDrawdown    float64 `json:"max_drawdown"`  // $ peak-to-trough on closed trades
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"`
+}
+
+// BacktestResult is everything a run produced
+type BacktestResult struct {
+	Source     string                    `json:"source"`
+	From       int64                     `json:"from"`
+	To         int64                     `json:"to"`
+	Frames     int64                     `json:"frames"`
+	TapeTrades int64                     `json:"tape_trades"`
+	Alerts     map[string]int            `json:"alerts"` // Analyzer output by type
+	Stats      BacktestStats             `json:"stats"`
+	ByStrategy map[string]*BacktestStats `json:"by_strategy"`
+	BySymbol   map[string]*BacktestStats `json:"by_symbol"`
+	ByTier     map[string]*BacktestStats `json:"by_tier"`
+	Trades     []*BacktestTrade          `json:"trades"`
+	Equity     []EquityPoint             `json:"equity"`
+}
+
+// Backtester replays frames into the strategies and simulates fills
+type Backtester struct {
+	cfg   BacktestConfig
+	clock *SimClock
+	now   int64 // Receive time of the current frame (ms, mirrors clock)
+
+	replay   *ReplayExchange // Frame routing (live parsers)
+	books    *OrderBookManager
+	gateway  *FakeGateway // Candles for TrendAnalyzer
+	analyzer *Analyzer
+	predator *PredatorEngine
+	liq      *LiquidationMonitor
+	funding  *FundingMonitor
+
+	tradeBuf chan Trade // Parser output (drained after every frame)
+	liqBuf   chan Alert
+	dirty    []string // Books changed by the current frame
+	dirtySet map[string]bool
+
+	candles   map[string][]*futures.Kline // "BTCUSDT_1m" -> Oldest first
+	lastPrice map[string]float64          // "BTCUSDT" -> Last Binance trade
+	walls     map[string]*WhaleCandidate  // Predator persistence (event time)
+	cooldowns map[string]int64            // Predator signal debounce (ms)
+	lastSweep int64                       // Last Analyzer cleanup (ms)
+
+	open   []*BacktestTrade // Ordered: closes are deterministic
+	trades []*BacktestTrade
+	nextID int
+
+	equity float64
+	peak   float64
+	curve  []EquityPoint
+
+	first, frames, tapeTrades int64
+	alerts                    map[string]int
+}
+
+// NewBacktester wires the strategies to simulated market data
+func NewBacktester(cfg BacktestConfig) *Backtester {
+	if len(cfg.Symbols) == 0 {
+		cfg.Symbols = DefaultSymbols
+	}
+	registry := NewSymbolRegistry(cfg.Symbols)
+
+	b := &Backtester{
+		cfg:       cfg,
+		clock:     NewSimClock(time.UnixMilli(0)),
+		books:     NewOrderBookManager(),
+		gateway:   NewFakeGateway(),
+		liq:       NewLiquidationMonitor(60*time.Second, cfg.Cascade),
+		tradeBuf:  make(chan Trade, 4096),
+		liqBuf:    make(chan Alert, 256),
+		dirtySet:  make(map[string]bool),
+		candles:   make(map[string][]*futures.Kline),
+		lastPrice: make(map[string]float64),
+		walls:     make(map[string]*WhaleCandidate),
+		cooldowns: make(map[string]int64),
+		equity:    cfg.StartingEquity,
+		peak:      cfg.StartingEquity,
+		alerts:    make(map[string]int),
+	}
+	b.liq.SetClock(b.clock)
+	b.funding = NewFundingMonitor(registry, cfg.Funding, nil)
+	b.funding.SetClock(b.clock)
+	b.replay = NewReplayExchange(cfg.Source, 0, registry)
+	b.replay.Funding = b.funding
+	b.books.ExternalSnapshots = true
+	b.books.OnUpdate(func(symbol string) {
+		if !b.dirtySet[symbol] {
+			b.dirtySet[symbol] = true
+			b.dirty = append(b.dirty, symbol)
+		}
+	})
+
+	trend := NewTrendAnalyzer(b.gateway)
+	trend.RetryDelay = 0
+
+	// Analyzer alerts from background sweeps are not part of the result
+	alertChan := make(chan Alert, 100)
+	go func() {
+		for range alertChan {
+		}
+	}()
+	b.analyzer = NewAnalyzer(alertChan, nil, trend, b.liq, nil, nil, nil, b.books)
+	b.analyzer.SetClock(b.clock)
+	b.analyzer.signalSink = b.onAnalyzerSignal
+	b.analyzer.AttachFunding(b.funding)
+	b.analyzer.cleanupTicker.Stop() // Swept on event time (onFrame)
+
+	risk := NewRiskManager(RiskLimits{}, nil)
+	risk.SetClock(b.clock)
+	b.predator = NewPredatorEngine(b.gateway, trend, 0, 0, nil, 20, 0, 100, nil, b.books, registry, risk)
+	b.predator.SetClock(b.clock)
+	b.predator.AttachFunding(b.funding)
+	return b
+}
+
+// RunBacktest runs cfg end to end and writes the reports
+func RunBacktest(cfg BacktestConfig) (*BacktestResult, error) {
+	b := NewBacktester(cfg)
+
+	if cfg.Source == "synthetic" {
+		GenerateSyntheticFeed(cfg.Synthetic, b.onFrame)
+	} else {
+		files, err := RecordingFiles(cfg.Source)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no recordings in %s", cfg.Source)
+		}
+		for _, path := range files {
+			if err := b.replay.replayFile(path, b.onFrame); err != nil {
+				return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+			}
+		}
+	}
+
+	b.closeAll("END")
+	res := b.result()
+	if cfg.OutDir != "" {
+		if err := res.Write(cfg.OutDir); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// ============================================================================
+// EVENT LOOP
+// ============================================================================
+
+func (b *Backtester) onFrame(frame RecordedFrame) {
+	b.clock.SetMillis(frame.Received)
+	b.now = b.clock.Now().UnixMilli()
+	if b.first == 0 {
+		b.first = b.now
+	}
+	b.frames++
+
+	// Analyzer cleanup (live: every 10s of wall time)
+	if b.now-b.lastSweep >= 10000 {
+		b.analyzer.cleanup()
+		b.lastSweep = b.now
+	}
+
+	// Spoof re-checks that came due read the book as of the previous frame
+	b.analyzer.verifier.ResolveDue()
+
+	b.replay.dispatch(frame, b.tradeBuf, b.liqBuf, b.analyzer)
+
+	for len(b.liqBuf) > 0 {
+		alert := <-b.liqBuf
+		b.liq.AddLiquidation(NormalizeSymbol(alert.Symbol), strings.ToUpper(alert.Data.Side), alert.Data.Notional)
+	}
+	for len(b.tradeBuf) > 0 {
+		b.onTrade(<-b.tradeBuf)
+	}
+	for _, symbol := range b.dirty {
+		b.scanPredator(symbol)
+		delete(b.dirtySet, symbol)
+	}
+	b.dirty = b.dirty[:0]
+}
+
+func (b *Backtester) onTrade(trade Trade) {
+	b.tapeTrades++
+
+	if trade.Exchange == "Binance" {
+		symbol := NormalizeSymbol(trade.Symbol)
+		b.lastPrice[symbol] = trade.Price
+		b.updateCandles(symbol, trade)
+		b.checkExits(symbol, trade.Price)
+	}
+
+	// Validated signals arrive via signalSink (onAnalyzerSignal)
+	if alert := b.analyzer.Analyze(trade); alert.Type != "" {
+		b.alerts[alert.Type]++
+	}
+}
+
+// updateCandles folds a trade into every interval (live klines include the forming candle)
+func (b *Backtester) updateCandles(symbol string, trade Trade) {
+	ts := trade.Timestamp
+	if ts == 0 {
+		ts = b.now
+	}
+	px := strconv.FormatFloat(trade.Price, 'f', -1, 64)
+
+	for _, iv := range backtestIntervals {
+		key := symbol + "_" + iv.Name
+		series := b.candles[key]
+		openTime := ts - ts%iv.Ms
+
+		n := len(series)
+		if n > 0 && series[n-1].OpenTime > openTime {
+			continue // Late trade for a closed candle
+		}
+		if n == 0 || series[n-1].OpenTime < openTime {
+			series = append(series, &futures.Kline{
+				OpenTime:  openTime,
+				CloseTime: openTime + iv.Ms - 1,
+				Open:      px,
+				High:      px,
+				Low:       px,
+				Close:     px,
+			})
+			if len(series) > 2*backtestMaxCandles {
+				series = append([]*futures.Kline(nil), series[len(series)-backtestMaxCandles:]...)
+			}
+			b.candles[key] = series
+			b.gateway.SetKlines(symbol, iv.Name, series)
+			continue
+		}
+
+		k := series[n-1]
+		if high, _ := strconv.ParseFloat(k.High, 64); trade.Price > high {
+			k.High = px
+		}
+		if low, _ := strconv.ParseFloat(k.Low, 64); trade.Price < low {
+			k.Low = px
+		}
+		k.Close = px
+	}
+}
+
+// scanPredator mirrors scanForWhales with event-time persistence
+func (b *Backtester) scanPredator(symbol string) {
+	bids, asks := b.books.LevelsWithin(symbol, predatorWallScanPct)
+	if len(bids) == 0 || len(asks) == 0 {
+		return
+	}
+	now := b.clock.Now()
+
+	wall, found := findWall(bids, asks)
+	candidate := b.walls[symbol]
+	if !found {
+		if candidate != nil && now.Sub(candidate.LastSeen) > predatorFlickerGrace {
+			delete(b.walls, symbol)
+		}
+		return
+	}
+	if candidate == nil || candidate.Side != wall.Side {
+		b.walls[symbol] = &WhaleCandidate{Symbol: symbol, Side: wall.Side, FirstSeen: now, LastSeen: now, Volume: wall.Volume}
+		return
+	}
+	candidate.LastSeen = now
+	candidate.Volume = wall.Volume
+	if now.Sub(candidate.FirstSeen) < predatorConfirmDelay {
+		return
+	}
+
+	pos := b.predator.evaluateCandidate(symbol, wall.Side, wall.Price, candidate.Volume, wall.Ratio)
+	if pos == nil {
+		return
+	}
+	delete(b.walls, symbol)
+	b.openPredatorTrade(pos)
+}
+
+// ============================================================================
+// SIMULATED FILLS
+// ============================================================================
+
+// onAnalyzerSignal sizes an Analyzer signal like the ExecutionService (risk at the stop)
+func (b *Backtester) onAnalyzerSignal(sig Signal) {
+	dist := math.Abs(sig.Entry - sig.StopLoss)
+	if dist == 0 || sig.Entry == 0 {
+		return
+	}
+	tier := sig.Label
+	if tier == "" {
+		tier = "No Trend Data"
+	}
+	b.openTrade(StrategyAnalyzer, NormalizeSymbol(sig.Symbol), sig.Side, tier, sig.Entry, b.cfg.RiskPerTrade/dist, sig.StopLoss, sig.Target)
+}
+
+// openPredatorTrade applies executeTrade's slippage guard and brackets
+func (b *Backtester) openPredatorTrade(pos *PredatorPosition) {
+	symbol := NormalizeSymbol(pos.Symbol)
+	if until, ok := b.cooldowns[symbol]; ok && b.now < until {
+		return
+	}
+
+	entry := pos.Entry
+	if last, ok := b.lastPrice[symbol]; ok && last > 0 {
+		if math.Abs(last-entry)/entry > predatorMaxSlippage {
+			return // SLIPPAGE GUARD
+		}
+		entry = last
+	}
+	if entry <= 0 || pos.MarginUsed <= 0 {
+		return
+	}
+
+	qty := pos.MarginUsed / entry
+	tpDist := b.predator.CalculateNetTP(entry, qty, pos.TakeProfit)
+	stopDist := predatorStopRisk / qty
+	tp, sl := entry+tpDist, entry-stopDist
+	if pos.Side == "SHORT" {
+		tp, sl = entry-tpDist, entry+stopDist
+	}
+
+	if b.openTrade(StrategyPredator, symbol, pos.Side, pos.Tier, entry, qty, sl, tp) {
+		b.cooldowns[symbol] = b.now + predatorTradeCooldown.Milliseconds()
+	}
+}
+
+// openTrade fills a market entry (one position per strategy + symbol)
+func (b *Backtester) openTrade(strategy, symbol, side, tier string, entry, qty, sl, tp float64) bool {
+	for _, t := range b.open {
+		if t.Strategy == strategy && t.Symbol == symbol {
+			return false
+		}
+	}
+	b.nextID++
+	b.open = append(b.open, &BacktestTrade{
+		ID:         b.nextID,
+		Strategy:   strategy,
+		Symbol:     symbol,
+		Side:       side,
+		Tier:       tier,
+		EntryTime:  b.now,
+		Entry:      entry,
+		Qty:        qty,
+		StopLoss:   sl,
+		TakeProfit: tp,
+		Fees:       entry * qty * b.cfg.TakerFee,
+	})
+	return true
+}
+
+// checkExits fills stops, targets and timeouts against a Binance trade
+func (b *Backtester) checkExits(symbol string, price float64) {
+	kept := b.open[:0]
+	for _, t := range b.open {
+		if t.Symbol != symbol {
+			kept = append(kept, t)
+			continue
+		}
+
+		long := t.Side == "LONG"
+		switch {
+		case (long && price <= t.StopLoss) || (!long && price >= t.StopLoss):
+			b.closeTrade(t, price, b.cfg.TakerFee, "SL")
+		case (long && price >= t.TakeProfit) || (!long && price <= t.TakeProfit):
+			b.closeTrade(t, t.TakeProfit, b.cfg.MakerFee, "TP")
+		case b.cfg.MaxHold > 0 && b.now-t.EntryTime >= b.cfg.MaxHold.Milliseconds():
+			b.closeTrade(t, price, b.cfg.TakerFee, "TIMEOUT")
+		default:
+			kept = append(kept, t)
+		}
+	}
+	b.open = kept
+}
+
+// closeAll exits whatever is open at the last seen price
+func (b *Backtester) closeAll(reason string) {
+	for _, t := range b.open {
+		price := b.lastPrice[t.Symbol]
+		if price == 0 {
+			price = t.Entry
+		}
+		b.closeTrade(t, price, b.cfg.TakerFee, reason)
+	}
+	b.open = nil
+}
+
+func (b *Backtester) closeTrade(t *BacktestTrade, exit, feeRate float64, reason string) {
+	t.Exit = exit
+	t.ExitTime = b.now
+	t.Reason = reason
+	t.Fees += exit * t.Qty * feeRate
+
+	gross := (exit - t.Entry) * t.Qty
+	if t.Side == "SHORT" {
+		gross = -gross
+	}
+	t.PnL = gross - t.Fees
+	b.trades = append(b.trades, t)
+
+	b.equity += t.PnL
+	b.peak = math.Max(b.peak, b.equity)
+	b.curve = append(b.curve, EquityPoint{Time: b.now, Equity: b.equity, Drawdown: b.peak - b.equity})
+
+	// Predator strike penalty / safety mode (recordClose)
+	if t.Strategy == StrategyPredator {
+		if t.PnL < 0 {
+			b.predator.ConsecutiveLosses++
+			if b.predator.ConsecutiveLosses >= 3 {
+				b.predator.SafetyModeUntil = b.clock.Now().Add(predatorLockdown)
+				b.predator.ConsecutiveLosses = 0
+			}
+		} else {
+			b.predator.ConsecutiveLosses = 0
+		}
+	}
+}
+
+// ============================================================================
+// REPORTS
+// ============================================================================
+
+func (b *Backtester) result() *BacktestResult {
+	res := &BacktestResult{
+		Source:     b.cfg.Source,
+		From:       b.first,
+		To:         b.now,
+		Frames:     b.frames,
+		TapeTrades: b.tapeTrades,
+		Alerts:     b.alerts,
+		Stats:      computeBacktestStats(b.trades, b.cfg.StartingEquity),
+		ByStrategy: groupBacktestStats(b.trades, b.cfg.StartingEquity, func(t *BacktestTrade) string { return t.Strategy }),
+		BySymbol:   groupBacktestStats(b.trades, b.cfg.StartingEquity, func(t *BacktestTrade) string { return t.Symbol }),
+		ByTier:     groupBacktestStats(b.trades, b.cfg.StartingEquity, func(t *BacktestTrade) string { return t.Strategy + " " + t.Tier }),
+		Trades:     b.trades,
+		Equity:     b.curve,
+	}
+	if res.Trades == nil {
+		res.Trades = []*BacktestTrade{}
+	}
+	if res.Equity == nil {
+		res.Equity = []EquityPoint{}
+	}
+	return res
+}
+
+// computeBacktestStats walks trades in close order (drawdown on closed equity)
+func computeBacktestStats(trades []*BacktestTrade, startingEquity float64) BacktestStats {
+	var st BacktestStats
+	equity, peak := startingEquity, startingEquity
+	for _, t := range trades {
+		st.Trades++
+		st.NetPnL += t.PnL
+		if t.PnL > 0 {
+			st.Wins++
+			st.GrossProfit += t.PnL
+		} else {
+			st.Losses++
+			st.GrossLoss += -t.PnL
+		}
+
+		equity += t.PnL
+		peak = math.Max(peak, equity)
+		if dd := peak - equity; dd > st.MaxDrawdown {
+			st.MaxDrawdown = dd
+			if peak > 0 {
+				st.MaxDrawdownPct = dd / peak * 100
+			}
+		}
+	}
+	if st.Trades > 0 {
+		st.WinRate = float64(st.Wins) / float64(st.Trades)
+		st.Expectancy = st.NetPnL / float64(st.Trades)
+	}
+	if st.GrossLoss > 0 {
+		st.ProfitFactor = st.GrossProfit / st.GrossLoss
+	}
+	return st
+}
+
+func groupBacktestStats(trades []*BacktestTrade, startingEquity float64, key func(*BacktestTrade) string) map[string]*BacktestStats {
+	groups := make(map[string][]*BacktestTrade)
+	for _, t := range trades {
+		groups[key(t)] = append(groups[key(t)], t)
+	}
+	res := make(map[string]*BacktestStats, len(groups))
+	for k, ts := range groups {
+		st := computeBacktestStats(ts, startingEquity)
+		res[k] = &st
+	}
+	return res
+}
+
+// Write saves backtest.json, trades.csv, equity.csv and breakdown.csv to dir
+func (res *BacktestResult) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "backtest.json"), data, 0o644); err != nil {
+		return err
+	}
+
+	f2 := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	ts := func(ms int64) string { return time.UnixMilli(ms).UTC().Format("2006-01-02T15:04:05.000Z") }
+
+	trades := [][]string{{"id", "strategy", "symbol", "side", "tier", "entry_time", "exit_time", "entry", "exit", "qty", "stop_loss", "take_profit", "fees", "pnl", "reason"}}
+	for _, t := range res.Trades {
+		trades = append(trades, []string{
+			strconv.Itoa(t.ID), t.Strategy, t.Symbol, t.Side, t.Tier, ts(t.EntryTime), ts(t.ExitTime),
+			f2(t.Entry), f2(t.Exit), f2(t.Qty), f2(t.StopLoss), f2(t.TakeProfit), f2(t.Fees), f2(t.PnL), t.Reason,
+		})
+	}
+	if err := writeCSV(filepath.Join(dir, "trades.csv"), trades); err != nil {
+		return err
+	}
+
+	equity := [][]string{{"time", "equity", "drawdown"}}
+	for _, p := range res.Equity {
+		equity = append(equity, []string{ts(p.Time), f2(p.Equity), f2(p.Drawdown)})
+	}
+	if err := writeCSV(filepath.Join(dir, "equity.csv"), equity); err != nil {
+		return err
+	}
+
+	breakdown := [][]string{{"group", "key", "trades", "wins", "losses", "win_rate", "net_pnl", "expectancy", "profit_factor", "max_drawdown"}}
+	addRow := func(group, key string, st *BacktestStats) {
+		breakdown = append(breakdown, []string{
+			group, key, strconv.Itoa(st.Trades), strconv.Itoa(st.Wins), strconv.Itoa(st.Losses),
+			f2(st.WinRate), f2(st.NetPnL), f2(st.Expectancy), f2(st.ProfitFactor), f2(st.MaxDrawdown),
+		})
+	}
+	addRow("total", "ALL", &res.Stats)
+	for _, g := range []struct {
+		name  string
+		stats map[string]*BacktestStats
+	}{{"strategy", res.ByStrategy}, {"symbol", res.BySymbol}, {"tier", res.ByTier}} {
+		keys := make([]string, 0, len(g.stats))
+		for k := range g.stats {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			addRow(g.name, k, g.stats[k])
+		}
+	}
+	return writeCSV(filepath.Join(dir, "breakdown.csv"), breakdown)
+}
+
+func writeCSV(path string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Summary is a short human-readable report
+func (res *BacktestResult) Summary(w io.Writer) {
+	st := res.Stats
+	fmt.Fprintf(w, "📊 BACKTEST: %s | %s -> %s | %d frames, %d tape trades\n", res.Source,
+		time.UnixMilli(res.From).UTC().Format(time.RFC3339), time.UnixMilli(res.To).UTC().Format(time.RFC3339), res.Frames, res.TapeTrades)
+	fmt.Fprintf(w, "   Trades %d | Win Rate %.1f%% | Expectancy $%.2f | Net $%.2f | PF %.2f | Max DD $%.2f (%.2f%%)\n",
+		st.Trades, st.WinRate*100, st.Expectancy, st.NetPnL, st.ProfitFactor, st.MaxDrawdown, st.MaxDrawdownPct)
+
+	keys := make([]string, 0, len(res.ByTier))
+	for k := range res.ByTier {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		t := res.ByTier[k]
+		fmt.Fprintf(w, "   %-40s %4d trades | %5.1f%% | $%.2f\n", k, t.Trades, t.WinRate*100, t.NetPnL)
+	}
+}
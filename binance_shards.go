@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// BINANCE STREAM SHARDING (SUBSCRIBE / UNSUBSCRIBE over N Sockets)
+// ============================================================================
+// Every symbol needs two streams (aggTrade + depth). Instead of one URL with
+// all of them, symbols are packed into shards of StreamsPerConn streams. Each
+// shard is its own supervised feed ("Binance #2"), dials the bare /stream
+// endpoint and subscribes with the JSON method, so a disconnect only blacks
+// out that shard's symbols.
+//
+// Assignments are sticky: a registry change UNSUBSCRIBEs dropped symbols and
+// SUBSCRIBEs new ones on live sockets (filling free slots first) instead of
+// reconnecting everything. Empty shards are stopped.
+
+const (
+	binanceStreamURL       = "wss://fstream.binance.com/stream"
+	binanceStreamsPerConn  = 200                    // Default budget (Binance allows 1024)
+	binanceStreamsPerSym   = 2                      // aggTrade + depth
+	binanceSubscribeBatch  = 100                    // Params per SUBSCRIBE message
+	binanceControlInterval = 150 * time.Millisecond // Binance: max 10 incoming messages/s
+)
+
+// binanceShard is one socket's slice of the symbol universe
+type binanceShard struct {
+	id   int
+	stop chan struct{}
+
+	mu      sync.Mutex
+	conn    *websocket.Conn // nil while (re)connecting
+	symbols map[string]bool // Desired symbols ("BTCUSDT")
+	nextID  int64           // Request id for SUBSCRIBE / UNSUBSCRIBE
+}
+
+// binanceControlResp is the reply to a SUBSCRIBE / UNSUBSCRIBE request
+type binanceControlResp struct {
+	ID    int64 `json:"id"`
+	Error *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+func (b *BinanceFutures) Start(out chan<- Trade, analyzer *Analyzer) {
+	budget := b.StreamsPerConn
+	if budget <= 0 {
+		budget = binanceStreamsPerConn
+	}
+	perShard := budget / binanceStreamsPerSym
+	if perShard < 1 {
+		perShard = 1
+	}
+
+	changes := b.Registry.Subscribe()
+	var shards []*binanceShard
+	nextID := 1
+
+	for {
+		drainChanges(changes)
+		shards = b.reshard(shards, b.Registry.Symbols(), perShard, &nextID, out, analyzer)
+		<-changes
+	}
+}
+
+// reshard diffs the shard assignments against symbols and starts / stops shards
+func (b *BinanceFutures) reshard(shards []*binanceShard, symbols []string, perShard int, nextID *int, out chan<- Trade, analyzer *Analyzer) []*binanceShard {
+	wanted := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		wanted[s] = true
+	}
+
+	// 1. Drop symbols that left the universe
+	assigned := make(map[string]bool)
+	for _, sh := range shards {
+		var removed []string
+		sh.mu.Lock()
+		for s := range sh.symbols {
+			if wanted[s] {
+				assigned[s] = true
+			} else {
+				removed = append(removed, s)
+			}
+		}
+		sh.mu.Unlock()
+		if len(removed) > 0 {
+			sh.update(nil, removed)
+		}
+	}
+
+	var added []string
+	for _, s := range symbols {
+		if !assigned[s] {
+			added = append(added, s)
+		}
+	}
+
+	// 2. Fill free slots on existing shards
+	for _, sh := range shards {
+		if len(added) == 0 {
+			break
+		}
+		sh.mu.Lock()
+		free := perShard - len(sh.symbols)
+		sh.mu.Unlock()
+		if free <= 0 {
+			continue
+		}
+		if free > len(added) {
+			free = len(added)
+		}
+		sh.update(added[:free], nil)
+		added = added[free:]
+	}
+
+	// 3. Stop empty shards
+	kept := shards[:0]
+	for _, sh := range shards {
+		sh.mu.Lock()
+		empty := len(sh.symbols) == 0
+		sh.mu.Unlock()
+		if empty {
+			close(sh.stop)
+			log.Printf("[Binance #%d] Shard empty. Stopping.", sh.id)
+			continue
+		}
+		kept = append(kept, sh)
+	}
+	shards = kept
+
+	// 4. Open new shards for the rest
+	for len(added) > 0 {
+		n := perShard
+		if n > len(added) {
+			n = len(added)
+		}
+		sh := &binanceShard{id: *nextID, stop: make(chan struct{}), symbols: make(map[string]bool)}
+		*nextID++
+		for _, s := range added[:n] {
+			sh.symbols[s] = true
+		}
+		added = added[n:]
+		shards = append(shards, sh)
+		go b.runShard(sh, out, analyzer)
+	}
+
+	log.Printf("[Binance] %d coins across %d shard(s) (%d streams per socket)", len(symbols), len(shards), perShard*binanceStreamsPerSym)
+	return shards
+}
+
+// runShard supervises one shard socket until the shard is stopped
+func (b *BinanceFutures) runShard(sh *binanceShard, out chan<- Trade, analyzer *Analyzer) {
+	tag := fmt.Sprintf("Binance #%d", sh.id)
+
+	Feeds.Run(FeedSpec{
+		Name: tag,
+		Stop: sh.stop,
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(binanceStreamURL, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// Subscribe under the lock so a concurrent reshard can't interleave writes
+			sh.mu.Lock()
+			symbols := sh.sortedLocked()
+			if err := sh.sendLocked(conn, "SUBSCRIBE", binanceStreamsFor(symbols)); err != nil {
+				sh.mu.Unlock()
+				conn.Close()
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
+			}
+			sh.conn = conn
+			sh.mu.Unlock()
+
+			log.Printf("[%s] Connected (%d coins + Depth)", tag, len(symbols))
+			return conn, func() {
+				sh.mu.Lock()
+				if sh.conn == conn {
+					sh.conn = nil
+				}
+				sh.mu.Unlock()
+			}, nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Binance", "futures", message)
+			b.handleFrame(message, out, analyzer)
+		},
+	})
+}
+
+// update applies a symbol diff; a live socket gets SUBSCRIBE / UNSUBSCRIBE,
+// a down one picks the new set up on its next connect
+func (sh *binanceShard) update(add, remove []string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	for _, s := range remove {
+		delete(sh.symbols, s)
+	}
+	for _, s := range add {
+		sh.symbols[s] = true
+	}
+	if sh.conn == nil {
+		return
+	}
+
+	if len(remove) > 0 {
+		if err := sh.sendLocked(sh.conn, "UNSUBSCRIBE", binanceStreamsFor(remove)); err != nil {
+			log.Printf("[Binance #%d] Unsubscribe error: %v. Reconnecting...", sh.id, err)
+			sh.conn.Close()
+			return
+		}
+	}
+	if len(add) > 0 {
+		if err := sh.sendLocked(sh.conn, "SUBSCRIBE", binanceStreamsFor(add)); err != nil {
+			log.Printf("[Binance #%d] Subscribe error: %v. Reconnecting...", sh.id, err)
+			sh.conn.Close()
+			return
+		}
+	}
+	log.Printf("[Binance #%d] Resubscribed (+%d / -%d coins, %d total)", sh.id, len(add), len(remove), len(sh.symbols))
+}
+
+// sendLocked writes the request in batches, paced under the control message limit
+func (sh *binanceShard) sendLocked(conn *websocket.Conn, method string, streams []string) error {
+	for i := 0; i < len(streams); i += binanceSubscribeBatch {
+		end := i + binanceSubscribeBatch
+		if end > len(streams) {
+			end = len(streams)
+		}
+		if i > 0 {
+			time.Sleep(binanceControlInterval)
+		}
+		sh.nextID++
+		req := map[string]interface{}{
+			"method": method,
+			"params": streams[i:end],
+			"id":     sh.nextID,
+		}
+		if err := conn.WriteJSON(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sh *binanceShard) sortedLocked() []string {
+	out := make([]string, 0, len(sh.symbols))
+	for s := range sh.symbols {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// binanceStreamsFor maps symbols to their aggTrade + depth stream names
+func binanceStreamsFor(symbols []string) []string {
+	streams := make([]string, 0, len(symbols)*binanceStreamsPerSym)
+	for _, s := range symbols {
+		s = strings.ToLower(s)
+		streams = append(streams, s+"@aggTrade", s+"@depth@100ms")
+	}
+	return streams
+}
+
+// logControlResp reports a rejected SUBSCRIBE / UNSUBSCRIBE (acks are silent)
+func logControlResp(message []byte) {
+	var resp binanceControlResp
+	if err := json.Unmarshal(message, &resp); err != nil || resp.Error == nil {
+		return
+	}
+	log.Printf("⚠️ [Binance] Request %d rejected: %s (code %d)", resp.ID, resp.Error.Msg, resp.Error.Code)
+}
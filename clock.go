@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// CLOCK (Wall Time Live, Event Time in Replay / Backtest)
+// ============================================================================
+// Everything that debounces, expires or persists on a timer reads the time
+// from an injected Clock instead of time.Now. Live components default to
+// RealClock; replay and backtests drive a SimClock from the frame timestamps,
+// so the same input always produces the same decisions.
+//
+// Tickers that only decide WHEN to run a check stay on wall time. The check
+// itself reads the Clock.
+
+// Clock is the time source for time-based logic
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// RealClock is the wall clock (default for every component)
+var RealClock Clock = realClock{}
+
+// clockOrReal guards SetClock(nil)
+func clockOrReal(c Clock) Clock {
+	if c == nil {
+		return RealClock
+	}
+	return c
+}
+
+// SimClock is a manually advanced clock (never moves backwards)
+type SimClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewSimClock creates a simulated clock starting at start
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+// Now returns the simulated time
+func (c *SimClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Set moves the clock to t (ignored if t is earlier: feeds interleave slightly out of order)
+func (c *SimClock) Set(t time.Time) {
+	c.mu.Lock()
+	if t.After(c.now) {
+		c.now = t
+	}
+	c.mu.Unlock()
+}
+
+// SetMillis is Set for Unix millisecond event timestamps
+func (c *SimClock) SetMillis(ms int64) {
+	c.Set(time.UnixMilli(ms))
+}
+
+// Advance moves the clock forward by d
+func (c *SimClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
@@ -39,17 +39,21 @@ type CoPilotService struct {
 	sessions      map[string]*TradeSession
 	trendAnalyzer *TrendAnalyzer
 	distributor   *AppSignalDistributor // To push updates to app
+	books         *OrderBookManager     // Local order books (replaces REST depth)
+	clock         Clock
 
 	// Cache for recent whales to check against trades
 	recentWhales map[string]Trade // Symbol -> Last Huge Whale
 }
 
 // NewCoPilotService creates the advisor
-func NewCoPilotService(ta *TrendAnalyzer, dist *AppSignalDistributor) *CoPilotService {
+func NewCoPilotService(ta *TrendAnalyzer, dist *AppSignalDistributor, books *OrderBookManager) *CoPilotService {
 	cp := &CoPilotService{
 		sessions:      make(map[string]*TradeSession),
 		trendAnalyzer: ta,
 		distributor:   dist,
+		books:         books,
+		clock:         RealClock,
 		recentWhales:  make(map[string]Trade),
 	}
 
@@ -59,6 +63,13 @@ func NewCoPilotService(ta *TrendAnalyzer, dist *AppSignalDistributor) *CoPilotSe
 	return cp
 }
 
+// SetClock swaps the time source (session timers and hysteresis)
+func (cp *CoPilotService) SetClock(c Clock) {
+	cp.mu.Lock()
+	cp.clock = clockOrReal(c)
+	cp.mu.Unlock()
+}
+
 // TrackPublicSession is the entry point for "I'm In" logic
 func (cp *CoPilotService) TrackPublicSession(userID, symbol, side string, entryPrice float64) string {
 	return cp.StartSession(userID, symbol, side, entryPrice)
@@ -69,14 +80,15 @@ func (cp *CoPilotService) StartSession(userID, symbol, side string, entryPrice f
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
-	sessionID := fmt.Sprintf("%s-%d", symbol, time.Now().UnixNano())
+	now := cp.clock.Now()
+	sessionID := fmt.Sprintf("%s-%d", symbol, now.UnixNano())
 	cp.sessions[sessionID] = &TradeSession{
 		ID:         sessionID,
 		UserID:     userID,
 		Symbol:     NormalizeSymbol(symbol),
 		EntryPrice: entryPrice,
 		Side:       side,
-		StartTime:  time.Now(),
+		StartTime:  now,
 		LastAdvice: AdviceNeutral,
 		Reason:     "Initializing Co-Pilot...",
 	}
@@ -128,13 +140,15 @@ func (cp *CoPilotService) checkSessions() {
 }
 
 func (cp *CoPilotService) evaluateSession(s *TradeSession) (string, string) {
+	now := cp.clock.Now()
+
 	// 1. GET CURRENT PRICE (Using recent whales or direct fetch fallback)
 	currentPrice := s.EntryPrice
 	if lastTrade, ok := cp.recentWhales[s.Symbol]; ok {
 		currentPrice = lastTrade.Price
 	} else {
 		// Fallback: Check ListPrices (Heavy but needed if no trade stream yet)
-		prices, err := cp.trendAnalyzer.client.NewListPricesService().Symbol(s.Symbol).Do(context.Background())
+		prices, err := cp.trendAnalyzer.gateway.Prices(context.Background(), s.Symbol)
 		if err == nil && len(prices) > 0 {
 			currentPrice, _ = strconv.ParseFloat(prices[0].Price, 64)
 		}
@@ -156,18 +170,18 @@ func (cp *CoPilotService) evaluateSession(s *TradeSession) (string, string) {
 		isOpposite := (s.Side == "LONG" && lastWhale.Side == "sell") || (s.Side == "SHORT" && lastWhale.Side == "buy")
 		isHuge := lastWhale.Notional > 500000
 
-		if isOpposite && isHuge && time.Since(time.UnixMilli(lastWhale.Timestamp)).Seconds() < 60 {
+		if isOpposite && isHuge && now.Sub(time.UnixMilli(lastWhale.Timestamp)).Seconds() < 60 {
 			// Whale is recent (<60s). Start/Check Timer.
 			if s.BearishStartTime.IsZero() {
-				s.BearishStartTime = time.Now() // Start Timer
+				s.BearishStartTime = now // Start Timer
 				return AdviceWarning, "⚠️ Measuring Selling Pressure... (Standby)"
 			} else {
 				// Timer Running
-				if time.Since(s.BearishStartTime).Seconds() > 10 {
+				if now.Sub(s.BearishStartTime).Seconds() > 10 {
 					// Sustained for > 10s. EXIT.
 					return AdviceExit, fmt.Sprintf("🚨 WHALE DUMP CONFIRMED ($%.1fM). EXIT NOW.", lastWhale.Notional/1000000)
 				}
-				return AdviceWarning, fmt.Sprintf("⚠️ Selling Pressure Detected... Hold (%ds)", int(10-time.Since(s.BearishStartTime).Seconds()))
+				return AdviceWarning, fmt.Sprintf("⚠️ Selling Pressure Detected... Hold (%ds)", int(10-now.Sub(s.BearishStartTime).Seconds()))
 			}
 		} else {
 			// No threat currently. Reset Timer.
@@ -197,7 +211,7 @@ func (cp *CoPilotService) evaluateSession(s *TradeSession) (string, string) {
 	}
 
 	// 6. FEE SAVER (Price Escaping - First 60s)
-	if time.Since(s.StartTime).Seconds() < 60 {
+	if now.Sub(s.StartTime).Seconds() < 60 {
 		if pnl > 0.1 {
 			return AdviceWarning, "⚠️ Price escaping. Limit update recommended."
 		}
@@ -229,7 +243,7 @@ type SmartTradeParams struct {
 // GetSmartEntry calculates optimal entry, SL, and TP
 func (cp *CoPilotService) GetSmartEntry(symbol, side string) SmartTradeParams {
 	// 1. Fetch Price
-	prices, err := cp.trendAnalyzer.client.NewListPricesService().Symbol(symbol).Do(context.Background())
+	prices, err := cp.trendAnalyzer.gateway.Prices(context.Background(), symbol)
 	if err != nil || len(prices) == 0 {
 		return SmartTradeParams{}
 	}
@@ -253,16 +267,15 @@ func (cp *CoPilotService) GetSmartEntry(symbol, side string) SmartTradeParams {
 	// We look for walls *between* entry and standard SL, or just beyond standard SL?
 	// Prompt: "If ICEBERG detected... place SL $5.00 above/below".
 	// We scan depth.
-	depth, err := cp.trendAnalyzer.client.NewDepthService().Symbol(symbol).Limit(20).Do(context.Background())
-	if err == nil {
+	bids, asks := cp.bookLevels(symbol, 0.01)
+	if len(bids) > 0 && len(asks) > 0 {
 		threshold := 500000.0 // > $500k
 
 		if side == "LONG" {
 			// Look for BUY walls (Support) below entry
-			for _, bid := range depth.Bids {
-				price, _ := strconv.ParseFloat(bid.Price, 64)
-				qty, _ := strconv.ParseFloat(bid.Quantity, 64)
-				if price*qty > threshold {
+			for _, bid := range bids {
+				price := bid.Price
+				if price*bid.Qty > threshold {
 					// If Wall is close to our SL (e.g. within 0.1%), use it as anchor
 					// Logic: SL should be BELOW the Wall (Support).
 					// Prompt said "Above", but for Long Support, "Above" exposes you to being stopped before wall holds.
@@ -275,10 +288,9 @@ func (cp *CoPilotService) GetSmartEntry(symbol, side string) SmartTradeParams {
 			}
 		} else {
 			// Look for SELL walls (Resistance) above entry
-			for _, ask := range depth.Asks {
-				price, _ := strconv.ParseFloat(ask.Price, 64)
-				qty, _ := strconv.ParseFloat(ask.Quantity, 64)
-				if price*qty > threshold {
+			for _, ask := range asks {
+				price := ask.Price
+				if price*ask.Qty > threshold {
 					if price > entry && price < (entry*1.01) {
 						sl = price + 5.0
 						break
@@ -293,29 +305,27 @@ func (cp *CoPilotService) GetSmartEntry(symbol, side string) SmartTradeParams {
 
 // GetWallAdvice analysis order book for walls near the recommended entry
 func (cp *CoPilotService) GetWallAdvice(symbol, side string, entryPrice float64) string {
-	// Fetch Depth
-	depth, err := cp.trendAnalyzer.client.NewDepthService().Symbol(symbol).Limit(10).Do(context.Background())
-	if err != nil {
+	// Read Local Book (mid +/- 0.5% so an entry off mid is still covered);
+	// only walls within entryBand of the entry count
+	const entryBand = 0.002 // +/- 0.2% of entry
+	bids, asks := cp.bookLevels(symbol, 0.005)
+	if len(bids) == 0 || len(asks) == 0 {
 		return "Analyzing liquidity..."
 	}
 
 	threshold := 500000.0 // > $500k wall
 
 	if side == "LONG" {
-		for _, bid := range depth.Bids {
-			price, _ := strconv.ParseFloat(bid.Price, 64)
-			qty, _ := strconv.ParseFloat(bid.Quantity, 64)
-			notional := price * qty
-			if notional > threshold && Abs(price-entryPrice)/entryPrice < 0.002 {
+		for _, bid := range bids {
+			notional := bid.Price * bid.Qty
+			if notional > threshold && Abs(bid.Price-entryPrice)/entryPrice < entryBand {
 				return "🐳 Huge Buy Wall at entry. High chance of fill."
 			}
 		}
 	} else {
-		for _, ask := range depth.Asks {
-			price, _ := strconv.ParseFloat(ask.Price, 64)
-			qty, _ := strconv.ParseFloat(ask.Quantity, 64)
-			notional := price * qty
-			if notional > threshold && Abs(price-entryPrice)/entryPrice < 0.002 {
+		for _, ask := range asks {
+			notional := ask.Price * ask.Qty
+			if notional > threshold && Abs(ask.Price-entryPrice)/entryPrice < entryBand {
 				return "🐳 Huge Sell Wall at entry. High chance of fill."
 			}
 		}
@@ -323,6 +333,14 @@ func (cp *CoPilotService) GetWallAdvice(symbol, side string, entryPrice float64)
 	return "Liquidity normal."
 }
 
+// bookLevels reads levels around mid from the local order book (nil if not synced)
+func (cp *CoPilotService) bookLevels(symbol string, pct float64) (bids, asks []BookLevel) {
+	if cp.books == nil {
+		return nil, nil
+	}
+	return cp.books.LevelsWithin(symbol, pct)
+}
+
 // Helper for Abs
 func Abs(x float64) float64 {
 	if x < 0 {
@@ -333,22 +351,26 @@ func Abs(x float64) float64 {
 
 // checkLiquidityThin checks if support is weak against the user's position
 func (cp *CoPilotService) checkLiquidityThin(symbol, userSide string) bool {
-	// Fetch Depth (Top 20 levels)
-	depth, err := cp.trendAnalyzer.client.NewDepthService().Symbol(symbol).Limit(20).Do(context.Background())
-	if err != nil {
+	// Read Local Book (Top 20 levels)
+	bids, asks := cp.bookLevels(symbol, 0.01)
+	if len(bids) == 0 || len(asks) == 0 {
 		return false // Assume safe if data missing
 	}
+	if len(bids) > 20 {
+		bids = bids[:20]
+	}
+	if len(asks) > 20 {
+		asks = asks[:20]
+	}
 
 	var supportVol, resistanceVol float64
 
 	// Sum Bids and Asks
-	for _, bid := range depth.Bids {
-		qty, _ := strconv.ParseFloat(bid.Quantity, 64)
-		supportVol += qty
+	for _, bid := range bids {
+		supportVol += bid.Qty
 	}
-	for _, ask := range depth.Asks {
-		qty, _ := strconv.ParseFloat(ask.Quantity, 64)
-		resistanceVol += qty
+	for _, ask := range asks {
+		resistanceVol += ask.Qty
 	}
 
 	// Logic: If I am LONG, I need Support (Bids). If Bids < Asks * 0.5, it's thin.
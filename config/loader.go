@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +18,65 @@ type Config struct {
 	MaxConcurrent      int
 	Leverage           int
 	TotalNotionalLimit float64
+	PredatorDailyLoss  float64 // Predator's share of the daily loss limit (USDT)
+
+	// Liquidation Cascade Detection
+	LiqCascadeWindowSec   int     // Sliding window in seconds
+	LiqCascadeMinNotional float64 // USD liquidated in one direction
+	LiqCascadeMinCount    int     // Minimum liquidation events
+
+	// Symbol Universe
+	Symbols      []string // Explicit list (SYMBOLS=BTCUSDT,ETHUSDT). Empty = rank by volume
+	UniverseSize int      // Top N USDT perpetuals by 24h volume
+	AdminToken   string   // Required for runtime admin endpoints (unset = disabled)
+
+	// Binance Stream Sharding
+	BinanceStreamsPerConn int // Streams per websocket (2 per symbol)
+
+	// Funding Gate
+	FundingGateRate    float64 // |rate| that blocks entries paying funding (0.0005 = 0.05%)
+	FundingGateWindow  int     // Minutes before the payment the gate applies
+	FundingExtremeRate float64 // |rate| that raises a FUNDING alert
+
+	// Open Interest
+	OIPollSec     int     // Poll interval (seconds)
+	OIWindowMin   int     // Delta lookback (minutes)
+	OISurgePct    float64 // OI +x over the window -> OI_SURGE (0.02 = 2%)
+	OIFlushPct    float64 // OI -x over the window -> OI_FLUSH
+	OICooldownMin int     // Per-symbol alert cooldown (minutes)
+
+	// Iceberg Accumulation
+	PriceBucketBPS float64 // Price bucket width in bps of price (0 = exact tick)
+
+	// Spoof Verification
+	SpoofVerifyDelayMs int     // Hold before re-reading the book
+	SpoofBandBPS       float64 // Level tolerance (bps)
+	SpoofMinRetain     float64 // Resting liquidity that must remain (0.5 = 50%)
+
+	// Wall Tracking (Order-Book Spoofs)
+	WallMinNotional float64 // Level size that counts as a wall (USD)
+	WallGraceMs     int     // Wait for late prints before judging a pulled wall
+
+	// Paper Trading
+	PaperTrading bool    // Route orders to the simulated exchange (PAPER_TRADING=true)
+	PaperBalance float64 // Starting USDT wallet for paper trading
+
+	// Account Risk (Shared by all Engines)
+	RiskMaxNotional  float64 // Global notional cap (USDT)
+	RiskMaxPositions int     // Concurrent symbols across engines
+	RiskMaxDailyLoss float64 // Combined daily loss limit (USDT)
+	RiskMaxLeverage  int
+
+	// Raw Feed Recording
+	RecordFeeds       bool   // Tee raw websocket frames to disk (RECORD_FEEDS=true)
+	RecordDir         string // Hourly gzip JSONL files
+	RecordMaxFileMB   int    // Part size before rolling over within the hour
+	RecordMaxTotalMB  int    // Oldest files deleted beyond this
+	RecordRetentionHr int    // Files older than this are deleted
+
+	// Durable State
+	StateDir          string // Journal + snapshot directory
+	StateResetHourUTC int    // Daily counters reset at this UTC hour (0-23)
 }
 
 // LoadConfig loads variables from .env and returns a Config struct
@@ -72,6 +132,163 @@ func LoadConfig() *Config {
 		}
 	}
 
+	// Parse Predator Daily Loss Budget
+	predatorLoss := 100.0 // Default
+	if val, err := strconv.ParseFloat(os.Getenv("PREDATOR_MAX_DAILY_LOSS"), 64); err == nil && val > 0 {
+		predatorLoss = val
+	}
+
+	// Parse Liquidation Cascade Thresholds
+	cascadeWindow := 30 // Default (seconds)
+	if val, err := strconv.Atoi(os.Getenv("LIQ_CASCADE_WINDOW_SEC")); err == nil && val > 0 {
+		cascadeWindow = val
+	}
+	cascadeNotional := 250000.0 // Default ($250k)
+	if val, err := strconv.ParseFloat(os.Getenv("LIQ_CASCADE_MIN_NOTIONAL"), 64); err == nil {
+		cascadeNotional = val
+	}
+	cascadeCount := 5 // Default
+	if val, err := strconv.Atoi(os.Getenv("LIQ_CASCADE_MIN_COUNT")); err == nil {
+		cascadeCount = val
+	}
+
+	// Parse Symbol Universe
+	var symbols []string
+	for _, sym := range strings.Split(os.Getenv("SYMBOLS"), ",") {
+		if sym = strings.ToUpper(strings.TrimSpace(sym)); sym != "" {
+			symbols = append(symbols, sym)
+		}
+	}
+	universeSize := 25 // Default
+	if val, err := strconv.Atoi(os.Getenv("UNIVERSE_SIZE")); err == nil && val > 0 {
+		universeSize = val
+	}
+
+	// Parse Binance Stream Sharding
+	streamsPerConn := 200 // Default (100 symbols per socket)
+	if val, err := strconv.Atoi(os.Getenv("BINANCE_STREAMS_PER_CONN")); err == nil && val > 0 {
+		streamsPerConn = val
+	}
+
+	// Parse Paper Trading
+	paperTrading := strings.EqualFold(os.Getenv("PAPER_TRADING"), "true")
+	paperBalance := 10000.0 // Default
+	if val, err := strconv.ParseFloat(os.Getenv("PAPER_BALANCE"), 64); err == nil && val > 0 {
+		paperBalance = val
+	}
+
+	// Parse Account Risk
+	riskNotional := 150000.0 // Default (3 x $50k ExecutionService cap)
+	if val, err := strconv.ParseFloat(os.Getenv("RISK_MAX_NOTIONAL"), 64); err == nil && val > 0 {
+		riskNotional = val
+	}
+	riskPositions := 5 // Default
+	if val, err := strconv.Atoi(os.Getenv("RISK_MAX_POSITIONS")); err == nil && val > 0 {
+		riskPositions = val
+	}
+	riskDailyLoss := 250.0 // Default ($150 Execution + $100 Predator)
+	if val, err := strconv.ParseFloat(os.Getenv("RISK_MAX_DAILY_LOSS"), 64); err == nil && val > 0 {
+		riskDailyLoss = val
+	}
+	riskLeverage := 25 // Default (20x + 5x Synergy Boost)
+	if val, err := strconv.Atoi(os.Getenv("RISK_MAX_LEVERAGE")); err == nil && val > 0 {
+		riskLeverage = val
+	}
+
+	// Parse Funding Gate
+	fundingGate := 0.0005 // Default (0.05%, 5x the base rate)
+	if val, err := strconv.ParseFloat(os.Getenv("FUNDING_GATE_RATE"), 64); err == nil && val >= 0 {
+		fundingGate = val
+	}
+	fundingWindow := 15 // Default (minutes)
+	if val, err := strconv.Atoi(os.Getenv("FUNDING_GATE_WINDOW_MIN")); err == nil && val > 0 {
+		fundingWindow = val
+	}
+	fundingExtreme := 0.001 // Default (0.10%)
+	if val, err := strconv.ParseFloat(os.Getenv("FUNDING_EXTREME_RATE"), 64); err == nil && val > 0 {
+		fundingExtreme = val
+	}
+
+	// Parse Open Interest
+	oiPoll := 60 // Default (seconds)
+	if val, err := strconv.Atoi(os.Getenv("OI_POLL_SEC")); err == nil && val >= 10 {
+		oiPoll = val
+	}
+	oiWindow := 5 // Default (minutes)
+	if val, err := strconv.Atoi(os.Getenv("OI_WINDOW_MIN")); err == nil && val > 0 {
+		oiWindow = val
+	}
+	oiSurge := 0.02 // Default (+2%)
+	if val, err := strconv.ParseFloat(os.Getenv("OI_SURGE_PCT"), 64); err == nil && val > 0 {
+		oiSurge = val
+	}
+	oiFlush := 0.02 // Default (-2%)
+	if val, err := strconv.ParseFloat(os.Getenv("OI_FLUSH_PCT"), 64); err == nil && val > 0 {
+		oiFlush = val
+	}
+	oiCooldown := 15 // Default (minutes)
+	if val, err := strconv.Atoi(os.Getenv("OI_COOLDOWN_MIN")); err == nil && val > 0 {
+		oiCooldown = val
+	}
+
+	// Parse Iceberg Accumulation
+	bucketBPS := 1.0 // Default (0.01% of price, snapped to ticks)
+	if val, err := strconv.ParseFloat(os.Getenv("PRICE_BUCKET_BPS"), 64); err == nil && val >= 0 {
+		bucketBPS = val
+	}
+
+	// Parse Spoof Verification
+	spoofDelay := 800 // Default (ms)
+	if val, err := strconv.Atoi(os.Getenv("SPOOF_VERIFY_DELAY_MS")); err == nil && val >= 0 {
+		spoofDelay = val
+	}
+	spoofBand := 5.0 // Default (0.05%)
+	if val, err := strconv.ParseFloat(os.Getenv("SPOOF_BAND_BPS"), 64); err == nil && val >= 0 {
+		spoofBand = val
+	}
+	spoofRetain := 0.5 // Default (50%)
+	if val, err := strconv.ParseFloat(os.Getenv("SPOOF_MIN_RETAIN"), 64); err == nil && val >= 0 && val <= 1 {
+		spoofRetain = val
+	}
+
+	// Parse Wall Tracking
+	wallNotional := 250000.0 // Default ($250k)
+	if val, err := strconv.ParseFloat(os.Getenv("WALL_MIN_NOTIONAL"), 64); err == nil && val > 0 {
+		wallNotional = val
+	}
+	wallGrace := 500 // Default (ms)
+	if val, err := strconv.Atoi(os.Getenv("WALL_GRACE_MS")); err == nil && val >= 0 {
+		wallGrace = val
+	}
+
+	// Parse Raw Feed Recording
+	recordDir := os.Getenv("RECORD_DIR")
+	if recordDir == "" {
+		recordDir = "recordings" // Default
+	}
+	recordFileMB := 256 // Default
+	if val, err := strconv.Atoi(os.Getenv("RECORD_MAX_FILE_MB")); err == nil && val > 0 {
+		recordFileMB = val
+	}
+	recordTotalMB := 10240 // Default (10 GB)
+	if val, err := strconv.Atoi(os.Getenv("RECORD_MAX_TOTAL_MB")); err == nil && val > 0 {
+		recordTotalMB = val
+	}
+	recordRetention := 72 // Default (3 days)
+	if val, err := strconv.Atoi(os.Getenv("RECORD_RETENTION_HOURS")); err == nil && val > 0 {
+		recordRetention = val
+	}
+
+	// Parse Durable State
+	stateDir := os.Getenv("STATE_DIR")
+	if stateDir == "" {
+		stateDir = "state" // Default
+	}
+	resetHour := 0 // Default (00:00 UTC)
+	if val, err := strconv.Atoi(os.Getenv("STATE_RESET_HOUR_UTC")); err == nil && val >= 0 && val < 24 {
+		resetHour = val
+	}
+
 	return &Config{
 		BinanceAPIKey:      apiKey,
 		BinanceAPISecret:   apiSecret,
@@ -80,5 +297,52 @@ func LoadConfig() *Config {
 		MaxConcurrent:      maxConc,
 		Leverage:           leverage,
 		TotalNotionalLimit: totalLimit,
+		PredatorDailyLoss:  predatorLoss,
+
+		LiqCascadeWindowSec:   cascadeWindow,
+		LiqCascadeMinNotional: cascadeNotional,
+		LiqCascadeMinCount:    cascadeCount,
+
+		Symbols:      symbols,
+		UniverseSize: universeSize,
+		AdminToken:   os.Getenv("ADMIN_TOKEN"),
+
+		BinanceStreamsPerConn: streamsPerConn,
+
+		FundingGateRate:    fundingGate,
+		FundingGateWindow:  fundingWindow,
+		FundingExtremeRate: fundingExtreme,
+
+		OIPollSec:     oiPoll,
+		OIWindowMin:   oiWindow,
+		OISurgePct:    oiSurge,
+		OIFlushPct:    oiFlush,
+		OICooldownMin: oiCooldown,
+
+		PriceBucketBPS: bucketBPS,
+
+		SpoofVerifyDelayMs: spoofDelay,
+		SpoofBandBPS:       spoofBand,
+		SpoofMinRetain:     spoofRetain,
+
+		WallMinNotional: wallNotional,
+		WallGraceMs:     wallGrace,
+
+		PaperTrading: paperTrading,
+		PaperBalance: paperBalance,
+
+		RiskMaxNotional:  riskNotional,
+		RiskMaxPositions: riskPositions,
+		RiskMaxDailyLoss: riskDailyLoss,
+		RiskMaxLeverage:  riskLeverage,
+
+		RecordFeeds:       strings.EqualFold(os.Getenv("RECORD_FEEDS"), "true"),
+		RecordDir:         recordDir,
+		RecordMaxFileMB:   recordFileMB,
+		RecordMaxTotalMB:  recordTotalMB,
+		RecordRetentionHr: recordRetention,
+
+		StateDir:          stateDir,
+		StateResetHourUTC: resetHour,
 	}
 }
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// CVD ENGINE (Per-Symbol Cumulative Volume Delta)
+// ============================================================================
+// Every trade lands in its symbol's 1-second bucket (buy / sell notional).
+// Each window (5s, 1m, 5m, 1h) keeps running sums and evicts buckets as they
+// age out, so reads are O(1) no matter how busy the symbol is.
+//
+// Symbols are keyed by instrument ("BTC" == "BTCUSDT"). Time comes from the
+// Clock, so replay / backtest windows follow event time.
+
+// cvdWindows are the tracked lookbacks (label -> length)
+var cvdWindows = []struct {
+	Label  string
+	Window time.Duration
+}{
+	{"5s", 5 * time.Second},
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+}
+
+const cvdBuckets = 3600 // 1s buckets: covers the longest window
+
+// CVDFlow is buy / sell notional over one window
+type CVDFlow struct {
+	Buy   float64 `json:"buy"`
+	Sell  float64 `json:"sell"`
+	Delta float64 `json:"delta"` // Buy - Sell
+}
+
+// Ratio is the dominance of side ("buy" / "sell") over the other (999 if unopposed)
+func (f CVDFlow) Ratio(side string) float64 {
+	with, against := f.Buy, f.Sell
+	if side != "buy" {
+		with, against = f.Sell, f.Buy
+	}
+	if against <= 0 {
+		return 999.0 // Infinite
+	}
+	return with / against
+}
+
+// CVDSnapshot is one symbol's flow across every window (SENTIMENT payload)
+type CVDSnapshot struct {
+	Windows    map[string]CVDFlow `json:"windows"`    // "5s", "1m", "5m", "1h"
+	Cumulative float64            `json:"cumulative"` // Delta since start
+}
+
+type cvdBucket struct {
+	sec       int64 // Unix second this slot holds
+	buy, sell float64
+}
+
+type cvdSeries struct {
+	buckets    [cvdBuckets]cvdBucket
+	last       int64     // Newest second seen
+	tails      []int64   // Per window: oldest second still in the sums
+	buy, sell  []float64 // Per window running sums
+	cumulative float64
+}
+
+// CVDEngine tracks rolling CVD per symbol
+type CVDEngine struct {
+	mu     sync.Mutex
+	series map[string]*cvdSeries
+	clock  Clock
+}
+
+// NewCVDEngine creates an empty engine
+func NewCVDEngine() *CVDEngine {
+	return &CVDEngine{
+		series: make(map[string]*cvdSeries),
+		clock:  RealClock,
+	}
+}
+
+// SetClock swaps the time source (event time in replay)
+func (e *CVDEngine) SetClock(c Clock) {
+	e.mu.Lock()
+	e.clock = clockOrReal(c)
+	e.mu.Unlock()
+}
+
+// Add books a trade's notional on its side
+func (e *CVDEngine) Add(trade Trade) {
+	key := busSymbol(trade.Symbol)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.series[key]
+	if !ok {
+		s = newCVDSeries()
+		e.series[key] = s
+	}
+	now := e.clock.Now().Unix()
+	s.advance(now)
+	if now < s.last {
+		now = s.last // Out-of-order feed: book it in the newest bucket
+	}
+
+	b := &s.buckets[now%cvdBuckets]
+	if b.sec != now {
+		*b = cvdBucket{sec: now}
+	}
+	if trade.Side == "buy" {
+		b.buy += trade.Notional
+		s.cumulative += trade.Notional
+		for i := range s.buy {
+			s.buy[i] += trade.Notional
+		}
+	} else {
+		b.sell += trade.Notional
+		s.cumulative -= trade.Notional
+		for i := range s.sell {
+			s.sell[i] += trade.Notional
+		}
+	}
+}
+
+// Flow returns a symbol's flow over window (must be one of cvdWindows)
+func (e *CVDEngine) Flow(symbol string, window time.Duration) CVDFlow {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.series[busSymbol(symbol)]
+	if !ok {
+		return CVDFlow{}
+	}
+	s.advance(e.clock.Now().Unix())
+	for i, w := range cvdWindows {
+		if w.Window == window {
+			return CVDFlow{Buy: s.buy[i], Sell: s.sell[i], Delta: s.buy[i] - s.sell[i]}
+		}
+	}
+	return CVDFlow{}
+}
+
+// Snapshot returns every symbol's flow across all windows
+func (e *CVDEngine) Snapshot() map[string]CVDSnapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now().Unix()
+	out := make(map[string]CVDSnapshot, len(e.series))
+	for key, s := range e.series {
+		s.advance(now)
+		snap := CVDSnapshot{Windows: make(map[string]CVDFlow, len(cvdWindows)), Cumulative: s.cumulative}
+		for i, w := range cvdWindows {
+			snap.Windows[w.Label] = CVDFlow{Buy: s.buy[i], Sell: s.sell[i], Delta: s.buy[i] - s.sell[i]}
+		}
+		out[key] = snap
+	}
+	return out
+}
+
+func newCVDSeries() *cvdSeries {
+	n := len(cvdWindows)
+	return &cvdSeries{
+		tails: make([]int64, n),
+		buy:   make([]float64, n),
+		sell:  make([]float64, n),
+	}
+}
+
+// advance moves the series to second now, evicting buckets that left each window
+func (s *cvdSeries) advance(now int64) {
+	if now <= s.last {
+		return
+	}
+	s.last = now
+
+	for i, w := range cvdWindows {
+		oldest := now - int64(w.Window/time.Second) + 1
+		if oldest-s.tails[i] >= cvdBuckets {
+			// Idle longer than the ring: nothing left in the window
+			s.buy[i], s.sell[i] = 0, 0
+			s.tails[i] = oldest
+			continue
+		}
+		for ; s.tails[i] < oldest; s.tails[i]++ {
+			b := &s.buckets[s.tails[i]%cvdBuckets]
+			if b.sec == s.tails[i] {
+				s.buy[i] -= b.buy
+				s.sell[i] -= b.sell
+			}
+		}
+		if s.buy[i] < 0 {
+			s.buy[i] = 0 // Float drift
+		}
+		if s.sell[i] < 0 {
+			s.sell[i] = 0
+		}
+	}
+}
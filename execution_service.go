@@ -10,7 +10,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
@@ -19,9 +18,10 @@ import (
 // ============================================================================
 
 type SafetyConfig struct {
-	Enabled    bool // Master switch
-	DryRun     bool // If true, log only (DO NOT execute)
-	UseTestnet bool // ⚠️ TESTNET MODE
+	Enabled      bool // Master switch
+	DryRun       bool // If true, log only (DO NOT execute)
+	PaperTrading bool // 📝 Fill orders on the simulated PaperExchange (overrides DryRun)
+	UseTestnet   bool // ⚠️ TESTNET MODE
 	// TargetSymbols    []string      // e.g. ["BTCUSDT", "ETHUSDT"] -- REPLACED BY PROFILES
 	Profiles map[string]CoinProfile // Configuration per coin
 	// Example 1k Runner Strategy:
@@ -68,6 +68,9 @@ type Signal struct {
 	RSI       float64
 	IsCounter bool
 	Label     string
+
+	Funding *FundingInfo `json:"funding,omitempty"` // Rate / next payment at signal time
+	OI      *OIDelta     `json:"oi,omitempty"`      // Open interest change (new money vs closes)
 }
 
 // ============================================================================
@@ -80,16 +83,16 @@ type SymbolProfile struct {
 }
 
 type ExecutionService struct {
-	client *futures.Client
-	config SafetyConfig
-	mu     sync.Mutex
+	gateway Gateway
+	config  SafetyConfig
+	mu      sync.Mutex
 
 	// State Tracking
 	dailyLoss     float64
 	openPositions map[string]bool // Symbol -> IsOpen
 	lastTradeTime map[string]time.Time
-	lastTradeSide map[string]string // Hysteresis: prevent Flip-Flop
-	processedSigs map[string]bool   // Duplicate Guard
+	lastTradeSide map[string]string    // Hysteresis: prevent Flip-Flop
+	processedSigs map[string]time.Time // Duplicate Guard (ID -> Seen)
 
 	// Chaos / Kill Switch
 	consecutiveLosses int       // Global counter for Kill Switch
@@ -106,7 +109,7 @@ type ExecutionService struct {
 
 	// Stats
 	// Stats
-	TotalFees float64 // Estimated Fees Paid
+	TotalFees float64 // Fees Paid (fill commissions)
 	DailyLoss float64 // Track Loss for Kill Switch
 
 	// Performance Tracking
@@ -115,28 +118,48 @@ type ExecutionService struct {
 	BestTrade  float64
 
 	activeSessions map[string]*GhostSession // Tracking for /status Live PnL
+
+	// User Data Stream (Push Fills)
+	userStream    *UserStreamManager
+	orderWatchers map[int64][]chan futures.WsOrderTradeUpdate // OrderID -> Waiting Monitors
+
+	// Account Risk (Shared with PredatorEngine)
+	risk           *RiskManager
+	pendingEntries map[string]int // Symbol -> Entry attempts in flight (orders / walk)
+
+	// Durable State (Survives Redeploys)
+	store            *StateStore
+	saveMu           sync.Mutex                   // Serializes snapshot + Save
+	restoredSessions map[string]ghostSessionState // Symbol -> Last journaled session (for reconcile)
 }
 
 // NewExecutionService creates a new execution service instance
-func NewExecutionService(apiKey, secretKey string, config SafetyConfig, notifier *NotificationService) *ExecutionService {
-	// SWITCH TO TESTNET IF ENABLED (Global Setting for the package)
-	if config.UseTestnet {
-		futures.UseTestnet = true
-		log.Println("⚠️ USING BINANCE FUTURES TESTNET URL")
+func NewExecutionService(gateway Gateway, config SafetyConfig, notifier *NotificationService, risk *RiskManager) *ExecutionService {
+	// PAPER MODE: Orders must reach the simulator (attached in main)
+	if config.PaperTrading {
+		config.DryRun = false
+		log.Println("📝 PAPER TRADING: Orders will be filled by the simulated exchange")
 	}
 
-	client := binance.NewFuturesClient(apiKey, secretKey)
+	// Our share of the account (global caps live in the RiskManager)
+	risk.SetEngineBudget(RiskEngineExec, EngineBudget{
+		MaxPositions: config.MaxOpenPositions,
+		MaxDailyLoss: config.MaxDailyLoss,
+	})
 
 	return &ExecutionService{
-		client:         client,
+		gateway:        NewRiskGateway(gateway, risk, RiskEngineExec), // Every order passes the RiskManager
+		risk:           risk,
 		config:         config,
 		openPositions:  make(map[string]bool),
 		lastTradeTime:  make(map[string]time.Time),
 		lastTradeSide:  make(map[string]string),
-		processedSigs:  make(map[string]bool),
+		processedSigs:  make(map[string]time.Time),
 		notifier:       notifier,
 		symbolInfo:     make(map[string]SymbolProfile),
 		activeSessions: make(map[string]*GhostSession),
+		orderWatchers:  make(map[int64][]chan futures.WsOrderTradeUpdate),
+		pendingEntries: make(map[string]int),
 	}
 }
 
@@ -161,7 +184,7 @@ func (es *ExecutionService) CheckBalance(symbol string) bool {
 	}
 
 	// Fetch Account Info
-	res, err := es.client.NewGetAccountService().Do(context.Background())
+	res, err := es.gateway.Account(context.Background())
 	if err != nil {
 		log.Printf("⚠️ Failed to check balance: %v", err)
 		return false
@@ -219,8 +242,9 @@ func (es *ExecutionService) GetStatusReport() string {
 	sb.WriteString("📊 *SYSTEM STATUS REPORT* 📊\n\n")
 
 	// 1. Fee Stats
-	sb.WriteString(fmt.Sprintf("💸 *Total Estimates Fees*: $%.2f\n", es.TotalFees))
-	sb.WriteString(fmt.Sprintf("🛡️ *Active Ghost Sessions*: %d\n\n", len(es.activeSessions)))
+	sb.WriteString(fmt.Sprintf("💸 *Total Fees*: $%.2f\n", es.TotalFees))
+	sb.WriteString(fmt.Sprintf("🛡️ *Active Ghost Sessions*: %d\n", len(es.activeSessions)))
+	sb.WriteString(es.risk.Status() + "\n")
 
 	sb.WriteString("*Active Sessions & Live PnL:*\n")
 
@@ -236,7 +260,7 @@ func (es *ExecutionService) GetStatusReport() string {
 			// Or we just do a quick fetch now? Fetching 1-2 coins is fast.
 
 			// Quick Fetch Ticker
-			tickerInfo, err := es.client.NewListPricesService().Symbol(sym).Do(context.Background())
+			tickerInfo, err := es.gateway.Prices(context.Background(), sym)
 			currentPrice := 0.0
 			if err == nil && len(tickerInfo) > 0 {
 				currentPrice, _ = strconv.ParseFloat(tickerInfo[0].Price, 64)
@@ -282,14 +306,12 @@ func (es *ExecutionService) Start() {
 		log.Println("⚠️ ExecutionService: LIVE TRADING ENABLED. BE CAREFUL.")
 	}
 
-	log.Println("⚠️ NOTE: Monitor only tracks new signals. Existing Binance positions are NOT monitored!")
-
 	// 0. FETCH EXCHANGE INFO (Precision Data)
 	es.FetchExchangeInfo()
 
 	// Force One-Way Mode (Hedge Mode OFF)
 	// This is global, not per symbol.
-	err := es.client.NewChangePositionModeService().DualSide(false).Do(context.Background())
+	err := es.gateway.ChangePositionMode(context.Background(), false)
 	if err != nil {
 		// Log but don't panic, might already be set
 		log.Printf("ℹ️ Position Mode: %v", err)
@@ -305,6 +327,9 @@ func (es *ExecutionService) Start() {
 			log.Printf("⚠️ %s Margin Error: %v", symbol, err)
 		}
 	}
+
+	// ADOPT EXISTING POSITIONS (Restart Recovery)
+	es.reconcile()
 }
 
 // ExecuteTrade attempts to execute a signal with paranoid safety checks
@@ -317,12 +342,12 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 
 	es.mu.Lock()
 	// 1. DUPLICATE CHECK
-	if es.processedSigs[signal.ID] {
+	if _, seen := es.processedSigs[signal.ID]; seen {
 		es.mu.Unlock()
 		log.Printf("🛡️ IGNORING DUPLICATE SIGNAL: %s", signal.ID)
 		return nil
 	}
-	es.processedSigs[signal.ID] = true
+	es.processedSigs[signal.ID] = time.Now()
 
 	// 3. KILL SWITCH: Daily Loss Limit
 	if es.dailyLoss >= es.config.MaxDailyLoss {
@@ -331,10 +356,10 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 		return nil
 	}
 
-	// 4. CHECK OPEN POSITIONS (Concurrency Limit)
-	if len(es.openPositions) >= es.config.MaxOpenPositions {
+	// 4. ACCOUNT RISK (Positions / Symbol Lock / Daily Loss) - Orders are re-checked by the RiskGateway
+	if err := es.risk.Check(RiskEngineExec, signal.Symbol, 0); err != nil {
 		es.mu.Unlock()
-		log.Printf(" MAX POSITIONS REACHED (%d). IGNORING SIGNAL.", es.config.MaxOpenPositions)
+		log.Printf("🛑 %v. IGNORING SIGNAL.", err)
 		return nil
 	}
 
@@ -346,6 +371,7 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 		return nil
 	}
 	es.mu.Unlock()
+	es.saveState() // Duplicate guard must survive a restart
 
 	// 5B. GLOBAL KILL SWITCH (Entropy)
 	if !es.config.DryRun && time.Now().Before(es.chaosModeUntil) {
@@ -355,7 +381,7 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 
 	// 6. SLIPPAGE CHECK (Liquidity Guard)
 	// Fetch Book Ticker to check Spread
-	ticker, err := es.client.NewListBookTickersService().Symbol(signal.Symbol).Do(context.Background())
+	ticker, err := es.gateway.BookTickers(context.Background(), signal.Symbol)
 	if err == nil && len(ticker) > 0 {
 		bestBid, _ := strconv.ParseFloat(ticker[0].BidPrice, 64)
 		bestAsk, _ := strconv.ParseFloat(ticker[0].AskPrice, 64)
@@ -401,7 +427,7 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 		log.Printf("🚀 SYNERGY BOOST: Leverage Increased to %dx", targetLeverage)
 	}
 
-	if _, err := es.client.NewChangeLeverageService().Symbol(signal.Symbol).Leverage(targetLeverage).Do(context.Background()); err != nil {
+	if err := es.gateway.ChangeLeverage(context.Background(), signal.Symbol, targetLeverage); err != nil {
 		log.Printf("⚠️ Failed to set Leverage to %dx: %v. ABORTING.", targetLeverage, err)
 		return err
 	}
@@ -473,7 +499,7 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 	// RETRY LOOP for GTX (-5022)
 	for i := 0; i < 2; i++ { // Try twice
 		// Get Order Book for Smart Offset
-		tickerInfo, tickErr := es.client.NewListBookTickersService().Symbol(signal.Symbol).Do(context.Background())
+		tickerInfo, tickErr := es.gateway.BookTickers(context.Background(), signal.Symbol)
 		finalPrice := signal.Entry
 
 		if tickErr == nil && len(tickerInfo) > 0 {
@@ -537,22 +563,17 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 		log.Printf("formatted Order: %s @ Qty %s", priceStr, finalQtyStr)
 		log.Printf("🏗️ PLACING MAKER ORDER (Attempt %d): %s %s @ %s (Qty: %s)", i+1, signal.Side, signal.Symbol, priceStr, finalQtyStr)
 
-		orderRes, err = es.client.NewCreateOrderService().
-			Symbol(signal.Symbol).
-			Side(entrySide).
-			Type(futures.OrderTypeLimit).
-			TimeInForce(futures.TimeInForceTypeGTX). // Post-Only
-			Price(priceStr).
-			Quantity(finalQtyStr).
-			Do(context.Background())
+		orderRes, err = es.gateway.PlaceOrder(context.Background(), OrderRequest{
+			Symbol:      signal.Symbol,
+			Side:        entrySide,
+			Type:        futures.OrderTypeLimit,
+			TimeInForce: futures.TimeInForceTypeGTX, // Post-Only
+			Price:       priceStr,
+			Quantity:    finalQtyStr,
+		})
 
 		if err == nil {
-			// FEE TRACKING (Maker)
-			// Est 0.02%
-			es.mu.Lock()
-			es.TotalFees += (safePrice * safeQty * 0.0002)
-			es.mu.Unlock()
-			break
+			break // Fees are booked from the fill's real commission (onOrderUpdate)
 		}
 
 		// If error is -5022 (GTX Reject) or -1013 (Filter failure), retry
@@ -576,12 +597,12 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 			marketSide = futures.SideTypeSell
 		}
 
-		orderRes, err = es.client.NewCreateOrderService().
-			Symbol(signal.Symbol).
-			Side(marketSide).
-			Type(futures.OrderTypeMarket).
-			Quantity(qtyStr).
-			Do(context.Background())
+		orderRes, err = es.gateway.PlaceOrder(context.Background(), OrderRequest{
+			Symbol:   signal.Symbol,
+			Side:     marketSide,
+			Type:     futures.OrderTypeMarket,
+			Quantity: qtyStr,
+		})
 
 		if err != nil {
 			log.Printf("❌ FLASH-RETRY FAILED: %v", err)
@@ -590,7 +611,7 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 		}
 		log.Printf("✅ FLASH-RETRY SUCCESS (ID: %d).", orderRes.OrderID)
 		// Launch Monitor
-		go es.monitorLimitOrder(signal.Symbol, orderRes.OrderID, signal.Entry, signal.StopLoss, takeProfit, targetQty, signal.Side)
+		es.launchEntryMonitor(signal.Symbol, orderRes.OrderID, signal.Entry, signal.StopLoss, takeProfit, targetQty, signal.Side)
 		return nil
 	}
 
@@ -606,20 +627,42 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 
 	// STEALTH WALKING (Bridge V2)
 	// 5s Wait -> Walk -> 2s Wait -> Market
+	es.beginEntry(signal.Symbol)
 	go func(symbol string, orderID int64, side string) {
-		time.Sleep(5 * time.Second) // Phase 1: Give Maker a chance
+		defer es.endEntry(symbol)
+		fills, unwatch := es.watchOrder(orderID)
+		defer unwatch()
+
+		// Phase 1: Give Maker a chance (exit early if the stream reports a terminal state)
+		var status futures.OrderStatusType
+		phase1 := time.After(5 * time.Second)
+	wait:
+		for {
+			select {
+			case update := <-fills:
+				status = update.Status
+				if !isOpenStatus(status) {
+					return
+				}
+			case <-phase1:
+				break wait
+			}
+		}
 
-		// Check Status
-		o, err := es.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
-		if err != nil {
-			return
+		// No push received: Check Status once
+		if status == "" {
+			o, err := es.gateway.GetOrder(context.Background(), symbol, orderID)
+			if err != nil {
+				return
+			}
+			status = o.Status
 		}
 
-		if o.Status == futures.OrderStatusTypeNew || o.Status == futures.OrderStatusTypePartiallyFilled {
+		if isOpenStatus(status) {
 			log.Printf("🥷 UNFILLED after 5s. Initiating STEALTH WALK...")
 
 			// Cancel Original
-			es.client.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+			es.gateway.CancelOrder(context.Background(), symbol, orderID)
 
 			// WALKING: 1 Tick Closer to Market
 			// We need current book to know where to go
@@ -646,15 +689,15 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 					marketSide = futures.SideTypeSell
 				}
 
-				marketRes, err := es.client.NewCreateOrderService().
-					Symbol(symbol).
-					Side(marketSide).
-					Type(futures.OrderTypeMarket).
-					Quantity(qtyStr).
-					Do(context.Background())
+				marketRes, err := es.gateway.PlaceOrder(context.Background(), OrderRequest{
+					Symbol:   symbol,
+					Side:     marketSide,
+					Type:     futures.OrderTypeMarket,
+					Quantity: qtyStr,
+				})
 
 				if err == nil {
-					go es.monitorLimitOrder(symbol, marketRes.OrderID, signal.Entry, signal.StopLoss, takeProfit, targetQty, side)
+					es.launchEntryMonitor(symbol, marketRes.OrderID, signal.Entry, signal.StopLoss, takeProfit, targetQty, side)
 				}
 			}
 		}
@@ -662,7 +705,7 @@ func (es *ExecutionService) ExecuteTrade(signal Signal) error {
 
 	// D. LAUNCH ASYNC MONITOR (Standard Monitor for the Limit Order)
 	// We pass the RAW Qty Float to monitorLimitOrder for precision
-	go es.monitorLimitOrder(signal.Symbol, orderRes.OrderID, signal.Entry, signal.StopLoss, takeProfit, targetQty, signal.Side)
+	es.launchEntryMonitor(signal.Symbol, orderRes.OrderID, signal.Entry, signal.StopLoss, takeProfit, targetQty, signal.Side)
 
 	return nil
 }
@@ -678,9 +721,12 @@ type GhostSession struct {
 	TakeProfit float64
 	Side       string
 
-	mu         sync.Mutex
-	CurrentQty float64 // Updates dynamically on partial fills
-	IsActive   bool
+	mu          sync.Mutex
+	CurrentQty  float64 // Updates dynamically on partial fills
+	IsActive    bool
+	RealizedPnL float64       // Summed from user stream fills ("rp")
+	closed      chan struct{} // Closed when the exchange reports the position flat
+	closeOnce   sync.Once
 }
 
 func NewGhostSession(symbol string, entry, sl, tp, qty float64, side string) *GhostSession {
@@ -692,6 +738,7 @@ func NewGhostSession(symbol string, entry, sl, tp, qty float64, side string) *Gh
 		Side:       side,
 		CurrentQty: qty,
 		IsActive:   true,
+		closed:     make(chan struct{}),
 	}
 }
 
@@ -703,9 +750,21 @@ func (gs *GhostSession) UpdateQty(newQty float64) {
 	log.Printf("🧩 GHOST SESSION: Updated Quantity to %.4f for %s", newQty, gs.Symbol)
 }
 
+// SetStopLoss moves the session stop (read by saveState under mu)
+func (gs *GhostSession) SetStopLoss(price float64) {
+	gs.mu.Lock()
+	gs.StopLoss = price
+	gs.mu.Unlock()
+}
+
+// MarkClosed signals the monitor that the exchange flattened the position (TP/SL/Liquidation)
+func (gs *GhostSession) MarkClosed() {
+	gs.closeOnce.Do(func() { close(gs.closed) })
+}
+
 // setMarginType forces Isolated Margin. Returns error if it fails (unless already set).
 func (es *ExecutionService) setMarginType(symbol string) error {
-	err := es.client.NewChangeMarginTypeService().Symbol(symbol).MarginType(futures.MarginTypeIsolated).Do(context.Background())
+	err := es.gateway.ChangeMarginType(context.Background(), symbol, futures.MarginTypeIsolated)
 	if err != nil {
 		// API returns error if already set, which is fine. Check error msg.
 		if strings.Contains(err.Error(), "No need to change margin type") {
@@ -734,12 +793,12 @@ func (es *ExecutionService) SetSymbolExitTarget(symbol string, targetPrice float
 
 	// 1. Cancel Existing "Web Target" Orders
 	// We use ClientOrderID prefix "web-target-" to avoid nuking manual orders
-	openOrders, err := es.client.NewListOpenOrdersService().Symbol(symbol).Do(context.Background())
+	openOrders, err := es.gateway.OpenOrders(context.Background(), symbol)
 	if err == nil {
 		for _, o := range openOrders {
 			if strings.HasPrefix(o.ClientOrderID, "web-target-") {
 				log.Printf("🗑️ Cancelling Old Web Target Order %d for %s", o.OrderID, symbol)
-				es.client.NewCancelOrderService().Symbol(symbol).OrderID(o.OrderID).Do(context.Background())
+				es.gateway.CancelOrder(context.Background(), symbol, o.OrderID)
 			}
 		}
 	}
@@ -758,7 +817,7 @@ func (es *ExecutionService) SetSymbolExitTarget(symbol string, targetPrice float
 	priceStr := fmt.Sprintf("%.*f", es.getPrecision(tickSize), safePrice)
 
 	// 3. FETCH POSITION (Required for Quantity & Side)
-	posRisk, err := es.client.NewGetPositionRiskService().Symbol(symbol).Do(context.Background())
+	posRisk, err := es.gateway.Positions(context.Background(), symbol)
 	if err != nil {
 		return fmt.Errorf("failed to fetch position for %s: %v", symbol, err)
 	}
@@ -794,16 +853,16 @@ func (es *ExecutionService) SetSymbolExitTarget(symbol string, targetPrice float
 
 	// 4. Place LIMIT Order (ReduceOnly + TimeInForce: GTC)
 	// FIXING -4120: Using standard LIMIT order. This is universally supported.
-	_, err = es.client.NewCreateOrderService().
-		Symbol(symbol).
-		Side(closeSide).
-		Type(futures.OrderTypeLimit).
-		Price(priceStr).
-		Quantity(qtyStr).
-		ReduceOnly(true).
-		TimeInForce(futures.TimeInForceTypeGTC).
-		NewClientOrderID(clientID).
-		Do(context.Background())
+	_, err = es.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:        symbol,
+		Side:          closeSide,
+		Type:          futures.OrderTypeLimit,
+		Price:         priceStr,
+		Quantity:      qtyStr,
+		ReduceOnly:    true,
+		TimeInForce:   futures.TimeInForceTypeGTC,
+		ClientOrderID: clientID,
+	})
 
 	if err != nil {
 		log.Printf("❌ TP Order Failed: %v", err)
@@ -824,7 +883,15 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 		timeoutDuration = 5 * time.Minute // Default
 	}
 	timeout := time.After(timeoutDuration)
-	ticker := time.NewTicker(2 * time.Second) // Aggressive Polling
+
+	// Fills arrive via the user data stream; polling is only a safety net
+	fills, unwatch := es.watchOrder(orderID)
+	defer unwatch()
+	pollInterval := 2 * time.Second // Aggressive Polling (No User Stream)
+	if es.userStream != nil && es.userStream.IsConnected() {
+		pollInterval = 15 * time.Second
+	}
+	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
 	log.Printf("🕵️ WATCHING ORDER %d for %s (Timeout: %v | Mode: %s)...", orderID, symbol, timeoutDuration, es.config.FailsafeMode)
@@ -835,6 +902,59 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 
 	lastFilledQty := 0.0
 
+	// applyUpdate handles a fill/status snapshot (push or poll). Returns true when the order is done.
+	applyUpdate := func(filledQty float64, status futures.OrderStatusType) bool {
+		// PARTIAL FILL LOGIC
+		if filledQty > lastFilledQty {
+			delta := filledQty - lastFilledQty
+
+			// DUST FILTER (> 10 USDT Notional)
+			notional := delta * entry
+			if notional < 10.0 && filledQty < plannedQty*0.99 { // Ignore small dust unless it's the final scrape
+				// log.Printf("🧹 Dust Fill Ignored: $%.2f", notional)
+				// actually we should probably count it, but maybe not log loudly?
+				// For risk accuracy, we MUST count it.
+			}
+
+			lastFilledQty = filledQty
+			log.Printf("🧩 PARTIAL FILL: +%.4f (Total: %.4f / %.4f)", delta, filledQty, plannedQty)
+			es.notifier.Notify(fmt.Sprintf("🧩 *PARTIAL FILL* (%.2f%%)\nFilled: %.4f / %.4f", (filledQty/plannedQty)*100, filledQty, plannedQty))
+
+			// UPDATE GHOST SESSION
+			ghost.UpdateQty(filledQty)
+
+			// START MONITOR IF NOT STARTED
+			if !monitorStarted {
+				monitorStarted = true
+
+				// Register Position Locally
+				es.mu.Lock()
+				es.openPositions[symbol] = true
+				es.lastTradeTime[symbol] = time.Now()
+				es.lastTradeSide[symbol] = side
+				es.mu.Unlock()
+
+				// Launch Ghost Monitor with POINTER to Session
+				go es.MonitorPosition(ghost)
+			}
+		}
+
+		// TERMINAL STATES
+		if status == futures.OrderStatusTypeFilled {
+			log.Printf("✅ MAKER EXECUTION COMPLETE! Order %d FILLED (%.4f).", orderID, filledQty)
+			es.notifier.Notify("✅ *MAKER ORDER FILLED COMPLETELY*")
+			return true // Monitor exits, Ghost keeps running
+		} else if status == futures.OrderStatusTypeCanceled || status == futures.OrderStatusTypeRejected || status == futures.OrderStatusTypeExpired {
+			if lastFilledQty > 0 {
+				log.Printf("❌ ORDER %d DEAD (%s) with Partial: %.4f. Ghost continues.", orderID, status, lastFilledQty)
+			} else {
+				log.Printf("❌ ORDER %d DEAD (%s). Stopped.", orderID, status)
+			}
+			return true
+		}
+		return false
+	}
+
 	for {
 		select {
 		case <-timeout:
@@ -843,7 +963,7 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 			es.notifier.Notify(fmt.Sprintf("⏳ *TIMEOUT* (Order %d)\nExceeded %v. Checking details...", orderID, timeoutDuration))
 
 			// 1. Check Distance (For Logging)
-			tickerInfo, err := es.client.NewListBookTickersService().Symbol(symbol).Do(context.Background())
+			tickerInfo, err := es.gateway.BookTickers(context.Background(), symbol)
 			if err == nil && len(tickerInfo) > 0 {
 				bestPrice, _ := strconv.ParseFloat(tickerInfo[0].AskPrice, 64)
 				if side == "SHORT" {
@@ -854,14 +974,14 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 			}
 
 			// 2. Fetch Latest Status/Qty before deciding
-			order, err := es.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+			order, err := es.gateway.GetOrder(context.Background(), symbol, orderID)
 			currentFilled := lastFilledQty
 			if err == nil {
 				currentFilled, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
 			}
 
 			// 3. CANCEL THE LIMIT ORDER
-			cancelRes, err := es.client.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+			cancelRes, err := es.gateway.CancelOrder(context.Background(), symbol, orderID)
 			if err != nil && !strings.Contains(err.Error(), "Unknown order") {
 				log.Printf("⚠️ Failed to Cancel Order %d: %v", orderID, err)
 			} else if cancelRes != nil {
@@ -892,12 +1012,12 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 					// Since we don't have profile here easily, we rely on broad formatting or pass it.
 					// Improvement: Pass precision to this func. For now: %.3f
 
-					_, err := es.client.NewCreateOrderService().
-						Symbol(symbol).
-						Side(marketSide).
-						Type(futures.OrderTypeMarket).
-						Quantity(fmt.Sprintf("%.3f", remainingQty)).
-						Do(context.Background())
+					_, err := es.gateway.PlaceOrder(context.Background(), OrderRequest{
+						Symbol:   symbol,
+						Side:     marketSide,
+						Type:     futures.OrderTypeMarket,
+						Quantity: fmt.Sprintf("%.3f", remainingQty),
+					})
 
 					if err != nil {
 						log.Printf("❌ FAILSAFE MARKET FAILED: %v", err)
@@ -950,9 +1070,16 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 			es.notifier.Notify("👋 *TIMEOUT CLEAN EXIT*\nNo position taken.")
 			return
 
+		case update := <-fills:
+			// Pushed Fill (User Data Stream)
+			filledQty, _ := strconv.ParseFloat(update.AccumulatedFilledQty, 64)
+			if applyUpdate(filledQty, update.Status) {
+				return
+			}
+
 		case <-ticker.C:
-			// Check Order Status
-			order, err := es.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(context.Background())
+			// Safety Poll: Check Order Status
+			order, err := es.gateway.GetOrder(context.Background(), symbol, orderID)
 			if err != nil {
 				log.Printf("⚠️ Monitor Check Error: %v", err)
 				continue
@@ -960,53 +1087,7 @@ func (es *ExecutionService) monitorLimitOrder(symbol string, orderID int64, entr
 
 			// Parse Executed Qty
 			filledQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
-
-			// PARTIAL FILL LOGIC
-			if filledQty > lastFilledQty {
-				delta := filledQty - lastFilledQty
-
-				// DUST FILTER (> 10 USDT Notional)
-				notional := delta * entry
-				if notional < 10.0 && filledQty < plannedQty*0.99 { // Ignore small dust unless it's the final scrape
-					// log.Printf("🧹 Dust Fill Ignored: $%.2f", notional)
-					// actually we should probably count it, but maybe not log loudly?
-					// For risk accuracy, we MUST count it.
-				}
-
-				lastFilledQty = filledQty
-				log.Printf("🧩 PARTIAL FILL: +%.4f (Total: %.4f / %.4f)", delta, filledQty, plannedQty)
-				es.notifier.Notify(fmt.Sprintf("🧩 *PARTIAL FILL* (%.2f%%)\nFilled: %.4f / %.4f", (filledQty/plannedQty)*100, filledQty, plannedQty))
-
-				// UPDATE GHOST SESSION
-				ghost.UpdateQty(filledQty)
-
-				// START MONITOR IF NOT STARTED
-				if !monitorStarted {
-					monitorStarted = true
-
-					// Register Position Locally
-					es.mu.Lock()
-					es.openPositions[symbol] = true
-					es.lastTradeTime[symbol] = time.Now()
-					es.lastTradeSide[symbol] = side
-					es.mu.Unlock()
-
-					// Launch Ghost Monitor with POINTER to Session
-					go es.MonitorPosition(ghost)
-				}
-			}
-
-			// TERMINAL STATES
-			if order.Status == futures.OrderStatusTypeFilled {
-				log.Printf("✅ MAKER EXECUTION COMPLETE! Order %d FILLED (%.4f).", orderID, filledQty)
-				es.notifier.Notify("✅ *MAKER ORDER FILLED COMPLETELY*")
-				return // Monitor exits, Ghost keeps running
-			} else if order.Status == futures.OrderStatusTypeCanceled || order.Status == futures.OrderStatusTypeRejected {
-				if lastFilledQty > 0 {
-					log.Printf("❌ ORDER %d DEAD (%s) with Partial: %.4f. Ghost continues.", orderID, order.Status, lastFilledQty)
-				} else {
-					log.Printf("❌ ORDER %d DEAD (%s). Stopped.", orderID, order.Status)
-				}
+			if applyUpdate(filledQty, order.Status) {
 				return
 			}
 		}
@@ -1032,16 +1113,17 @@ func (es *ExecutionService) placeProtectionOrders(signal Signal, qty string, pro
 
 	log.Printf("🛡️ PLACING STOP LIMIT (Aggressive) for %s @ %.4f (Limit: %s) Qty: %s", signal.Symbol, signal.StopLoss, limitPriceStr, qty)
 
-	_, err := es.client.NewCreateOrderService().
-		Symbol(signal.Symbol).
-		Side(closeSide).
-		Type(futures.OrderType("STOP")). // <--- CHANGED TO STOP (Limit)
-		StopPrice(fmt.Sprintf("%.4f", signal.StopLoss)).
-		Price(limitPriceStr). // <--- REQUIRED for Limit Stop
-		Quantity(qty).
-		ReduceOnly(true).
-		WorkingType(futures.WorkingTypeMarkPrice).
-		Do(context.Background())
+	_, err := es.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:        signal.Symbol,
+		Side:          closeSide,
+		Type:          futures.OrderType("STOP"), // <--- CHANGED TO STOP (Limit)
+		StopPrice:     fmt.Sprintf("%.4f", signal.StopLoss),
+		Price:         limitPriceStr, // <--- REQUIRED for Limit Stop
+		Quantity:      qty,
+		ReduceOnly:    true,
+		WorkingType:   futures.WorkingTypeMarkPrice,
+		ClientOrderID: newClientOrderID(ghostSLPrefix), // Adopted on restart
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to place STOP LOSS: %v", err)
@@ -1050,16 +1132,17 @@ func (es *ExecutionService) placeProtectionOrders(signal Signal, qty string, pro
 	log.Printf("🛡️ PROTECT: Stop Loss set at %.4f", signal.StopLoss)
 
 	// TAKE PROFIT
-	_, err = es.client.NewCreateOrderService().
-		Symbol(signal.Symbol).
-		Side(closeSide).
-		Type(futures.OrderType("TAKE_PROFIT_MARKET")).
-		StopPrice(fmt.Sprintf("%.4f", signal.Target)).
-		WorkingType(futures.WorkingTypeMarkPrice). // Explicit Trigger
-		PriceProtect(true).                        // Enable Mark Price Protection
-		Quantity(qty).                             // Required for ReduceOnly
-		ReduceOnly(true).                          // Standard TP behavior
-		Do(context.Background())
+	_, err = es.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:        signal.Symbol,
+		Side:          closeSide,
+		Type:          futures.OrderType("TAKE_PROFIT_MARKET"),
+		StopPrice:     fmt.Sprintf("%.4f", signal.Target),
+		WorkingType:   futures.WorkingTypeMarkPrice, // Explicit Trigger
+		PriceProtect:  true,                         // Enable Mark Price Protection
+		Quantity:      qty,                          // Required for ReduceOnly
+		ReduceOnly:    true,                         // Standard TP behavior
+		ClientOrderID: newClientOrderID(ghostTPPrefix),
+	})
 
 	if err != nil {
 		// Soft error? No, users want TP. But SL is the critical one.
@@ -1081,13 +1164,13 @@ func (es *ExecutionService) emergencyClose(symbol, qty string, entrySide futures
 	}
 
 	log.Printf("🚨 EXECUTING EMERGENCY CLOSE for %s", symbol)
-	_, err := es.client.NewCreateOrderService().
-		Symbol(symbol).
-		Side(side).
-		Type(futures.OrderTypeMarket).
-		Quantity(qty). // Or define ReduceOnly=true instead of qty (safer)
-		ReduceOnly(true).
-		Do(context.Background())
+	_, err := es.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:     symbol,
+		Side:       side,
+		Type:       futures.OrderTypeMarket,
+		Quantity:   qty, // Or define ReduceOnly=true instead of qty (safer)
+		ReduceOnly: true,
+	})
 
 	if err != nil {
 		log.Printf("💀 CRITICAL: FAILED TO EMERGENCY CLOSE. PANIC! Error: %v", err)
@@ -1099,6 +1182,7 @@ func (es *ExecutionService) emergencyClose(symbol, qty string, entrySide futures
 		es.mu.Lock()
 		delete(es.openPositions, symbol)
 		es.mu.Unlock()
+		es.risk.Release(RiskEngineExec, symbol)
 	}
 }
 
@@ -1110,12 +1194,16 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 	es.mu.Lock()
 	es.activeSessions[gs.Symbol] = gs
 	es.mu.Unlock()
+	es.saveState()
 
-	// Cleanup on exit
+	// Cleanup on exit (stats were updated by the closing branch)
 	defer func() {
 		es.mu.Lock()
 		delete(es.activeSessions, gs.Symbol)
+		delete(es.openPositions, gs.Symbol)
 		es.mu.Unlock()
+		es.risk.Release(RiskEngineExec, gs.Symbol)
+		es.saveState()
 	}()
 
 	// Stop All Goroutines
@@ -1139,9 +1227,31 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 
 	for {
 		select {
+		case <-gs.closed:
+			// Exchange flattened the position (TP/SL fill or Liquidation) -> User Stream
+			gs.mu.Lock()
+			finalPnL := gs.RealizedPnL
+			gs.mu.Unlock()
+			log.Printf("🏁 POSITION CLOSED ON EXCHANGE (%s) | Realized: $%.2f", gs.Symbol, finalPnL)
+			es.notifier.Notify(fmt.Sprintf("🏁 *POSITION CLOSED* (%s)\nRealized PnL: $%.2f", gs.Symbol, finalPnL))
+
+			es.mu.Lock()
+			delete(es.openPositions, gs.Symbol)
+			es.TradeCount++
+			if finalPnL > 0 {
+				es.WinCount++
+				if finalPnL > es.BestTrade {
+					es.BestTrade = finalPnL
+				}
+			}
+			es.DailyLoss -= finalPnL
+			es.mu.Unlock()
+			es.risk.RecordPnL(RiskEngineExec, finalPnL)
+			return
+
 		case <-ticker.C:
 			// Fetch Price
-			prices, err := es.client.NewListPricesService().Symbol(gs.Symbol).Do(context.Background())
+			prices, err := es.gateway.Prices(context.Background(), gs.Symbol)
 			if err != nil || len(prices) == 0 {
 				continue
 			}
@@ -1154,7 +1264,9 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 				diff = -diff
 			}
 
-			pnl := diff * gs.CurrentQty
+			gs.mu.Lock()
+			pnl := diff * gs.CurrentQty // Partial fills update CurrentQty from the user stream
+			gs.mu.Unlock()
 
 			// 0. BREAKEVEN TRIGGER (Protect Capital at +$50)
 			// User Req: "Move SL to $0 at +$50 profit"
@@ -1174,8 +1286,9 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 				if needsUpdate {
 					log.Printf("🛡️ BREAKEVEN ACTIVATED (%s): Profit $%.2f. Moving SL to Entry.", gs.Symbol, pnl)
 					// Cancel & Update SL
-					es.client.NewCancelAllOpenOrdersService().Symbol(gs.Symbol).Do(context.Background())
-					gs.StopLoss = gs.EntryPrice
+					es.gateway.CancelAllOrders(context.Background(), gs.Symbol)
+					gs.SetStopLoss(gs.EntryPrice)
+					es.saveState()
 					// In real world, place new Stop Order here.
 					es.notifier.Notify(fmt.Sprintf("🛡️ *BREAKEVEN SECURED* (%s)\nProfit: $%.2f. SL moved to Entry.", gs.Symbol, pnl))
 				}
@@ -1203,12 +1316,13 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 
 				// Cancel Old SL / Place New SL (Implementation optional or assumed manual for now?)
 				// We assume we cancel all open orders and place a new Conditional Stop?
-				es.client.NewCancelAllOpenOrdersService().Symbol(gs.Symbol).Do(context.Background())
+				es.gateway.CancelAllOrders(context.Background(), gs.Symbol)
 
 				// Place New Hard SL @ Locked
 				// We should ideally assume placeProtectionOrders can handle update, but simple Cancel/Replace is safer here.
 				// For brevity, we just log "VIRTUAL SL MOVED". Real code would API call.
-				gs.StopLoss = newSL
+				gs.SetStopLoss(newSL)
+				es.saveState()
 				log.Printf("🔒 SL LOCKED at %.2f (Virtual)", newSL)
 			}
 
@@ -1237,7 +1351,8 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 					}
 
 					if update {
-						gs.StopLoss = dynamicSL
+						gs.SetStopLoss(dynamicSL)
+						es.saveState()
 						log.Printf("⛓️ TRAILING SL UPDATED: %.2f", gs.StopLoss)
 					}
 				}
@@ -1263,7 +1378,12 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 				if gs.Side == "SHORT" {
 					closeSide = futures.SideTypeBuy
 				}
-				es.client.NewCreateOrderService().Symbol(gs.Symbol).Side(closeSide).Type(futures.OrderTypeMarket).Quantity(fmt.Sprintf("%.3f", gs.CurrentQty)).Do(context.Background())
+				es.gateway.PlaceOrder(context.Background(), OrderRequest{
+					Symbol:   gs.Symbol,
+					Side:     closeSide,
+					Type:     futures.OrderTypeMarket,
+					Quantity: fmt.Sprintf("%.3f", gs.CurrentQty),
+				})
 
 				// Calc Loss
 				finalPnL := (currentPrice - gs.EntryPrice) * gs.CurrentQty
@@ -1282,6 +1402,7 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 					}
 				}
 				es.mu.Unlock()
+				es.risk.RecordPnL(RiskEngineExec, finalPnL)
 
 				return
 			}
@@ -1289,6 +1410,419 @@ func (es *ExecutionService) MonitorPosition(gs *GhostSession) {
 	}
 }
 
+// ============================================================================
+// STARTUP RECONCILIATION (Adopt Live Positions)
+// ============================================================================
+
+// reconcile rebuilds Ghost Sessions for positions that survived a restart.
+// Only positions protected by our tagged orders (ghost-tp- / ghost-sl-) are
+// adopted. Predator-tagged positions are left to Predator; anything else
+// (opened by hand, other bots) is listed as foreign and never touched.
+func (es *ExecutionService) reconcile() {
+	snap, err := LoadExchangeSnapshot(es.gateway)
+	if err != nil {
+		log.Printf("⚠️ RECONCILE FAILED: %v. Existing positions are NOT monitored!", err)
+		es.notifier.Notify(fmt.Sprintf("⚠️ *RECONCILE FAILED*\n%v\nExisting positions are NOT monitored!", err))
+		return
+	}
+
+	var adopted, skipped, foreign []string
+	for symbol, lp := range snap.Positions {
+		if len(lp.OrdersWithPrefix(predatorOrderPrefix)) > 0 {
+			continue // Owned by PredatorEngine
+		}
+		if len(lp.OrdersWithPrefix(ghostTPPrefix)) == 0 && len(lp.OrdersWithPrefix(ghostSLPrefix)) == 0 {
+			foreign = append(foreign, fmt.Sprintf("%s %s %.4f @ %.4f", lp.Side(), symbol, lp.Qty(), lp.Entry))
+			continue
+		}
+		if _, ok := es.config.Profiles[symbol]; !ok {
+			skipped = append(skipped, fmt.Sprintf("%s %s %.4f (no profile)", lp.Side(), symbol, lp.Qty()))
+			continue
+		}
+		if es.config.DryRun {
+			skipped = append(skipped, fmt.Sprintf("%s %s %.4f (dry run)", lp.Side(), symbol, lp.Qty()))
+			continue
+		}
+
+		gs := es.adoptPosition(lp)
+		adopted = append(adopted, fmt.Sprintf("%s %s %.4f @ %.4f (SL %.4f | TP %.4f)", gs.Side, symbol, gs.CurrentQty, gs.EntryPrice, gs.StopLoss, gs.TakeProfit))
+	}
+
+	// Our protection orders without a position (closed while offline)
+	for symbol, orders := range snap.Orphans {
+		for _, o := range ordersWithPrefix(orders, ghostOrderPrefix) {
+			log.Printf("🗑️ RECONCILE: Cancelling orphan %s order %d (%s)", symbol, o.OrderID, o.ClientOrderID)
+			es.gateway.CancelOrder(context.Background(), symbol, o.OrderID)
+		}
+	}
+
+	if len(adopted) == 0 && len(skipped) == 0 && len(foreign) == 0 {
+		log.Println("♻️ RECONCILE: No open positions on exchange.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("♻️ *STARTUP RECONCILIATION*\n")
+	if len(adopted) > 0 {
+		sb.WriteString(fmt.Sprintf("\n👻 Adopted (%d):\n", len(adopted)))
+		for _, line := range adopted {
+			sb.WriteString("• " + line + "\n")
+		}
+	}
+	if len(skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️ NOT Monitored (%d):\n", len(skipped)))
+		for _, line := range skipped {
+			sb.WriteString("• " + line + "\n")
+		}
+	}
+	if len(foreign) > 0 {
+		sb.WriteString(fmt.Sprintf("\n🚫 Foreign, untouched (%d):\n", len(foreign)))
+		for _, line := range foreign {
+			sb.WriteString("• " + line + "\n")
+		}
+	}
+	log.Printf("♻️ RECONCILE: Adopted %d | Not Monitored %d | Foreign %d", len(adopted), len(skipped), len(foreign))
+	es.notifier.Notify(sb.String())
+}
+
+// adoptPosition restores SL/TP from our tagged orders (or the sizing rule) and resumes the Ghost Monitor
+func (es *ExecutionService) adoptPosition(lp *LivePosition) *GhostSession {
+	side, qty := lp.Side(), lp.Qty()
+
+	// Sizing rule: Qty = RiskPerTrade / RiskDist  =>  RiskDist = RiskPerTrade / Qty (2R target)
+	riskDist := es.config.RiskPerTrade / qty
+	if es.config.RiskPerTrade <= 0 {
+		riskDist = lp.Entry * 0.01 // Fallback: 1% Stop
+	}
+	sl, tp := lp.Entry-riskDist, lp.Entry+2*riskDist
+	if side == "SHORT" {
+		sl, tp = lp.Entry+riskDist, lp.Entry-2*riskDist
+	}
+
+	for _, o := range lp.OrdersWithPrefix(ghostSLPrefix) {
+		if stop, _ := strconv.ParseFloat(o.StopPrice, 64); stop > 0 {
+			sl = stop
+		}
+	}
+	for _, o := range lp.OrdersWithPrefix(ghostTPPrefix) {
+		if stop, _ := strconv.ParseFloat(o.StopPrice, 64); stop > 0 {
+			tp = stop
+		}
+	}
+	for _, o := range lp.OrdersWithPrefix("web-target-") {
+		if price, _ := strconv.ParseFloat(o.Price, 64); price > 0 {
+			tp = price
+		}
+	}
+
+	// Journaled session wins over derived levels (keeps trailing / breakeven stops)
+	if saved, ok := es.restoredSessions[lp.Symbol]; ok && saved.Side == side {
+		sl, tp = saved.StopLoss, saved.TakeProfit
+	}
+
+	log.Printf("♻️ ADOPTING %s %s %.4f @ %.4f (SL: %.4f | TP: %.4f)", side, lp.Symbol, qty, lp.Entry, sl, tp)
+
+	gs := NewGhostSession(lp.Symbol, lp.Entry, sl, tp, qty, side)
+	es.risk.Adopt(RiskEngineExec, lp.Symbol, side, qty*lp.Entry)
+	es.mu.Lock()
+	es.openPositions[lp.Symbol] = true
+	es.lastTradeTime[lp.Symbol] = time.Now()
+	es.lastTradeSide[lp.Symbol] = side
+	es.mu.Unlock()
+
+	go es.MonitorPosition(gs)
+	return gs
+}
+
+// ============================================================================
+// DURABLE STATE (Journal)
+// ============================================================================
+
+const executionStateKey = "execution"
+
+// executionState is the journaled form of the kill switch + stats
+type executionState struct {
+	Day               string                       `json:"day"`
+	DailyLossLimit    float64                      `json:"dailyLossLimit"` // dailyLoss (signal gate)
+	DailyLoss         float64                      `json:"dailyLoss"`
+	ConsecutiveLosses int                          `json:"consecutiveLosses"`
+	ChaosModeUntil    time.Time                    `json:"chaosModeUntil"`
+	LastLossTime      time.Time                    `json:"lastLossTime"`
+	ProcessedSigs     map[string]time.Time         `json:"processedSigs"`
+	LastTradeTime     map[string]time.Time         `json:"lastTradeTime"`
+	LastTradeSide     map[string]string            `json:"lastTradeSide"`
+	TotalFees         float64                      `json:"totalFees"`
+	TradeCount        int                          `json:"tradeCount"`
+	WinCount          int                          `json:"winCount"`
+	BestTrade         float64                      `json:"bestTrade"`
+	Sessions          map[string]ghostSessionState `json:"sessions"`
+}
+
+type ghostSessionState struct {
+	Side        string  `json:"side"`
+	Entry       float64 `json:"entry"`
+	StopLoss    float64 `json:"stopLoss"`
+	TakeProfit  float64 `json:"takeProfit"`
+	Qty         float64 `json:"qty"`
+	RealizedPnL float64 `json:"realizedPnL"`
+}
+
+// AttachStateStore restores persisted counters and journals every change from now on (call before Start)
+func (es *ExecutionService) AttachStateStore(store *StateStore) {
+	var st executionState
+	restored := store.Load(executionStateKey, &st)
+
+	es.mu.Lock()
+	es.store = store
+	if restored {
+		es.consecutiveLosses = st.ConsecutiveLosses
+		es.chaosModeUntil = st.ChaosModeUntil
+		es.lastLossTime = st.LastLossTime
+		es.TotalFees = st.TotalFees
+		for id, seen := range st.ProcessedSigs {
+			es.processedSigs[id] = seen
+		}
+		for sym, t := range st.LastTradeTime {
+			es.lastTradeTime[sym] = t
+		}
+		for sym, side := range st.LastTradeSide {
+			es.lastTradeSide[sym] = side
+		}
+		es.restoredSessions = st.Sessions
+
+		if st.Day == store.TradingDay(time.Now()) {
+			es.dailyLoss = st.DailyLossLimit
+			es.DailyLoss = st.DailyLoss
+			es.TradeCount = st.TradeCount
+			es.WinCount = st.WinCount
+			es.BestTrade = st.BestTrade
+		}
+	}
+	es.mu.Unlock()
+
+	if restored {
+		log.Printf("💾 EXECUTION STATE RESTORED: Daily Loss $%.2f | Trades %d | Chaos Until %s | %d session(s)",
+			es.DailyLoss, es.TradeCount, es.chaosModeUntil.Format(time.Kitchen), len(st.Sessions))
+	}
+
+	store.OnRollover(es.resetDaily)
+}
+
+// resetDaily clears daily counters at the trading day boundary
+func (es *ExecutionService) resetDaily(day string) {
+	es.mu.Lock()
+	es.dailyLoss = 0
+	es.DailyLoss = 0
+	es.TradeCount = 0
+	es.WinCount = 0
+	es.BestTrade = 0
+	es.mu.Unlock()
+
+	log.Printf("🌅 EXECUTION: Daily counters reset for %s", day)
+	es.saveState()
+}
+
+// saveState journals the current counters (caller must NOT hold es.mu).
+// saveMu spans snapshot + Save so the newest snapshot is always journaled last.
+func (es *ExecutionService) saveState() {
+	es.saveMu.Lock()
+	defer es.saveMu.Unlock()
+
+	es.mu.Lock()
+	if es.store == nil {
+		es.mu.Unlock()
+		return
+	}
+
+	// Duplicate guard only needs recent IDs
+	for id, seen := range es.processedSigs {
+		if time.Since(seen) > 24*time.Hour {
+			delete(es.processedSigs, id)
+		}
+	}
+
+	st := executionState{
+		Day:               es.store.TradingDay(time.Now()),
+		DailyLossLimit:    es.dailyLoss,
+		DailyLoss:         es.DailyLoss,
+		ConsecutiveLosses: es.consecutiveLosses,
+		ChaosModeUntil:    es.chaosModeUntil,
+		LastLossTime:      es.lastLossTime,
+		ProcessedSigs:     make(map[string]time.Time, len(es.processedSigs)),
+		LastTradeTime:     make(map[string]time.Time, len(es.lastTradeTime)),
+		LastTradeSide:     make(map[string]string, len(es.lastTradeSide)),
+		TotalFees:         es.TotalFees,
+		TradeCount:        es.TradeCount,
+		WinCount:          es.WinCount,
+		BestTrade:         es.BestTrade,
+		Sessions:          make(map[string]ghostSessionState, len(es.activeSessions)),
+	}
+	for id, seen := range es.processedSigs {
+		st.ProcessedSigs[id] = seen
+	}
+	for sym, t := range es.lastTradeTime {
+		st.LastTradeTime[sym] = t
+	}
+	for sym, side := range es.lastTradeSide {
+		st.LastTradeSide[sym] = side
+	}
+	for sym, gs := range es.activeSessions {
+		gs.mu.Lock()
+		st.Sessions[sym] = ghostSessionState{
+			Side:        gs.Side,
+			Entry:       gs.EntryPrice,
+			StopLoss:    gs.StopLoss,
+			TakeProfit:  gs.TakeProfit,
+			Qty:         gs.CurrentQty,
+			RealizedPnL: gs.RealizedPnL,
+		}
+		gs.mu.Unlock()
+	}
+	store := es.store
+	es.mu.Unlock()
+
+	if err := store.Save(executionStateKey, st); err != nil {
+		log.Printf("⚠️ STATE SAVE FAILED (execution): %v", err)
+	}
+}
+
+// ============================================================================
+// USER DATA STREAM (Push Fills / Account / Margin Calls)
+// ============================================================================
+
+// ListenUserStream routes user data events to order monitors and ghost sessions
+func (es *ExecutionService) ListenUserStream(stream *UserStreamManager) {
+	events := stream.Subscribe()
+
+	es.mu.Lock()
+	es.userStream = stream
+	es.mu.Unlock()
+
+	go func() {
+		for event := range events {
+			switch event.Event {
+			case futures.UserDataEventTypeOrderTradeUpdate:
+				es.onOrderUpdate(event.OrderTradeUpdate)
+			case futures.UserDataEventTypeAccountUpdate:
+				es.onAccountUpdate(event.AccountUpdate)
+			case futures.UserDataEventTypeMarginCall:
+				log.Printf("🚨 MARGIN CALL: %d position(s) at risk (Cross Wallet: %s)", len(event.MarginCallPositions), event.CrossWalletBalance)
+				es.notifier.Notify(fmt.Sprintf("🚨 *MARGIN CALL* 🚨\n%d position(s) near liquidation!", len(event.MarginCallPositions)))
+			}
+		}
+	}()
+}
+
+// watchOrder registers for pushed updates of orderID. Call the returned func when done.
+func (es *ExecutionService) watchOrder(orderID int64) (<-chan futures.WsOrderTradeUpdate, func()) {
+	ch := make(chan futures.WsOrderTradeUpdate, 16)
+
+	es.mu.Lock()
+	es.orderWatchers[orderID] = append(es.orderWatchers[orderID], ch)
+	es.mu.Unlock()
+
+	return ch, func() {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		watchers := es.orderWatchers[orderID]
+		for i, w := range watchers {
+			if w == ch {
+				watchers = append(watchers[:i], watchers[i+1:]...)
+				break
+			}
+		}
+		if len(watchers) == 0 {
+			delete(es.orderWatchers, orderID)
+		} else {
+			es.orderWatchers[orderID] = watchers
+		}
+	}
+}
+
+func (es *ExecutionService) onOrderUpdate(update futures.WsOrderTradeUpdate) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	for _, ch := range es.orderWatchers[update.ID] {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+
+	// Liquidation orders carry an "autoclose-" client ID
+	if strings.HasPrefix(update.ClientOrderID, "autoclose-") && update.Status == futures.OrderStatusTypeFilled {
+		log.Printf("💀 LIQUIDATED: %s %s %s @ %s", update.Symbol, update.Side, update.AccumulatedFilledQty, update.AveragePrice)
+		es.notifier.Notify(fmt.Sprintf("💀 *LIQUIDATED* (%s)\nQty: %s @ %s", update.Symbol, update.AccumulatedFilledQty, update.AveragePrice))
+	}
+
+	if update.ExecutionType != futures.OrderExecutionTypeTrade || strings.HasPrefix(update.ClientOrderID, predatorOrderPrefix) {
+		return // Predator's fills on a shared account are its own
+	}
+
+	// Actual commission on every fill (entries included, before the session exists)
+	fee, _ := strconv.ParseFloat(update.Commission, 64)
+	es.TotalFees += fee
+
+	// Realized PnL on exits (TP / SL / Liquidation / Manual)
+	if gs, ok := es.activeSessions[update.Symbol]; ok {
+		rp, _ := strconv.ParseFloat(update.RealizedPnL, 64)
+		gs.mu.Lock()
+		gs.RealizedPnL += rp - fee
+		gs.mu.Unlock()
+	}
+}
+
+func (es *ExecutionService) onAccountUpdate(update futures.WsAccountUpdate) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	for _, p := range update.Positions {
+		gs, ok := es.activeSessions[p.Symbol]
+		if !ok {
+			continue
+		}
+		amt, _ := strconv.ParseFloat(p.Amount, 64)
+		if amt == 0 {
+			gs.MarkClosed()
+			continue
+		}
+		gs.UpdateQty(math.Abs(amt))
+	}
+}
+
+// launchEntryMonitor runs monitorLimitOrder as a tracked entry (see beginEntry)
+func (es *ExecutionService) launchEntryMonitor(symbol string, orderID int64, entry, sl, tp, plannedQty float64, side string) {
+	es.beginEntry(symbol)
+	go func() {
+		defer es.endEntry(symbol)
+		es.monitorLimitOrder(symbol, orderID, entry, sl, tp, plannedQty, side)
+	}()
+}
+
+// beginEntry marks an entry in flight. Counted synchronously so a walk/failsafe
+// hand-off never leaves a gap in which the risk claim could be released.
+func (es *ExecutionService) beginEntry(symbol string) {
+	es.mu.Lock()
+	es.pendingEntries[symbol]++
+	es.mu.Unlock()
+}
+
+// endEntry frees the risk claim once the last entry attempt ends without a position
+func (es *ExecutionService) endEntry(symbol string) {
+	es.mu.Lock()
+	es.pendingEntries[symbol]--
+	flat := es.pendingEntries[symbol] <= 0 && !es.openPositions[symbol]
+	if es.pendingEntries[symbol] <= 0 {
+		delete(es.pendingEntries, symbol)
+	}
+	es.mu.Unlock()
+
+	if flat {
+		es.risk.Release(RiskEngineExec, symbol)
+	}
+}
+
 // checkCriticalError detects API Fatalities and halts trading via Alert
 func (es *ExecutionService) checkCriticalError(err error) {
 	if err == nil {
@@ -1306,7 +1840,7 @@ func (es *ExecutionService) checkCriticalError(err error) {
 // FetchExchangeInfo loads precision data from Binance to prevent -1111 errors
 func (es *ExecutionService) FetchExchangeInfo() {
 	log.Println("🔌 Fetching Exchange Info (Precision Data)...")
-	info, err := es.client.NewExchangeInfoService().Do(context.Background())
+	info, err := es.gateway.ExchangeInfo(context.Background())
 	if err != nil {
 		log.Printf("⚠️ Failed to fetch Exchange Info: %v. Using Defaults.", err)
 		return
@@ -1333,6 +1867,7 @@ func (es *ExecutionService) FetchExchangeInfo() {
 			TickSize: tickSize,
 			StepSize: stepSize,
 		}
+		Instruments.SetTickSize(s.Symbol, tickSize) // Analyzer price buckets
 	}
 	log.Printf("✅ Exchange Info Loaded. Symbols tracked: %d", len(es.symbolInfo))
 }
@@ -1409,7 +1944,7 @@ func (es *ExecutionService) EmergencyStopAll() {
 
 	// 1. Cancel All Orders
 	for symbol := range es.openPositions {
-		err := es.client.NewCancelAllOpenOrdersService().Symbol(symbol).Do(context.Background())
+		err := es.gateway.CancelAllOrders(context.Background(), symbol)
 		if err != nil {
 			log.Printf("❌ Failed to cancel orders for %s: %v", symbol, err)
 		} else {
@@ -1430,12 +1965,12 @@ func (es *ExecutionService) EmergencyStopAll() {
 			}
 
 			// Execute Market Close
-			_, err := es.client.NewCreateOrderService().
-				Symbol(session.Symbol).
-				Side(closeSide).
-				Type(futures.OrderTypeMarket).
-				Quantity(fmt.Sprintf("%.3f", session.CurrentQty)).
-				Do(context.Background())
+			_, err := es.gateway.PlaceOrder(context.Background(), OrderRequest{
+				Symbol:   session.Symbol,
+				Side:     closeSide,
+				Type:     futures.OrderTypeMarket,
+				Quantity: fmt.Sprintf("%.3f", session.CurrentQty),
+			})
 
 			if err != nil {
 				log.Printf("❌ Failed to close %s: %v", session.Symbol, err)
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// FAKE GATEWAY (In-Memory, Scriptable)
+// ============================================================================
+// For tests and offline runs. Seed market data with SetPrice/SetKlines/...,
+// script failures with FailNext, and inspect Placed/Canceled afterwards.
+// Orders are recorded as NEW; call FillOrder to move them along.
+
+// FakeGateway is an in-memory Gateway
+type FakeGateway struct {
+	mu sync.Mutex
+
+	// Scriptable Market Data
+	prices       map[string]float64                // Symbol -> Last Price
+	bookTickers  map[string]*futures.BookTicker    // Symbol -> Best Bid/Ask
+	klines       map[string][]*futures.Kline       // "Symbol_Interval" -> Candles
+	depth        map[string]*futures.DepthResponse // Symbol -> Snapshot
+	openInterest map[string]float64                // Symbol -> Open Interest (contracts)
+	exchangeInfo *futures.ExchangeInfo
+
+	// Scriptable Account
+	positions map[string]*futures.PositionRisk
+	account   *futures.Account
+	balances  []*futures.Balance
+
+	// Scripted Errors: method name ("PlaceOrder", "GetOrder", ...) -> queued errors
+	failures map[string][]error
+
+	// Recorded Calls
+	nextID     int64
+	orders     map[int64]*futures.Order
+	Placed     []OrderRequest
+	Canceled   []int64
+	Leverage   map[string]int
+	MarginType map[string]futures.MarginType
+	DualSide   bool
+
+	// User Data Stream
+	StreamURL  string // Websocket base served by the test (empty = no stream)
+	ListenKeys int    // listenKeys issued
+}
+
+// NewFakeGateway creates an empty fake
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		prices:       make(map[string]float64),
+		bookTickers:  make(map[string]*futures.BookTicker),
+		klines:       make(map[string][]*futures.Kline),
+		depth:        make(map[string]*futures.DepthResponse),
+		openInterest: make(map[string]float64),
+		exchangeInfo: &futures.ExchangeInfo{},
+		positions:    make(map[string]*futures.PositionRisk),
+		account:      &futures.Account{},
+		failures:     make(map[string][]error),
+		nextID:       1,
+		orders:       make(map[int64]*futures.Order),
+		Leverage:     make(map[string]int),
+		MarginType:   make(map[string]futures.MarginType),
+	}
+}
+
+// ============================================================================
+// SCRIPTING
+// ============================================================================
+
+// SetPrice sets the last price and a zero-spread book ticker
+func (f *FakeGateway) SetPrice(symbol string, price float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prices[symbol] = price
+	p := strconv.FormatFloat(price, 'f', -1, 64)
+	f.bookTickers[symbol] = &futures.BookTicker{Symbol: symbol, BidPrice: p, AskPrice: p, BidQuantity: "1", AskQuantity: "1"}
+}
+
+// SetBookTicker sets best bid/ask
+func (f *FakeGateway) SetBookTicker(symbol string, bid, ask float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bookTickers[symbol] = &futures.BookTicker{
+		Symbol:      symbol,
+		BidPrice:    strconv.FormatFloat(bid, 'f', -1, 64),
+		AskPrice:    strconv.FormatFloat(ask, 'f', -1, 64),
+		BidQuantity: "1",
+		AskQuantity: "1",
+	}
+}
+
+// SetKlines sets the candles returned for symbol/interval (oldest first)
+func (f *FakeGateway) SetKlines(symbol, interval string, klines []*futures.Kline) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.klines[symbol+"_"+interval] = klines
+}
+
+// SetDepth sets the order book snapshot for symbol
+func (f *FakeGateway) SetDepth(symbol string, depth *futures.DepthResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.depth[symbol] = depth
+}
+
+// SetOpenInterest sets the open interest (contracts) for symbol
+func (f *FakeGateway) SetOpenInterest(symbol string, oi float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.openInterest[symbol] = oi
+}
+
+// SetExchangeInfo sets the exchangeInfo response
+func (f *FakeGateway) SetExchangeInfo(info *futures.ExchangeInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.exchangeInfo = info
+}
+
+// SetPosition sets a one-way position (amt 0 removes it)
+func (f *FakeGateway) SetPosition(symbol string, amt, entry float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if amt == 0 {
+		delete(f.positions, symbol)
+		return
+	}
+	f.positions[symbol] = &futures.PositionRisk{
+		Symbol:       symbol,
+		PositionAmt:  strconv.FormatFloat(amt, 'f', -1, 64),
+		EntryPrice:   strconv.FormatFloat(entry, 'f', -1, 64),
+		PositionSide: string(futures.PositionSideTypeBoth),
+	}
+}
+
+// SetAccount sets the account and balance responses
+func (f *FakeGateway) SetAccount(account *futures.Account, balances []*futures.Balance) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.account = account
+	f.balances = balances
+}
+
+// FailNext makes the next call to method return err
+func (f *FakeGateway) FailNext(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method] = append(f.failures[method], err)
+}
+
+// FillOrder marks a recorded order as FILLED at price
+func (f *FakeGateway) FillOrder(orderID int64, price float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if o, ok := f.orders[orderID]; ok {
+		o.Status = futures.OrderStatusTypeFilled
+		o.ExecutedQuantity = o.OrigQuantity
+		o.AvgPrice = strconv.FormatFloat(price, 'f', -1, 64)
+		o.UpdateTime = time.Now().UnixMilli()
+	}
+}
+
+// popFailure returns the next scripted error for method (caller holds f.mu)
+func (f *FakeGateway) popFailure(method string) error {
+	queue := f.failures[method]
+	if len(queue) == 0 {
+		return nil
+	}
+	f.failures[method] = queue[1:]
+	return queue[0]
+}
+
+// ============================================================================
+// GATEWAY
+// ============================================================================
+
+func (f *FakeGateway) PlaceOrder(ctx context.Context, req OrderRequest) (*futures.CreateOrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("PlaceOrder"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	id := f.nextID
+	f.nextID++
+	f.Placed = append(f.Placed, req)
+	f.orders[id] = &futures.Order{
+		Symbol:        req.Symbol,
+		OrderID:       id,
+		ClientOrderID: req.ClientOrderID,
+		Price:         req.Price,
+		ReduceOnly:    req.ReduceOnly,
+		OrigQuantity:  req.Quantity,
+		Status:        futures.OrderStatusTypeNew,
+		TimeInForce:   req.TimeInForce,
+		Type:          req.Type,
+		OrigType:      req.Type,
+		Side:          req.Side,
+		StopPrice:     req.StopPrice,
+		WorkingType:   req.WorkingType,
+		ClosePosition: req.ClosePosition,
+		Time:          now,
+		UpdateTime:    now,
+	}
+
+	return &futures.CreateOrderResponse{
+		Symbol:        req.Symbol,
+		OrderID:       id,
+		ClientOrderID: req.ClientOrderID,
+		Price:         req.Price,
+		OrigQuantity:  req.Quantity,
+		Status:        futures.OrderStatusTypeNew,
+		TimeInForce:   req.TimeInForce,
+		Type:          req.Type,
+		Side:          req.Side,
+		StopPrice:     req.StopPrice,
+		UpdateTime:    now,
+		ReduceOnly:    req.ReduceOnly,
+		ClosePosition: req.ClosePosition,
+	}, nil
+}
+
+func (f *FakeGateway) CancelOrder(ctx context.Context, symbol string, orderID int64) (*futures.CancelOrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("CancelOrder"); err != nil {
+		return nil, err
+	}
+
+	o, ok := f.orders[orderID]
+	if !ok || o.Symbol != symbol {
+		return nil, fmt.Errorf("code=-2011, msg=Unknown order sent.")
+	}
+	o.Status = futures.OrderStatusTypeCanceled
+	f.Canceled = append(f.Canceled, orderID)
+	return &futures.CancelOrderResponse{
+		Symbol:           o.Symbol,
+		OrderID:          o.OrderID,
+		ClientOrderID:    o.ClientOrderID,
+		Price:            o.Price,
+		OrigQuantity:     o.OrigQuantity,
+		ExecutedQuantity: o.ExecutedQuantity,
+		Status:           o.Status,
+		Type:             o.Type,
+		Side:             o.Side,
+	}, nil
+}
+
+func (f *FakeGateway) CancelAllOrders(ctx context.Context, symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("CancelAllOrders"); err != nil {
+		return err
+	}
+
+	for id, o := range f.orders {
+		if o.Symbol == symbol && isOpenStatus(o.Status) {
+			o.Status = futures.OrderStatusTypeCanceled
+			f.Canceled = append(f.Canceled, id)
+		}
+	}
+	return nil
+}
+
+func (f *FakeGateway) GetOrder(ctx context.Context, symbol string, orderID int64) (*futures.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("GetOrder"); err != nil {
+		return nil, err
+	}
+
+	o, ok := f.orders[orderID]
+	if !ok || o.Symbol != symbol {
+		return nil, fmt.Errorf("code=-2013, msg=Order does not exist.")
+	}
+	copied := *o
+	return &copied, nil
+}
+
+func (f *FakeGateway) OpenOrders(ctx context.Context, symbol string) ([]*futures.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("OpenOrders"); err != nil {
+		return nil, err
+	}
+
+	res := []*futures.Order{}
+	for id := int64(1); id < f.nextID; id++ {
+		if o, ok := f.orders[id]; ok && (symbol == "" || o.Symbol == symbol) && isOpenStatus(o.Status) {
+			copied := *o
+			res = append(res, &copied)
+		}
+	}
+	return res, nil
+}
+
+func (f *FakeGateway) Positions(ctx context.Context, symbol string) ([]*futures.PositionRisk, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("Positions"); err != nil {
+		return nil, err
+	}
+
+	res := []*futures.PositionRisk{}
+	for sym, pos := range f.positions {
+		if symbol == "" || sym == symbol {
+			copied := *pos
+			res = append(res, &copied)
+		}
+	}
+	return res, nil
+}
+
+func (f *FakeGateway) Account(ctx context.Context) (*futures.Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("Account"); err != nil {
+		return nil, err
+	}
+	return f.account, nil
+}
+
+func (f *FakeGateway) Balances(ctx context.Context) ([]*futures.Balance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("Balances"); err != nil {
+		return nil, err
+	}
+	return f.balances, nil
+}
+
+func (f *FakeGateway) ChangeLeverage(ctx context.Context, symbol string, leverage int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("ChangeLeverage"); err != nil {
+		return err
+	}
+	f.Leverage[symbol] = leverage
+	return nil
+}
+
+func (f *FakeGateway) ChangeMarginType(ctx context.Context, symbol string, marginType futures.MarginType) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("ChangeMarginType"); err != nil {
+		return err
+	}
+	f.MarginType[symbol] = marginType
+	return nil
+}
+
+func (f *FakeGateway) ChangePositionMode(ctx context.Context, dualSide bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("ChangePositionMode"); err != nil {
+		return err
+	}
+	f.DualSide = dualSide
+	return nil
+}
+
+func (f *FakeGateway) StartUserStream(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("StartUserStream"); err != nil {
+		return "", err
+	}
+	f.ListenKeys++
+	return fmt.Sprintf("fake-listen-key-%d", f.ListenKeys), nil
+}
+
+func (f *FakeGateway) KeepaliveUserStream(ctx context.Context, listenKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.popFailure("KeepaliveUserStream")
+}
+
+func (f *FakeGateway) CloseUserStream(ctx context.Context, listenKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.popFailure("CloseUserStream")
+}
+
+func (f *FakeGateway) UserStreamURL(listenKey string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.StreamURL == "" {
+		return ""
+	}
+	return f.StreamURL + "/" + listenKey
+}
+
+func (f *FakeGateway) Klines(ctx context.Context, symbol, interval string, limit int) ([]*futures.Kline, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("Klines"); err != nil {
+		return nil, err
+	}
+
+	klines := f.klines[symbol+"_"+interval]
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+func (f *FakeGateway) Depth(ctx context.Context, symbol string, limit int) (*futures.DepthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("Depth"); err != nil {
+		return nil, err
+	}
+
+	depth, ok := f.depth[symbol]
+	if !ok {
+		return &futures.DepthResponse{}, nil
+	}
+	return depth, nil
+}
+
+func (f *FakeGateway) Prices(ctx context.Context, symbol string) ([]*futures.SymbolPrice, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("Prices"); err != nil {
+		return nil, err
+	}
+
+	price, ok := f.prices[symbol]
+	if !ok {
+		return []*futures.SymbolPrice{}, nil
+	}
+	return []*futures.SymbolPrice{{Symbol: symbol, Price: strconv.FormatFloat(price, 'f', -1, 64)}}, nil
+}
+
+func (f *FakeGateway) BookTickers(ctx context.Context, symbol string) ([]*futures.BookTicker, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("BookTickers"); err != nil {
+		return nil, err
+	}
+
+	ticker, ok := f.bookTickers[symbol]
+	if !ok {
+		return []*futures.BookTicker{}, nil
+	}
+	return []*futures.BookTicker{ticker}, nil
+}
+
+func (f *FakeGateway) ExchangeInfo(ctx context.Context) (*futures.ExchangeInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("ExchangeInfo"); err != nil {
+		return nil, err
+	}
+	return f.exchangeInfo, nil
+}
+
+func (f *FakeGateway) OpenInterest(ctx context.Context, symbol string) (*futures.OpenInterest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.popFailure("OpenInterest"); err != nil {
+		return nil, err
+	}
+
+	oi, ok := f.openInterest[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no open interest for %s", symbol)
+	}
+	return &futures.OpenInterest{Symbol: symbol, OpenInterest: strconv.FormatFloat(oi, 'f', -1, 64)}, nil
+}
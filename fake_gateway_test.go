@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+func TestFakeGatewayFailNextIsConsumedOnce(t *testing.T) {
+	gw := NewFakeGateway()
+	rejected := errors.New("code=-2019, msg=Margin is insufficient.")
+	gw.FailNext("PlaceOrder", rejected)
+
+	req := OrderRequest{Symbol: "BTCUSDT", Side: futures.SideTypeBuy, Type: futures.OrderTypeMarket, Quantity: "0.01"}
+	if _, err := gw.PlaceOrder(context.Background(), req); err != rejected {
+		t.Fatalf("first PlaceOrder: got %v, want scripted error", err)
+	}
+	if len(gw.Placed) != 0 {
+		t.Fatalf("failed order was recorded: %+v", gw.Placed)
+	}
+
+	if _, err := gw.PlaceOrder(context.Background(), req); err != nil {
+		t.Fatalf("second PlaceOrder: %v", err)
+	}
+	if len(gw.Placed) != 1 {
+		t.Fatalf("placed %d orders, want 1", len(gw.Placed))
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// FEED RECORDER (Raw Frames -> Hourly gzip JSONL)
+// ============================================================================
+// Opt-in (RECORD_FEEDS=true). Connectors call RecordFeed right after
+// ReadMessage; frames are queued and written by one goroutine so a slow disk
+// never stalls a feed (a full queue drops frames and counts them).
+//
+// Files: <dir>/feed-20060102T15-000.jsonl.gz (UTC hour, part number). A part
+// rolls over at MaxFileBytes; retention deletes the oldest files beyond
+// MaxAge or MaxTotalBytes. Names sort chronologically.
+
+// RecordedFrame is one line of a recording
+type RecordedFrame struct {
+	Received int64           `json:"ts"`             // Receive time (Unix ms)
+	Exchange string          `json:"ex"`             // "Binance", "Bybit", ...
+	Stream   string          `json:"stream"`         // Connection tag ("futures", "forceOrder", ...)
+	Data     json.RawMessage `json:"data,omitempty"` // Frame (when valid JSON)
+	Text     string          `json:"text,omitempty"` // Frame (otherwise)
+}
+
+// RecorderConfig controls rotation and retention
+type RecorderConfig struct {
+	Dir           string
+	MaxFileBytes  int64         // Compressed size before a new part (0 = hourly only)
+	MaxTotalBytes int64         // Oldest files deleted beyond this (0 = unlimited)
+	MaxAge        time.Duration // Files older than this are deleted (0 = keep)
+}
+
+// FeedRecorder tees raw frames to disk
+type FeedRecorder struct {
+	cfg    RecorderConfig
+	frames chan RecordedFrame
+	done   chan struct{}
+
+	dropped atomic.Int64
+	written atomic.Int64
+
+	// Writer goroutine only
+	hour    string
+	part    int
+	file    *os.File
+	counter *countingWriter
+	gz      *gzip.Writer
+}
+
+// activeRecorder is nil unless recording is enabled
+var activeRecorder atomic.Pointer[FeedRecorder]
+
+// RecordFeed tees a raw frame to the active recorder (no-op when disabled)
+func RecordFeed(exchange, stream string, message []byte) {
+	if rec := activeRecorder.Load(); rec != nil {
+		rec.Record(exchange, stream, message)
+	}
+}
+
+// RecordingEnabled reports whether a recorder is installed (skip costly encoding otherwise)
+func RecordingEnabled() bool {
+	return activeRecorder.Load() != nil
+}
+
+// NewFeedRecorder creates the recorder (call Start to begin writing)
+func NewFeedRecorder(cfg RecorderConfig) (*FeedRecorder, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FeedRecorder{
+		cfg:    cfg,
+		frames: make(chan RecordedFrame, 20000),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Start installs the recorder for all connectors and runs the writer
+func (r *FeedRecorder) Start() {
+	activeRecorder.Store(r)
+	go r.run()
+	log.Printf("🎙️ FEED RECORDER: Writing raw frames to %s (part %d MB | total %d MB | keep %v)",
+		r.cfg.Dir, r.cfg.MaxFileBytes>>20, r.cfg.MaxTotalBytes>>20, r.cfg.MaxAge)
+}
+
+// Stop detaches the recorder and flushes the current file
+func (r *FeedRecorder) Stop() {
+	activeRecorder.CompareAndSwap(r, nil)
+	close(r.frames)
+	<-r.done
+}
+
+// Record queues one frame (copied: callers reuse their buffers)
+func (r *FeedRecorder) Record(exchange, stream string, message []byte) {
+	frame := RecordedFrame{Received: time.Now().UnixMilli(), Exchange: exchange, Stream: stream}
+	if json.Valid(message) {
+		frame.Data = append(json.RawMessage(nil), message...)
+	} else {
+		frame.Text = string(message)
+	}
+
+	select {
+	case r.frames <- frame:
+	default:
+		if r.dropped.Add(1)%1000 == 1 {
+			log.Printf("⚠️ FEED RECORDER: Queue full. %d frames dropped so far.", r.dropped.Load())
+		}
+	}
+}
+
+func (r *FeedRecorder) run() {
+	defer close(r.done)
+	defer r.closeFile()
+
+	flush := time.NewTicker(1 * time.Second) // Bound data lost on a crash
+	defer flush.Stop()
+
+	for {
+		select {
+		case frame, ok := <-r.frames:
+			if !ok {
+				return
+			}
+			if err := r.write(frame); err != nil {
+				log.Printf("⚠️ FEED RECORDER: Write failed: %v", err)
+				r.closeFile()
+			}
+		case <-flush.C:
+			if r.gz != nil {
+				r.gz.Flush()
+			}
+		}
+	}
+}
+
+func (r *FeedRecorder) write(frame RecordedFrame) error {
+	hour := time.UnixMilli(frame.Received).UTC().Format("20060102T15")
+	if hour != r.hour {
+		r.closeFile()
+		r.hour, r.part = hour, 0
+	} else if r.cfg.MaxFileBytes > 0 && r.counter != nil && r.counter.n >= r.cfg.MaxFileBytes {
+		r.closeFile()
+		r.part++
+	}
+
+	if r.gz == nil {
+		if err := r.openFile(); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(frame)
+	if err != nil {
+		return nil // Skip unencodable frame
+	}
+	if _, err := r.gz.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	r.written.Add(1)
+	return nil
+}
+
+func (r *FeedRecorder) openFile() error {
+	// Never clobber a part from a previous run in the same hour
+	for {
+		name := filepath.Join(r.cfg.Dir, fmt.Sprintf("feed-%s-%03d.jsonl.gz", r.hour, r.part))
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if os.IsExist(err) {
+			r.part++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r.file = f
+		r.counter = &countingWriter{w: f}
+		r.gz = gzip.NewWriter(r.counter)
+		log.Printf("🎙️ FEED RECORDER: Recording to %s", filepath.Base(name))
+		r.enforceRetention()
+		return nil
+	}
+}
+
+func (r *FeedRecorder) closeFile() {
+	if r.gz != nil {
+		r.gz.Close()
+		r.gz = nil
+	}
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	r.counter = nil
+}
+
+// enforceRetention deletes the oldest closed files beyond MaxAge / MaxTotalBytes
+func (r *FeedRecorder) enforceRetention() {
+	files, err := RecordingFiles(r.cfg.Dir)
+	if err != nil {
+		return
+	}
+
+	current := ""
+	if r.file != nil {
+		current = r.file.Name()
+	}
+
+	var total int64
+	sizes := make(map[string]int64, len(files))
+	for _, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			sizes[path] = info.Size()
+			total += info.Size()
+		}
+	}
+
+	for _, path := range files { // Oldest first
+		if path == current {
+			break
+		}
+		expired := false
+		if info, err := os.Stat(path); err == nil && r.cfg.MaxAge > 0 {
+			expired = time.Since(info.ModTime()) > r.cfg.MaxAge
+		}
+		overBudget := r.cfg.MaxTotalBytes > 0 && total > r.cfg.MaxTotalBytes
+		if !expired && !overBudget {
+			break
+		}
+		if err := os.Remove(path); err == nil {
+			total -= sizes[path]
+			log.Printf("🗑️ FEED RECORDER: Removed %s (retention)", filepath.Base(path))
+		}
+	}
+}
+
+// RecordingFiles lists a recording directory oldest first
+func RecordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "feed-") && strings.HasSuffix(e.Name(), ".jsonl.gz") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// countingWriter tracks compressed bytes written to the current part
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// FEED SUPERVISOR (Reconnects, Watchdogs, Health)
+// ============================================================================
+// Connectors describe HOW to connect (FeedSpec.Connect) and WHAT to do with a
+// frame (FeedSpec.Handle); the supervisor owns the loop around them:
+//
+//   - Backoff: exponential (1s -> 60s) with jitter, reset once a connection
+//     delivers data, so a flapping venue never hammers the endpoint.
+//   - Watchdog: every read has a deadline (server pings extend it). A socket
+//     that goes silent for StaleAfter is flagged STALE (+ FEED_STALE alert);
+//     silent for ReadTimeout it is dropped and redialed.
+//   - Health: per-feed state and counters for /ping.
+//
+// States: CONNECTING (dialing) -> LIVE -> STALE (silent) -> DOWN (dial/read
+// failed, waiting out the backoff).
+
+type FeedState string
+
+const (
+	FeedConnecting FeedState = "CONNECTING"
+	FeedLive       FeedState = "LIVE"
+	FeedStale      FeedState = "STALE"
+	FeedDown       FeedState = "DOWN"
+)
+
+const (
+	feedMinBackoff  = 1 * time.Second
+	feedMaxBackoff  = 60 * time.Second
+	feedStaleAfter  = 30 * time.Second // Default silence before STALE
+	feedWatchPeriod = 1 * time.Second
+
+	liquidationStaleAfter = 5 * time.Minute // Liquidation streams go quiet in calm markets
+	quietStaleAfter       = 2 * time.Minute // Single-pair feeds (Kraken, Coinbase, Crypto.com)
+)
+
+// FeedSpec describes one supervised websocket feed
+type FeedSpec struct {
+	Name        string        // Unique display name ("Binance", "Bybit Liq", ...)
+	StaleAfter  time.Duration // Silence before STALE (default 30s)
+	ReadTimeout time.Duration // Silence before reconnecting (default 2x StaleAfter)
+
+	// Connect dials and subscribes. cleanup (optional) runs when the connection ends.
+	Connect func() (conn *websocket.Conn, cleanup func(), err error)
+	// Handle receives every frame (runs on the read goroutine)
+	Handle func(conn *websocket.Conn, message []byte)
+	// Stop (optional) ends Run and closes the connection
+	Stop <-chan struct{}
+}
+
+// FeedStatus is one feed's health snapshot
+type FeedStatus struct {
+	Name        string    `json:"name"`
+	State       FeedState `json:"state"`
+	Since       time.Time `json:"since"` // Entered the current state
+	LastMessage time.Time `json:"last_message"`
+	Connects    int64     `json:"connects"`
+	Disconnects int64     `json:"disconnects"`
+	Messages    int64     `json:"messages"`
+	Bytes       int64     `json:"bytes"`
+	StaleEvents int64     `json:"stale_events"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// feedHealth tracks one feed (hot-path counters are atomic)
+type feedHealth struct {
+	mu          sync.Mutex
+	name        string
+	staleAfter  time.Duration
+	state       FeedState
+	since       time.Time
+	connects    int64
+	disconnects int64
+	staleEvents int64
+	lastError   string
+
+	messages atomic.Int64
+	bytes    atomic.Int64
+	lastNano atomic.Int64 // Last frame (any type, pings included)
+	stale    atomic.Bool
+}
+
+// FeedSupervisor runs feeds and watches their health
+type FeedSupervisor struct {
+	mu     sync.RWMutex
+	feeds  map[string]*feedHealth
+	alerts chan<- Alert
+
+	watchOnce sync.Once
+}
+
+// Feeds is the process-wide supervisor every connector plugs into
+var Feeds = NewFeedSupervisor()
+
+// NewFeedSupervisor creates an empty supervisor
+func NewFeedSupervisor() *FeedSupervisor {
+	return &FeedSupervisor{feeds: make(map[string]*feedHealth)}
+}
+
+// SetAlerts routes FEED_STALE alerts to out (nil = log only)
+func (s *FeedSupervisor) SetAlerts(out chan<- Alert) {
+	s.mu.Lock()
+	s.alerts = out
+	s.mu.Unlock()
+}
+
+// Run supervises spec until spec.Stop is closed (forever if nil)
+func (s *FeedSupervisor) Run(spec FeedSpec) {
+	if spec.StaleAfter <= 0 {
+		spec.StaleAfter = feedStaleAfter
+	}
+	if spec.ReadTimeout <= 0 {
+		spec.ReadTimeout = 2 * spec.StaleAfter
+	}
+
+	h := s.register(spec)
+	defer s.unregister(spec.Name)
+	s.watchOnce.Do(func() { go s.watch() })
+
+	backoff := feedMinBackoff
+	for {
+		if stopped(spec.Stop) {
+			return
+		}
+
+		h.setState(FeedConnecting, "")
+		conn, cleanup, err := spec.Connect()
+		if err != nil {
+			h.setState(FeedDown, err.Error())
+			wait := jitter(backoff)
+			log.Printf("[%s] Connection error: %v. Retrying in %v...", spec.Name, err, wait.Round(time.Millisecond))
+			if sleepOrStop(wait, spec.Stop) {
+				return
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		h.connected()
+		received, err := s.read(h, conn, spec)
+		if cleanup != nil {
+			cleanup()
+		}
+		conn.Close()
+		if stopped(spec.Stop) {
+			return
+		}
+		h.disconnected(err)
+
+		// A connection that delivered data was healthy: start the backoff over
+		if received > 0 {
+			backoff = feedMinBackoff
+		}
+		wait := jitter(backoff)
+		log.Printf("[%s] Read error: %v. Reconnecting in %v...", spec.Name, err, wait.Round(time.Millisecond))
+		if sleepOrStop(wait, spec.Stop) {
+			return
+		}
+		if received == 0 {
+			backoff = nextBackoff(backoff)
+		}
+	}
+}
+
+// read pumps frames into Handle until the socket fails or the deadline passes
+func (s *FeedSupervisor) read(h *feedHealth, conn *websocket.Conn, spec FeedSpec) (int64, error) {
+	// Server pings prove the socket is alive (and extend the deadline)
+	conn.SetPingHandler(func(data string) error {
+		h.touch(0)
+		conn.SetReadDeadline(time.Now().Add(spec.ReadTimeout))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return nil
+		}
+		return err
+	})
+
+	// Stop closes the socket to unblock ReadMessage
+	done := make(chan struct{})
+	defer close(done)
+	if spec.Stop != nil {
+		go func() {
+			select {
+			case <-spec.Stop:
+				conn.Close()
+			case <-done:
+			}
+		}()
+	}
+
+	var received int64
+	for {
+		conn.SetReadDeadline(time.Now().Add(spec.ReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		received++
+		if h.touch(len(message)) {
+			log.Printf("✅ FEED RECOVERED: %s", h.name)
+		}
+		spec.Handle(conn, message)
+	}
+}
+
+// watch flags LIVE feeds that went silent
+func (s *FeedSupervisor) watch() {
+	ticker := time.NewTicker(feedWatchPeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.RLock()
+		feeds := make([]*feedHealth, 0, len(s.feeds))
+		for _, h := range s.feeds {
+			feeds = append(feeds, h)
+		}
+		alerts := s.alerts
+		s.mu.RUnlock()
+
+		for _, h := range feeds {
+			silent, ok := h.markStale()
+			if !ok {
+				continue
+			}
+			msg := fmt.Sprintf("📡 FEED STALE: %s silent for %v", h.name, silent.Round(time.Second))
+			log.Println("⚠️ " + msg)
+			if alerts != nil {
+				select {
+				case alerts <- Alert{Type: "FEED_STALE", Level: 4, Symbol: "SYSTEM", Message: msg}:
+				default: // Never block the watchdog on a full pipeline
+				}
+			}
+		}
+	}
+}
+
+// Status returns every feed's health, sorted by name
+func (s *FeedSupervisor) Status() []FeedStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]FeedStatus, 0, len(s.feeds))
+	for _, h := range s.feeds {
+		out = append(out, h.status())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *FeedSupervisor) register(spec FeedSpec) *feedHealth {
+	h := &feedHealth{name: spec.Name, staleAfter: spec.StaleAfter, state: FeedConnecting, since: time.Now()}
+	s.mu.Lock()
+	if _, dup := s.feeds[spec.Name]; dup {
+		log.Printf("⚠️ FEED SUPERVISOR: Duplicate feed name %q (status will be shared)", spec.Name)
+	}
+	s.feeds[spec.Name] = h
+	s.mu.Unlock()
+	return h
+}
+
+func (s *FeedSupervisor) unregister(name string) {
+	s.mu.Lock()
+	delete(s.feeds, name)
+	s.mu.Unlock()
+}
+
+// ============================================================================
+// FEED HEALTH
+// ============================================================================
+
+func (h *feedHealth) setState(state FeedState, lastError string) {
+	h.mu.Lock()
+	if h.state != state {
+		h.state = state
+		h.since = time.Now()
+	}
+	if lastError != "" {
+		h.lastError = lastError
+	}
+	h.mu.Unlock()
+	h.stale.Store(false)
+}
+
+func (h *feedHealth) connected() {
+	h.lastNano.Store(time.Now().UnixNano()) // Silence is measured from the connect
+	h.mu.Lock()
+	h.connects++
+	h.mu.Unlock()
+	h.setState(FeedLive, "")
+}
+
+func (h *feedHealth) disconnected(err error) {
+	h.mu.Lock()
+	h.disconnects++
+	h.mu.Unlock()
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	h.setState(FeedDown, msg)
+}
+
+// touch records a frame; returns true if it ended a STALE episode
+func (h *feedHealth) touch(n int) bool {
+	h.lastNano.Store(time.Now().UnixNano())
+	if n > 0 {
+		h.messages.Add(1)
+		h.bytes.Add(int64(n))
+	}
+	if !h.stale.Load() {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state != FeedStale {
+		return false
+	}
+	h.stale.Store(false)
+	h.state = FeedLive
+	h.since = time.Now()
+	return true
+}
+
+// markStale moves a silent LIVE feed to STALE (once per episode)
+func (h *feedHealth) markStale() (time.Duration, bool) {
+	silent := time.Since(time.Unix(0, h.lastNano.Load()))
+	if silent < h.staleAfter {
+		return 0, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state != FeedLive {
+		return 0, false
+	}
+	h.state = FeedStale
+	h.since = time.Now()
+	h.staleEvents++
+	h.stale.Store(true)
+	return silent, true
+}
+
+func (h *feedHealth) status() FeedStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := FeedStatus{
+		Name:        h.name,
+		State:       h.state,
+		Since:       h.since,
+		Connects:    h.connects,
+		Disconnects: h.disconnects,
+		Messages:    h.messages.Load(),
+		Bytes:       h.bytes.Load(),
+		StaleEvents: h.staleEvents,
+		LastError:   h.lastError,
+	}
+	if last := h.lastNano.Load(); last > 0 {
+		st.LastMessage = time.Unix(0, last)
+	}
+	return st
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// jitter spreads a backoff over [d/2, d) so reconnects don't synchronize
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > feedMaxBackoff {
+		d = feedMaxBackoff
+	}
+	return d
+}
+
+// heartbeat writes payload every interval until the returned stop is called
+func heartbeat(conn *websocket.Conn, every time.Duration, payload string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(payload)); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// chain combines cleanup funcs (nil entries are skipped)
+func chain(fns ...func()) func() {
+	return func() {
+		for _, fn := range fns {
+			if fn != nil {
+				fn()
+			}
+		}
+	}
+}
+
+func stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepOrStop waits d; returns true if stop closed first
+func sleepOrStop(d time.Duration, stop <-chan struct{}) bool {
+	select {
+	case <-time.After(d):
+		return false
+	case <-stop:
+		return true
+	}
+}
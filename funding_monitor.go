@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// FUNDING MONITOR (Mark Price + Funding Rate per Symbol)
+// ============================================================================
+// One socket (!markPrice@arr@1s) carries mark, index, the funding rate that
+// settles next and the settlement time for every perpetual. On top of that:
+//
+//   - Predicted rate: Binance's formula on our own premium average over the
+//     current period: P + clamp(0.01% - P, ±0.05%).
+//   - Entry gate: no new position in the last GateWindow before a payment
+//     the position would MAKE, once |rate| >= GateRate.
+//   - FUNDING alert: |rate| >= ExtremeRate (once per symbol per period).
+//   - Mark listeners: the paper exchange triggers stops on mark, like the
+//     real WorkingTypeMarkPrice orders.
+
+const (
+	fundingMarkURL      = "wss://fstream.binance.com/ws/!markPrice@arr@1s"
+	fundingInterestRate = 0.0001 // Binance interest component per 8h period
+	fundingPremiumClamp = 0.0005 // ±0.05% clamp around the interest rate
+	fundingMarkMaxAge   = 5 * time.Second
+)
+
+// FundingInfo is the funding / mark state attached to signals
+type FundingInfo struct {
+	Rate          float64 `json:"rate"`           // Settles at NextFunding (0.0001 = 0.01%)
+	PredictedRate float64 `json:"predicted_rate"` // From the premium average so far this period
+	NextFunding   int64   `json:"next_funding"`   // Unix ms
+	MarkPrice     float64 `json:"mark_price"`
+	IndexPrice    float64 `json:"index_price"`
+	UpdatedAt     int64   `json:"updated_at"` // Event time (Unix ms)
+}
+
+// FundingConfig holds the gate and alert thresholds
+type FundingConfig struct {
+	GateRate    float64       // |rate| that blocks adverse entries (0.0005 = 0.05%)
+	GateWindow  time.Duration // Blocked this long before the payment
+	ExtremeRate float64       // |rate| that raises a FUNDING alert
+}
+
+type fundingState struct {
+	info        FundingInfo
+	premiumSum  float64 // (mark - index) / index, summed over the current period
+	premiumN    int
+	alertedNext int64 // NextFunding of the last FUNDING alert (one per period)
+}
+
+// FundingMonitor tracks mark price and funding for the monitored universe
+type FundingMonitor struct {
+	mu        sync.RWMutex
+	states    map[string]*fundingState // "BTCUSDT" -> State
+	registry  *SymbolRegistry
+	alerts    chan<- Alert
+	cfg       FundingConfig
+	clock     Clock
+	listeners []func(symbol string, mark float64)
+}
+
+type binanceMarkPriceMsg struct {
+	Event       string `json:"e"`
+	Time        int64  `json:"E"`
+	Symbol      string `json:"s"`
+	MarkPrice   string `json:"p"`
+	IndexPrice  string `json:"i"`
+	FundingRate string `json:"r"`
+	NextFunding int64  `json:"T"`
+}
+
+// NewFundingMonitor creates a monitor; alerts may be nil (gate only)
+func NewFundingMonitor(registry *SymbolRegistry, cfg FundingConfig, alerts chan<- Alert) *FundingMonitor {
+	return &FundingMonitor{
+		states:   make(map[string]*fundingState),
+		registry: registry,
+		alerts:   alerts,
+		cfg:      cfg,
+		clock:    RealClock,
+	}
+}
+
+// SetClock swaps the time source (event time in replay)
+func (fm *FundingMonitor) SetClock(c Clock) {
+	fm.mu.Lock()
+	fm.clock = clockOrReal(c)
+	fm.mu.Unlock()
+}
+
+// OnMark registers a callback for every mark price update (must not block)
+func (fm *FundingMonitor) OnMark(fn func(symbol string, mark float64)) {
+	fm.mu.Lock()
+	fm.listeners = append(fm.listeners, fn)
+	fm.mu.Unlock()
+}
+
+// Start streams mark price / funding for all perpetuals (blocks)
+func (fm *FundingMonitor) Start() {
+	log.Printf("💸 FUNDING MONITOR: Gate %.3f%% within %v, alert at %.3f%%", fm.cfg.GateRate*100, fm.cfg.GateWindow, fm.cfg.ExtremeRate*100)
+
+	Feeds.Run(FeedSpec{
+		Name: "Binance Mark",
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(fundingMarkURL, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			log.Println("[Binance Mark] Connected")
+			return conn, nil, nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Binance", "markPrice", message)
+			fm.handleFrame(message)
+		},
+	})
+}
+
+// handleFrame parses one !markPrice@arr frame (live or recorded)
+func (fm *FundingMonitor) handleFrame(message []byte) {
+	var updates []binanceMarkPriceMsg
+	if err := json.Unmarshal(message, &updates); err != nil {
+		return
+	}
+
+	for _, u := range updates {
+		if u.Event != "markPriceUpdate" || (fm.registry != nil && !fm.registry.Contains(u.Symbol)) {
+			continue
+		}
+		mark, _ := strconv.ParseFloat(u.MarkPrice, 64)
+		index, _ := strconv.ParseFloat(u.IndexPrice, 64)
+		rate, _ := strconv.ParseFloat(u.FundingRate, 64)
+		if mark <= 0 {
+			continue
+		}
+		symbol := NormalizeSymbol(u.Symbol)
+
+		fm.mu.Lock()
+		st, ok := fm.states[symbol]
+		if !ok {
+			st = &fundingState{}
+			fm.states[symbol] = st
+		}
+		// New period: the premium average starts over
+		if st.info.NextFunding != u.NextFunding {
+			st.premiumSum, st.premiumN = 0, 0
+		}
+		if index > 0 {
+			st.premiumSum += (mark - index) / index
+			st.premiumN++
+		}
+		st.info = FundingInfo{
+			Rate:          rate,
+			PredictedRate: predictFunding(st.premiumSum, st.premiumN),
+			NextFunding:   u.NextFunding,
+			MarkPrice:     mark,
+			IndexPrice:    index,
+			UpdatedAt:     u.Time,
+		}
+		info := st.info
+		alert := fm.cfg.ExtremeRate > 0 && math.Abs(rate) >= fm.cfg.ExtremeRate && st.alertedNext != u.NextFunding
+		if alert {
+			st.alertedNext = u.NextFunding
+		}
+		listeners := fm.listeners
+		fm.mu.Unlock()
+
+		for _, fn := range listeners {
+			fn(symbol, mark)
+		}
+		if alert {
+			fm.sendAlert(symbol, info)
+		}
+	}
+}
+
+// sendAlert broadcasts a FUNDING alert (who pays, how much, when)
+func (fm *FundingMonitor) sendAlert(symbol string, info FundingInfo) {
+	if fm.alerts == nil {
+		return
+	}
+	shortSym := Instruments.Canonical(symbol).Key() // "BTC", "BTCUSDC"
+	payer := "LONGS pay SHORTS"
+	if info.Rate < 0 {
+		payer = "SHORTS pay LONGS"
+	}
+	msg := fmt.Sprintf("💸 FUNDING EXTREME: %s %+.4f%% (%s, predicted %+.4f%%) in %v",
+		shortSym, info.Rate*100, payer, info.PredictedRate*100, fm.untilFunding(info).Round(time.Minute))
+	log.Println(msg)
+
+	alert := Alert{
+		Type:           "FUNDING",
+		Level:          4,
+		Symbol:         shortSym,
+		Message:        msg,
+		FormattedValue: fmt.Sprintf("%+.4f%%", info.Rate*100),
+		Data: Trade{
+			Symbol:    shortSym,
+			Price:     info.MarkPrice,
+			Exchange:  "Binance",
+			Timestamp: info.UpdatedAt,
+		},
+	}
+	select {
+	case fm.alerts <- alert:
+	default:
+		log.Printf("⚠️ FUNDING: Alert channel full, dropped %s", shortSym)
+	}
+}
+
+// Get returns the funding state for a symbol ("BTC" or "BTCUSDT")
+func (fm *FundingMonitor) Get(symbol string) (FundingInfo, bool) {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	st, ok := fm.states[NormalizeSymbol(symbol)]
+	if !ok {
+		return FundingInfo{}, false
+	}
+	return st.info, true
+}
+
+// Info returns a copy for Signal.Funding (nil if unknown)
+func (fm *FundingMonitor) Info(symbol string) *FundingInfo {
+	if fm == nil {
+		return nil
+	}
+	info, ok := fm.Get(symbol)
+	if !ok {
+		return nil
+	}
+	return &info
+}
+
+// Mark returns the mark price if it is fresh
+func (fm *FundingMonitor) Mark(symbol string) (float64, bool) {
+	info, ok := fm.Get(symbol)
+	if !ok {
+		return 0, false
+	}
+	fm.mu.RLock()
+	now := fm.clock.Now()
+	fm.mu.RUnlock()
+	if now.Sub(time.UnixMilli(info.UpdatedAt)) > fundingMarkMaxAge {
+		return 0, false
+	}
+	return info.MarkPrice, true
+}
+
+// CheckEntry blocks a new position that would pay a large funding payment
+// within GateWindow ("LONG"/"BUY" pays a positive rate, "SHORT"/"SELL" a negative one)
+func (fm *FundingMonitor) CheckEntry(symbol, side string) error {
+	if fm == nil || fm.cfg.GateRate <= 0 {
+		return nil
+	}
+	info, ok := fm.Get(symbol)
+	if !ok || info.NextFunding == 0 {
+		return nil
+	}
+
+	until := fm.untilFunding(info)
+	if until < 0 || until > fm.cfg.GateWindow {
+		return nil
+	}
+
+	isLong := side == "LONG" || side == "BUY"
+	adverse := (isLong && info.Rate > 0) || (!isLong && info.Rate < 0)
+	if !adverse || math.Abs(info.Rate) < fm.cfg.GateRate {
+		return nil
+	}
+	return fmt.Errorf("%s would pay %.4f%% funding in %v (gate %.4f%%)",
+		side, math.Abs(info.Rate)*100, until.Round(time.Second), fm.cfg.GateRate*100)
+}
+
+func (fm *FundingMonitor) untilFunding(info FundingInfo) time.Duration {
+	fm.mu.RLock()
+	now := fm.clock.Now()
+	fm.mu.RUnlock()
+	return time.UnixMilli(info.NextFunding).Sub(now)
+}
+
+// predictFunding applies Binance's formula to the average premium index
+func predictFunding(premiumSum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	p := premiumSum / float64(n)
+	adj := fundingInterestRate - p
+	if adj > fundingPremiumClamp {
+		adj = fundingPremiumClamp
+	} else if adj < -fundingPremiumClamp {
+		adj = -fundingPremiumClamp
+	}
+	return p + adj
+}
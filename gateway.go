@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// ORDER GATEWAY (Venue Abstraction)
+// ============================================================================
+// ExecutionService, PredatorEngine, TrendAnalyzer and CoPilotService talk to
+// the venue only through Gateway. Responses reuse the go-binance futures types
+// so existing parsing (string prices, OrderID, Status) is unchanged.
+
+// OrderRequest describes a new order (empty fields are not sent)
+type OrderRequest struct {
+	Symbol        string
+	Side          futures.SideType
+	Type          futures.OrderType
+	TimeInForce   futures.TimeInForceType
+	Quantity      string
+	Price         string
+	StopPrice     string
+	ReduceOnly    bool
+	ClosePosition bool
+	WorkingType   futures.WorkingType
+	PriceProtect  bool
+	ClientOrderID string
+}
+
+// Gateway is the order + market data API the trading engines depend on
+type Gateway interface {
+	// Orders
+	PlaceOrder(ctx context.Context, req OrderRequest) (*futures.CreateOrderResponse, error)
+	CancelOrder(ctx context.Context, symbol string, orderID int64) (*futures.CancelOrderResponse, error)
+	CancelAllOrders(ctx context.Context, symbol string) error
+	GetOrder(ctx context.Context, symbol string, orderID int64) (*futures.Order, error)
+	OpenOrders(ctx context.Context, symbol string) ([]*futures.Order, error)
+
+	// Account
+	Positions(ctx context.Context, symbol string) ([]*futures.PositionRisk, error) // symbol "" = all
+	Account(ctx context.Context) (*futures.Account, error)
+	Balances(ctx context.Context) ([]*futures.Balance, error)
+	ChangeLeverage(ctx context.Context, symbol string, leverage int) error
+	ChangeMarginType(ctx context.Context, symbol string, marginType futures.MarginType) error
+	ChangePositionMode(ctx context.Context, dualSide bool) error
+
+	// User Data Stream (listenKey)
+	StartUserStream(ctx context.Context) (string, error)
+	KeepaliveUserStream(ctx context.Context, listenKey string) error
+	CloseUserStream(ctx context.Context, listenKey string) error
+	UserStreamURL(listenKey string) string
+
+	// Market Data
+	Klines(ctx context.Context, symbol, interval string, limit int) ([]*futures.Kline, error)
+	Depth(ctx context.Context, symbol string, limit int) (*futures.DepthResponse, error)
+	Prices(ctx context.Context, symbol string) ([]*futures.SymbolPrice, error)
+	BookTickers(ctx context.Context, symbol string) ([]*futures.BookTicker, error)
+	ExchangeInfo(ctx context.Context) (*futures.ExchangeInfo, error)
+	OpenInterest(ctx context.Context, symbol string) (*futures.OpenInterest, error)
+}
+
+// ============================================================================
+// BINANCE GATEWAY
+// ============================================================================
+
+// BinanceGateway implements Gateway with the go-binance futures client
+type BinanceGateway struct {
+	client *futures.Client
+	wsURL  string // User data stream base ("wss://fstream.binance.com/ws")
+}
+
+// NewBinanceGateway creates a Binance USDⓈ-M gateway (testnet flips the package-wide URL)
+func NewBinanceGateway(apiKey, secretKey string, testnet bool) *BinanceGateway {
+	if testnet {
+		futures.UseTestnet = true
+		log.Println("⚠️ USING BINANCE FUTURES TESTNET URL")
+	}
+	wsURL := futures.BaseWsMainUrl
+	if futures.UseTestnet {
+		wsURL = futures.BaseWsTestnetUrl
+	}
+	return &BinanceGateway{client: binance.NewFuturesClient(apiKey, secretKey), wsURL: wsURL}
+}
+
+// NewMarketDataGateway creates an unauthenticated mainnet gateway for public market data.
+// Streams come from fstream.binance.com, so REST reads must too, whatever the testnet flag says.
+func NewMarketDataGateway() *BinanceGateway {
+	client := binance.NewFuturesClient("", "").SetApiEndpoint(futures.BaseApiMainUrl)
+	return &BinanceGateway{client: client, wsURL: futures.BaseWsMainUrl}
+}
+
+// SetBaseURL points the gateway at another Binance-compatible endpoint (e.g. PaperExchange)
+func (g *BinanceGateway) SetBaseURL(url string) {
+	g.client.SetApiEndpoint(url)
+}
+
+// SetStreamURL overrides the user data stream base URL
+func (g *BinanceGateway) SetStreamURL(url string) {
+	g.wsURL = url
+}
+
+func (g *BinanceGateway) PlaceOrder(ctx context.Context, req OrderRequest) (*futures.CreateOrderResponse, error) {
+	svc := g.client.NewCreateOrderService().
+		Symbol(req.Symbol).
+		Side(req.Side).
+		Type(req.Type)
+
+	if req.TimeInForce != "" {
+		svc.TimeInForce(req.TimeInForce)
+	}
+	if req.Quantity != "" {
+		svc.Quantity(req.Quantity)
+	}
+	if req.Price != "" {
+		svc.Price(req.Price)
+	}
+	if req.StopPrice != "" {
+		svc.StopPrice(req.StopPrice)
+	}
+	if req.ReduceOnly {
+		svc.ReduceOnly(true)
+	}
+	if req.ClosePosition {
+		svc.ClosePosition(true)
+	}
+	if req.WorkingType != "" {
+		svc.WorkingType(req.WorkingType)
+	}
+	if req.PriceProtect {
+		svc.PriceProtect(true)
+	}
+	if req.ClientOrderID != "" {
+		svc.NewClientOrderID(req.ClientOrderID)
+	}
+	return svc.Do(ctx)
+}
+
+func (g *BinanceGateway) CancelOrder(ctx context.Context, symbol string, orderID int64) (*futures.CancelOrderResponse, error) {
+	return g.client.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+}
+
+func (g *BinanceGateway) CancelAllOrders(ctx context.Context, symbol string) error {
+	return g.client.NewCancelAllOpenOrdersService().Symbol(symbol).Do(ctx)
+}
+
+func (g *BinanceGateway) GetOrder(ctx context.Context, symbol string, orderID int64) (*futures.Order, error) {
+	return g.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+}
+
+func (g *BinanceGateway) OpenOrders(ctx context.Context, symbol string) ([]*futures.Order, error) {
+	return g.client.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+}
+
+func (g *BinanceGateway) Positions(ctx context.Context, symbol string) ([]*futures.PositionRisk, error) {
+	svc := g.client.NewGetPositionRiskService()
+	if symbol != "" {
+		svc.Symbol(symbol)
+	}
+	return svc.Do(ctx)
+}
+
+func (g *BinanceGateway) Account(ctx context.Context) (*futures.Account, error) {
+	return g.client.NewGetAccountService().Do(ctx)
+}
+
+func (g *BinanceGateway) Balances(ctx context.Context) ([]*futures.Balance, error) {
+	return g.client.NewGetBalanceService().Do(ctx)
+}
+
+func (g *BinanceGateway) ChangeLeverage(ctx context.Context, symbol string, leverage int) error {
+	_, err := g.client.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	return err
+}
+
+func (g *BinanceGateway) ChangeMarginType(ctx context.Context, symbol string, marginType futures.MarginType) error {
+	return g.client.NewChangeMarginTypeService().Symbol(symbol).MarginType(marginType).Do(ctx)
+}
+
+func (g *BinanceGateway) ChangePositionMode(ctx context.Context, dualSide bool) error {
+	return g.client.NewChangePositionModeService().DualSide(dualSide).Do(ctx)
+}
+
+func (g *BinanceGateway) StartUserStream(ctx context.Context) (string, error) {
+	return g.client.NewStartUserStreamService().Do(ctx)
+}
+
+func (g *BinanceGateway) KeepaliveUserStream(ctx context.Context, listenKey string) error {
+	return g.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+}
+
+func (g *BinanceGateway) CloseUserStream(ctx context.Context, listenKey string) error {
+	return g.client.NewCloseUserStreamService().ListenKey(listenKey).Do(ctx)
+}
+
+func (g *BinanceGateway) UserStreamURL(listenKey string) string {
+	return g.wsURL + "/" + listenKey
+}
+
+func (g *BinanceGateway) Klines(ctx context.Context, symbol, interval string, limit int) ([]*futures.Kline, error) {
+	return g.client.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx)
+}
+
+func (g *BinanceGateway) Depth(ctx context.Context, symbol string, limit int) (*futures.DepthResponse, error) {
+	return g.client.NewDepthService().Symbol(symbol).Limit(limit).Do(ctx)
+}
+
+func (g *BinanceGateway) Prices(ctx context.Context, symbol string) ([]*futures.SymbolPrice, error) {
+	return g.client.NewListPricesService().Symbol(symbol).Do(ctx)
+}
+
+func (g *BinanceGateway) BookTickers(ctx context.Context, symbol string) ([]*futures.BookTicker, error) {
+	return g.client.NewListBookTickersService().Symbol(symbol).Do(ctx)
+}
+
+func (g *BinanceGateway) ExchangeInfo(ctx context.Context) (*futures.ExchangeInfo, error) {
+	return g.client.NewExchangeInfoService().Do(ctx)
+}
+
+func (g *BinanceGateway) OpenInterest(ctx context.Context, symbol string) (*futures.OpenInterest, error) {
+	return g.client.NewGetOpenInterestService().Symbol(symbol).Do(ctx)
+}
+
+// isOpenStatus reports whether an order can still fill
+func isOpenStatus(status futures.OrderStatusType) bool {
+	return status == futures.OrderStatusTypeNew || status == futures.OrderStatusTypePartiallyFilled
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// INSTRUMENT REGISTRY (Venue IDs -> Canonical Instruments)
+// ============================================================================
+// Canonical IDs are Binance USDⓈ-M style ("BTCUSDT", "1000PEPEUSDT",
+// "BTCUSDC"): order books, risk and execution all speak them. Every venue
+// print is resolved to one and converted into its units:
+//
+//   base qty  = contracts x ContractSize x venue Multiplier
+//   notional  = contracts x ContractSize x price   (inverse: contracts x ContractSize)
+//   canon qty = base qty / canonical Multiplier     (1000PEPE: lots of 1000 PEPE)
+//
+// Listings with a contract size (OKX ctVal, KuCoin lots) are registered from
+// the venues' instrument endpoints; everything else is parsed from the
+// venue's naming convention. USD-quoted venues (Coinbase, Kraken, Crypto.com)
+// resolve onto the USDT instrument so their prints join the same book.
+//
+// Trade.Symbol is the instrument Key: the canonical ID without "USDT"
+// ("BTC", "1000PEPE"), the full ID for any other quote ("BTCUSDC").
+
+// Instrument is one canonical contract
+type Instrument struct {
+	Symbol     string  // Canonical ID ("1000PEPEUSDT")
+	Base       string  // Underlying asset ("PEPE")
+	Quote      string  // "USDT", "USDC", ...
+	Multiplier float64 // Base units per quoted unit (1000PEPE: 1000)
+}
+
+// Key is the short form used in Trade.Symbol and alerts
+func (i Instrument) Key() string {
+	if i.Quote == "USDT" {
+		return strings.TrimSuffix(i.Symbol, "USDT")
+	}
+	return i.Symbol
+}
+
+// VenueInstrument is one venue's listing of a canonical instrument
+type VenueInstrument struct {
+	Venue        string
+	Native       string  // Venue ID ("PEPE-USDT-SWAP", "XBTUSDTM", "PI_XBTUSD")
+	Base         string  // Underlying asset (XBT already mapped to BTC)
+	Quote        string  // Quote / settle currency
+	Multiplier   float64 // Base units per venue price unit (Bybit "SHIB1000USDT": 1000)
+	ContractSize float64 // Venue units per contract (OKX ctVal, KuCoin lot); 1 = size is in units
+	Inverse      bool    // Contracts are worth ContractSize quote (Kraken PI_: 1 USD)
+
+	Instrument Instrument // Canonical target
+}
+
+// Normalize converts a venue print (price, size in contracts) to canonical price, size and notional
+func (v VenueInstrument) Normalize(price, size float64) (float64, float64, float64) {
+	var baseQty, notional float64
+	if v.Inverse {
+		notional = size * v.ContractSize
+		if price > 0 {
+			baseQty = notional / price
+		}
+	} else {
+		units := size * v.ContractSize
+		baseQty = units * v.Multiplier
+		notional = units * price
+	}
+
+	canonSize := baseQty / v.Instrument.Multiplier
+	canonPrice := price / v.Multiplier * v.Instrument.Multiplier
+	if v.Inverse {
+		canonPrice = price * v.Instrument.Multiplier
+	}
+	return canonPrice, canonSize, notional
+}
+
+// InstrumentRegistry maps venue IDs and loose symbols to canonical instruments
+type InstrumentRegistry struct {
+	mu        sync.RWMutex
+	canonical map[string]Instrument      // Full canonical ID -> Instrument (parse cache)
+	byAsset   map[string]string          // "PEPE/USDT" -> "1000PEPEUSDT"
+	listings  map[string]VenueInstrument // "OKX|BTC-USDT-SWAP" -> Listing (registered)
+	ticks     map[string]float64         // Canonical ID -> PRICE_FILTER tickSize (exchangeInfo)
+}
+
+// Instruments is the process-wide registry
+var Instruments = NewInstrumentRegistry()
+
+// NewInstrumentRegistry creates an empty registry
+func NewInstrumentRegistry() *InstrumentRegistry {
+	return &InstrumentRegistry{
+		canonical: make(map[string]Instrument),
+		byAsset:   make(map[string]string),
+		listings:  make(map[string]VenueInstrument),
+		ticks:     make(map[string]float64),
+	}
+}
+
+// Known quote currencies (longest first so "FDUSD" wins over "USD")
+var instrumentQuotes = []string{"FDUSD", "BUSD", "USDT", "USDC", "USD"}
+
+// Lot-size prefixes Binance puts in front of cheap assets ("1000PEPE", "1MBABYDOGE")
+var instrumentMultipliers = []struct {
+	Prefix string
+	Value  float64
+}{
+	{"1000000", 1e6}, {"100000", 1e5}, {"10000", 1e4}, {"1000", 1e3}, {"1M", 1e6},
+}
+
+// venueAssets maps venue-specific asset codes to the canonical one
+var venueAssets = map[string]string{"XBT": "BTC"}
+
+// AddCanonical pins symbol as THE instrument for its base/quote (the monitored universe)
+func (r *InstrumentRegistry) AddCanonical(symbol string) Instrument {
+	inst := r.Canonical(symbol)
+	r.mu.Lock()
+	r.canonical[inst.Symbol] = inst
+	r.byAsset[inst.Base+"/"+inst.Quote] = inst.Symbol
+	r.mu.Unlock()
+	return inst
+}
+
+// Canonical resolves a loose symbol ("btc", "BTCUSDT", "1000PEPE", "PEPE", "ETHUSDC").
+// Full IDs are taken as-is; a bare asset maps to its pinned USDT instrument.
+func (r *InstrumentRegistry) Canonical(symbol string) Instrument {
+	sym := strings.ToUpper(strings.TrimSpace(symbol))
+
+	r.mu.RLock()
+	inst, ok := r.canonical[sym]
+	r.mu.RUnlock()
+	if ok {
+		return inst
+	}
+
+	mult, base, quote := parseInstrumentSymbol(sym)
+	if quote == "" {
+		// Bare asset: USDT-margined (not cached: a later AddCanonical may re-pin it)
+		if mult == 1 {
+			if pinned, ok := r.pinned(base, "USDT"); ok {
+				return pinned
+			}
+		}
+		return newInstrument(base, "USDT", mult)
+	}
+
+	inst = newInstrument(base, quote, mult)
+	inst.Symbol = sym // Keep the caller's spelling of the multiplier ("1MBABYDOGE")
+	r.mu.Lock()
+	r.canonical[sym] = inst
+	if _, exists := r.byAsset[base+"/"+quote]; !exists {
+		r.byAsset[base+"/"+quote] = sym
+	}
+	r.mu.Unlock()
+	return inst
+}
+
+// Register installs a venue listing (instrument endpoints: OKX ctVal, KuCoin lots)
+func (r *InstrumentRegistry) Register(v VenueInstrument) {
+	v.Base = venueAsset(v.Base)
+	if v.Multiplier <= 0 {
+		v.Multiplier = 1
+	}
+	if v.ContractSize <= 0 {
+		v.ContractSize = 1
+	}
+	r.mu.Lock()
+	r.listings[v.Venue+"|"+v.Native] = v
+	r.mu.Unlock()
+}
+
+// Resolve maps a venue's native ID to its listing and canonical instrument
+func (r *InstrumentRegistry) Resolve(venue, native string) (VenueInstrument, bool) {
+	r.mu.RLock()
+	v, ok := r.listings[venue+"|"+native]
+	r.mu.RUnlock()
+	if !ok {
+		if v, ok = parseVenueInstrument(venue, native); !ok {
+			return VenueInstrument{}, false
+		}
+	}
+
+	// Binance IS the canonical naming; elsewhere go through the pinned instrument
+	if venue == "Binance" {
+		v.Instrument = r.Canonical(native)
+		return v, true
+	}
+	v.Instrument = r.canonicalFor(v.Base, v.Quote, v.Multiplier)
+	return v, true
+}
+
+// Native returns the venue's ID for a canonical symbol (subscriptions)
+func (r *InstrumentRegistry) Native(venue, symbol string) (string, bool) {
+	inst := r.Canonical(symbol)
+
+	r.mu.RLock()
+	var matches []string
+	for _, v := range r.listings {
+		if v.Venue == venue && r.canonicalForLocked(v.Base, v.Quote, v.Multiplier).Symbol == inst.Symbol {
+			matches = append(matches, v.Native)
+		}
+	}
+	r.mu.RUnlock()
+	if len(matches) > 0 {
+		sort.Strings(matches) // Deterministic if a venue lists duplicates
+		return matches[0], true
+	}
+
+	switch venue {
+	case "Binance", "Bybit":
+		return inst.Symbol, true
+	case "OKX":
+		return inst.Base + "-" + inst.Quote + "-SWAP", true
+	}
+	return "", false
+}
+
+// Listings counts a venue's registered listings
+func (r *InstrumentRegistry) Listings(venue string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, v := range r.listings {
+		if v.Venue == venue {
+			n++
+		}
+	}
+	return n
+}
+
+// SetTickSize records a canonical instrument's price tick (from exchangeInfo)
+func (r *InstrumentRegistry) SetTickSize(symbol string, tick float64) {
+	if tick <= 0 {
+		return
+	}
+	r.mu.Lock()
+	r.ticks[strings.ToUpper(symbol)] = tick // exchangeInfo IDs are canonical: no parse / pin side effects
+	r.mu.Unlock()
+}
+
+// TickSize returns the instrument's price tick (0 if exchangeInfo never loaded)
+func (r *InstrumentRegistry) TickSize(symbol string) float64 {
+	inst := r.Canonical(symbol)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ticks[inst.Symbol]
+}
+
+func (r *InstrumentRegistry) pinned(base, quote string) (Instrument, bool) {
+	r.mu.RLock()
+	sym, ok := r.byAsset[base+"/"+quote]
+	inst := r.canonical[sym]
+	r.mu.RUnlock()
+	return inst, ok
+}
+
+// canonicalFor picks the canonical instrument for a venue's base/quote
+func (r *InstrumentRegistry) canonicalFor(base, quote string, mult float64) Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.canonicalForLocked(base, quote, mult)
+}
+
+func (r *InstrumentRegistry) canonicalForLocked(base, quote string, mult float64) Instrument {
+	if quote == "USD" {
+		quote = "USDT" // USD venues proxy the USDT book
+	}
+	if sym, ok := r.byAsset[base+"/"+quote]; ok {
+		return r.canonical[sym]
+	}
+	return newInstrument(base, quote, mult)
+}
+
+// ============================================================================
+// PARSING
+// ============================================================================
+
+func newInstrument(base, quote string, mult float64) Instrument {
+	prefix := ""
+	for _, m := range instrumentMultipliers {
+		if m.Value == mult && m.Prefix != "1M" {
+			prefix = m.Prefix
+			break
+		}
+	}
+	if mult <= 0 {
+		mult = 1
+	}
+	return Instrument{Symbol: prefix + base + quote, Base: base, Quote: quote, Multiplier: mult}
+}
+
+// parseInstrumentSymbol splits "1000PEPEUSDT" into (1000, "PEPE", "USDT").
+// quote is "" for a bare asset ("BTC").
+func parseInstrumentSymbol(sym string) (mult float64, base, quote string) {
+	for _, q := range instrumentQuotes {
+		if sym == q {
+			return 1, "", q
+		}
+		if strings.HasSuffix(sym, q) {
+			quote = q
+			sym = strings.TrimSuffix(sym, q)
+			break
+		}
+	}
+
+	mult = 1
+	for _, m := range instrumentMultipliers {
+		rest := strings.TrimPrefix(sym, m.Prefix)
+		if rest != sym && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			mult, sym = m.Value, rest
+			break
+		}
+		// Bybit puts it after the asset ("SHIB1000")
+		rest = strings.TrimSuffix(sym, m.Prefix)
+		if m.Prefix != "1M" && rest != sym && rest != "" && rest[len(rest)-1] >= 'A' && rest[len(rest)-1] <= 'Z' {
+			mult, sym = m.Value, rest
+			break
+		}
+	}
+	return mult, venueAsset(sym), quote
+}
+
+// parseVenueInstrument applies a venue's naming convention (no contract size known)
+func parseVenueInstrument(venue, native string) (VenueInstrument, bool) {
+	v := VenueInstrument{Venue: venue, Native: native, Multiplier: 1, ContractSize: 1}
+	id := strings.ToUpper(native)
+
+	switch venue {
+	case "Binance", "Bybit":
+		// "BTCUSDT", "1000PEPEUSDT", "SHIB1000USDT"
+		v.Multiplier, v.Base, v.Quote = parseInstrumentSymbol(id)
+
+	case "Coinbase":
+		// "BTC-USD" (spot, size in base)
+		parts := strings.Split(id, "-")
+		if len(parts) < 2 {
+			return v, false
+		}
+		v.Base, v.Quote = venueAsset(parts[0]), parts[1]
+
+	case "Kraken":
+		// "PI_XBTUSD" (inverse, 1 USD per contract) / "PF_XBTUSD" (linear, size in base)
+		v.Inverse = strings.HasPrefix(id, "PI_")
+		if i := strings.IndexByte(id, '_'); i >= 0 {
+			id = id[i+1:]
+		}
+		v.Multiplier, v.Base, v.Quote = parseInstrumentSymbol(id)
+
+	case "Crypto.com":
+		// "BTCUSD-PERP" / "BTC_USD_PERP" (size in base)
+		id = strings.TrimSuffix(strings.TrimSuffix(id, "-PERP"), "_PERP")
+		v.Multiplier, v.Base, v.Quote = parseInstrumentSymbol(strings.ReplaceAll(id, "_", ""))
+
+	default:
+		// OKX / KuCoin sizes are contracts: without the registered size the notional would be wrong
+		return v, false
+	}
+
+	if v.Base == "" || v.Quote == "" {
+		return v, false
+	}
+	return v, true
+}
+
+func venueAsset(asset string) string {
+	asset = strings.ToUpper(asset)
+	if mapped, ok := venueAssets[asset]; ok {
+		return mapped
+	}
+	return asset
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// KUCOIN FUTURES (bullet-public Token + /contractMarket/execution)
+// ============================================================================
+// KuCoin has no fixed socket URL: POST /api/v1/bullet-public returns a token
+// and a list of instance servers, each with its own ping interval / timeout.
+// Reconnects and the read watchdog are the feed supervisor's job.
+// Sizes on the execution topic are in lots, so every trade is converted with
+// the contract multiplier (XBTUSDTM: 1 lot = 0.001 BTC) before notional.
+//
+// The contract list is recorded alongside the frames so replay can convert
+// lots the same way.
+
+const (
+	kucoinFuturesAPI   = "https://api-futures.kucoin.com"
+	kucoinTopicBatch   = 50 // Symbols per subscribe message
+	kucoinDefaultPing  = 18 * time.Second
+	kucoinDefaultGrace = 10 * time.Second
+)
+
+var kucoinHTTP = &http.Client{Timeout: 10 * time.Second}
+
+type KuCoinFutures struct {
+	Registry *SymbolRegistry // Monitored universe (resubscribes on change)
+
+	mu        sync.RWMutex
+	contracts map[string]kucoinContract // "XBTUSDTM" -> Contract (status; lots live in Instruments)
+}
+
+type kucoinContract struct {
+	Symbol        string  `json:"symbol"`
+	BaseCurrency  string  `json:"baseCurrency"`
+	QuoteCurrency string  `json:"quoteCurrency"`
+	Multiplier    float64 `json:"multiplier"` // Base per lot
+	IsInverse     bool    `json:"isInverse"`
+	Status        string  `json:"status"`
+}
+
+type kucoinContractsResp struct {
+	Code string           `json:"code"`
+	Data []kucoinContract `json:"data"`
+}
+
+type kucoinServer struct {
+	Endpoint     string `json:"endpoint"`
+	Protocol     string `json:"protocol"`
+	PingInterval int64  `json:"pingInterval"` // ms
+	PingTimeout  int64  `json:"pingTimeout"`  // ms
+}
+
+type kucoinBulletResp struct {
+	Code string `json:"code"`
+	Data struct {
+		Token           string         `json:"token"`
+		InstanceServers []kucoinServer `json:"instanceServers"`
+	} `json:"data"`
+}
+
+type kucoinMsg struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"` // "welcome", "ack", "pong", "message", "error"
+	Topic string `json:"topic"`
+	Data  struct {
+		Symbol string  `json:"symbol"`
+		Price  string  `json:"price"`
+		Size   float64 `json:"size"` // Lots
+		Side   string  `json:"side"`
+		Time   int64   `json:"ts"` // Nanoseconds
+	} `json:"data"`
+}
+
+func (k *KuCoinFutures) Start(out chan<- Trade, analyzer *Analyzer) {
+	changes := k.Registry.Subscribe()
+
+	Feeds.Run(FeedSpec{
+		Name: "KuCoin",
+		Connect: func() (*websocket.Conn, func(), error) {
+			return k.connect(changes)
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("KuCoin", "execution", message)
+			k.handleFrame(message, out)
+		},
+	})
+}
+
+// connect runs one token -> connect -> welcome -> subscribe handshake. The
+// cleanup stops the heartbeat and the registry watch.
+func (k *KuCoinFutures) connect(changes <-chan struct{}) (*websocket.Conn, func(), error) {
+	if err := k.loadContracts(); err != nil {
+		k.mu.RLock()
+		cached := len(k.contracts)
+		k.mu.RUnlock()
+		if cached == 0 {
+			return nil, nil, fmt.Errorf("contract list: %v", err)
+		}
+		log.Printf("[KuCoin] Contract refresh failed (%v). Using cached multipliers.", err)
+	}
+
+	token, server, err := k.bullet()
+	if err != nil {
+		return nil, nil, fmt.Errorf("bullet-public: %v", err)
+	}
+	pingInterval := time.Duration(server.PingInterval) * time.Millisecond
+	if pingInterval <= 0 {
+		pingInterval = kucoinDefaultPing
+	}
+	pingTimeout := time.Duration(server.PingTimeout) * time.Millisecond
+	if pingTimeout <= 0 {
+		pingTimeout = kucoinDefaultGrace
+	}
+
+	url := fmt.Sprintf("%s?token=%s&connectId=%d", server.Endpoint, token, time.Now().UnixNano())
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 1. Welcome
+	conn.SetReadDeadline(time.Now().Add(pingInterval + pingTimeout))
+	var welcome kucoinMsg
+	if err := conn.ReadJSON(&welcome); err != nil || welcome.Type != "welcome" {
+		conn.Close()
+		return nil, nil, fmt.Errorf("no welcome (%v)", err)
+	}
+
+	// 2. Subscribe (comma-separated contracts per topic)
+	drainChanges(changes)
+	contracts := k.contractsFor(k.Registry.Symbols())
+	if len(contracts) == 0 {
+		conn.Close()
+		return nil, nil, fmt.Errorf("no KuCoin contracts for the monitored symbols")
+	}
+	for i := 0; i < len(contracts); i += kucoinTopicBatch {
+		end := i + kucoinTopicBatch
+		if end > len(contracts) {
+			end = len(contracts)
+		}
+		sub := map[string]interface{}{
+			"id":             strconv.FormatInt(time.Now().UnixNano(), 10),
+			"type":           "subscribe",
+			"topic":          "/contractMarket/execution:" + strings.Join(contracts[i:end], ","),
+			"privateChannel": false,
+			"response":       true,
+		}
+		if err := conn.WriteJSON(sub); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("subscribe error: %v", err)
+		}
+	}
+
+	log.Printf("[KuCoin] Connected to %s (%d contracts, ping %v)", server.Endpoint, len(contracts), pingInterval)
+
+	// 3. Heartbeat (server-provided interval; the pongs keep the read deadline fresh)
+	ping := fmt.Sprintf(`{"id":"%d","type":"ping"}`, time.Now().UnixNano())
+	return conn, chain(closeOnChange(changes, conn, "KuCoin"), heartbeat(conn, pingInterval, ping)), nil
+}
+
+// bullet fetches a public token and picks one of the instance servers
+func (k *KuCoinFutures) bullet() (string, kucoinServer, error) {
+	resp, err := kucoinHTTP.Post(kucoinFuturesAPI+"/api/v1/bullet-public", "application/json", nil)
+	if err != nil {
+		return "", kucoinServer{}, err
+	}
+	defer resp.Body.Close()
+
+	var bullet kucoinBulletResp
+	if err := json.NewDecoder(resp.Body).Decode(&bullet); err != nil {
+		return "", kucoinServer{}, err
+	}
+	if bullet.Code != "200000" || bullet.Data.Token == "" {
+		return "", kucoinServer{}, fmt.Errorf("code %s", bullet.Code)
+	}
+
+	var servers []kucoinServer
+	for _, s := range bullet.Data.InstanceServers {
+		if s.Protocol == "websocket" && s.Endpoint != "" {
+			servers = append(servers, s)
+		}
+	}
+	if len(servers) == 0 {
+		return "", kucoinServer{}, fmt.Errorf("no websocket instance servers")
+	}
+	return bullet.Data.Token, servers[rand.Intn(len(servers))], nil
+}
+
+// loadContracts refreshes the lot multipliers from /api/v1/contracts/active
+func (k *KuCoinFutures) loadContracts() error {
+	resp, err := kucoinHTTP.Get(kucoinFuturesAPI + "/api/v1/contracts/active")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := k.setContracts(body); err != nil {
+		return err
+	}
+
+	// Replay converts lots with the same multipliers
+	RecordFeed("KuCoin", "contracts", body)
+	return nil
+}
+
+// setContracts installs a contracts/active response (live or recorded)
+func (k *KuCoinFutures) setContracts(body []byte) error {
+	var res kucoinContractsResp
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Code != "200000" {
+		return fmt.Errorf("code %s", res.Code)
+	}
+
+	contracts := make(map[string]kucoinContract, len(res.Data))
+	for _, c := range res.Data {
+		// Linear USDT perpetuals only (inverse multipliers are USD per lot)
+		if c.IsInverse || c.QuoteCurrency != "USDT" || c.Multiplier <= 0 {
+			continue
+		}
+		contracts[c.Symbol] = c
+		Instruments.Register(VenueInstrument{Venue: "KuCoin", Native: c.Symbol, Base: c.BaseCurrency, Quote: c.QuoteCurrency, ContractSize: c.Multiplier})
+	}
+	if len(contracts) == 0 {
+		return fmt.Errorf("no linear USDT contracts")
+	}
+
+	k.mu.Lock()
+	k.contracts = contracts
+	k.mu.Unlock()
+	return nil
+}
+
+// contractsFor maps registry symbols ("BTCUSDT") to open KuCoin contracts ("XBTUSDTM") via the instrument registry
+func (k *KuCoinFutures) contractsFor(symbols []string) []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	var out []string
+	for _, s := range symbols {
+		native, ok := Instruments.Native("KuCoin", s)
+		if !ok {
+			continue
+		}
+		if c, ok := k.contracts[native]; ok && c.Status == "Open" {
+			out = append(out, c.Symbol)
+		}
+	}
+	return out
+}
+
+// handleFrame parses one /contractMarket/execution frame
+func (k *KuCoinFutures) handleFrame(message []byte, out chan<- Trade) {
+	var msg kucoinMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+	if msg.Type != "message" || !strings.HasPrefix(msg.Topic, "/contractMarket/execution") {
+		return
+	}
+
+	inst, ok := Instruments.Resolve("KuCoin", msg.Data.Symbol)
+	if !ok {
+		return // Unknown lot size: the notional would be wrong
+	}
+
+	price, _ := strconv.ParseFloat(msg.Data.Price, 64)
+	price, size, notionalValue := inst.Normalize(price, msg.Data.Size)
+	ts := msg.Data.Time
+	if ts > 1e15 {
+		ts /= 1e6 // ns -> ms
+	}
+
+	out <- Trade{
+		Symbol:    inst.Instrument.Key(),
+		Price:     price,
+		Size:      size,
+		Notional:  notionalValue,
+		Side:      msg.Data.Side,
+		Exchange:  "KuCoin",
+		Timestamp: ts,
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -10,6 +12,12 @@ type LiquidationMonitor struct {
 	mu           sync.RWMutex
 	liquidations map[string][]LiquidationEvent // Symbol -> Events
 	window       time.Duration
+
+	// Cascade Detection
+	cascade     CascadeConfig
+	lastCascade map[string]time.Time // "Symbol_Side" -> last LIQ_CASCADE alert
+
+	clock Clock
 }
 
 // LiquidationEvent represents a single rekt event
@@ -20,14 +28,54 @@ type LiquidationEvent struct {
 	Timestamp time.Time
 }
 
+// CascadeConfig defines when one-sided liquidations become a cascade
+type CascadeConfig struct {
+	Window      time.Duration // Sliding window (e.g. 30s)
+	MinNotional float64       // Total USD liquidated in one direction (e.g. $250k)
+	MinCount    int           // Minimum number of events (e.g. 5)
+	Cooldown    time.Duration // Min gap between alerts per symbol/side
+}
+
 // NewLiquidationMonitor creates the monitor
-func NewLiquidationMonitor(window time.Duration) *LiquidationMonitor {
+func NewLiquidationMonitor(window time.Duration, cascade CascadeConfig) *LiquidationMonitor {
 	return &LiquidationMonitor{
 		liquidations: make(map[string][]LiquidationEvent),
 		window:       window,
+		cascade:      cascade,
+		lastCascade:  make(map[string]time.Time),
+		clock:        RealClock,
+	}
+}
+
+// SetClock swaps the time source (event timestamps and windows)
+func (lm *LiquidationMonitor) SetClock(c Clock) {
+	lm.mu.Lock()
+	lm.clock = clockOrReal(c)
+	lm.mu.Unlock()
+}
+
+// Run records every LIQUIDATION alert from the feeds and forwards it (plus any
+// LIQ_CASCADE it triggers) to out. Other alert types pass through untouched.
+func (lm *LiquidationMonitor) Run(in <-chan Alert, out chan<- Alert) {
+	for alert := range in {
+		out <- alert
+		if cascade, ok := lm.Observe(alert); ok {
+			out <- cascade
+		}
 	}
 }
 
+// Observe records a LIQUIDATION alert and returns the LIQ_CASCADE it triggers, if any
+func (lm *LiquidationMonitor) Observe(alert Alert) (Alert, bool) {
+	if alert.Type != "LIQUIDATION" {
+		return Alert{}, false
+	}
+	symbol := NormalizeSymbol(alert.Symbol)
+	side := strings.ToUpper(alert.Data.Side) // "BUY" = Shorts rekt, "SELL" = Longs rekt
+	lm.AddLiquidation(symbol, side, alert.Data.Notional)
+	return lm.DetectCascade(symbol, side)
+}
+
 // AddLiquidation records a new event
 func (lm *LiquidationMonitor) AddLiquidation(symbol string, side string, amount float64) {
 	lm.mu.Lock()
@@ -37,7 +85,7 @@ func (lm *LiquidationMonitor) AddLiquidation(symbol string, side string, amount
 		Symbol:    symbol,
 		Side:      side,
 		Amount:    amount,
-		Timestamp: time.Now(),
+		Timestamp: lm.clock.Now(),
 	})
 
 	// Cleanup old events lazily
@@ -51,24 +99,87 @@ func (lm *LiquidationMonitor) GetLiquidationVolume(symbol string, side string) f
 	lm.mu.RLock()
 	defer lm.mu.RUnlock()
 
-	total := 0.0
-	cutoff := time.Now().Add(-lm.window)
+	total, _ := lm.sumSince(symbol, side, lm.clock.Now().Add(-lm.window))
+	return total
+}
+
+// GetCascadeVolume returns the cascade-window volume for a side, or 0 if the
+// burst is below the cascade thresholds
+func (lm *LiquidationMonitor) GetCascadeVolume(symbol string, side string) float64 {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
 
-	events, exists := lm.liquidations[symbol]
-	if !exists {
+	total, count := lm.sumSince(symbol, side, lm.clock.Now().Add(-lm.cascade.Window))
+	if !lm.isCascade(total, count) {
 		return 0.0
 	}
+	return total
+}
 
-	for _, ev := range events {
+// DetectCascade checks the sliding window for symbol/side and returns a
+// LIQ_CASCADE alert when thresholds are crossed (debounced by Cooldown)
+func (lm *LiquidationMonitor) DetectCascade(symbol string, side string) (Alert, bool) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	now := lm.clock.Now()
+	total, count := lm.sumSince(symbol, side, now.Add(-lm.cascade.Window))
+	if !lm.isCascade(total, count) {
+		return Alert{}, false
+	}
+
+	key := symbol + "_" + side
+	if last, exists := lm.lastCascade[key]; exists && now.Sub(last) < lm.cascade.Cooldown {
+		return Alert{}, false
+	}
+	lm.lastCascade[key] = now
+
+	victims := "SHORTS"
+	if side == "SELL" {
+		victims = "LONGS"
+	}
+	shortSym := Instruments.Canonical(symbol).Key() // "BTC", "BTCUSDC"
+
+	return Alert{
+		Type:           "LIQ_CASCADE",
+		Level:          5,
+		Symbol:         shortSym,
+		FormattedValue: fmt.Sprintf("$%.0fK", total/1000),
+		Message:        fmt.Sprintf("🌊 LIQ CASCADE: $%.0f of %s %s liquidated (%d events in %.0fs)", total, shortSym, victims, count, lm.cascade.Window.Seconds()),
+		Data: Trade{
+			Symbol:    shortSym,
+			Notional:  total,
+			Side:      strings.ToLower(side),
+			Timestamp: now.UnixMilli(),
+		},
+		Volume: total,
+	}, true
+}
+
+func (lm *LiquidationMonitor) isCascade(total float64, count int) bool {
+	return lm.cascade.Window > 0 && count >= lm.cascade.MinCount && total >= lm.cascade.MinNotional
+}
+
+// sumSince totals one side's events after cutoff (caller holds the lock)
+func (lm *LiquidationMonitor) sumSince(symbol, side string, cutoff time.Time) (float64, int) {
+	total := 0.0
+	count := 0
+	for _, ev := range lm.liquidations[symbol] {
 		if ev.Timestamp.After(cutoff) && ev.Side == side {
 			total += ev.Amount
+			count++
 		}
 	}
-	return total
+	return total, count
 }
 
 func (lm *LiquidationMonitor) cleanup(symbol string) {
-	cutoff := time.Now().Add(-lm.window)
+	// Keep enough history for both the fuel window and the cascade window
+	retention := lm.window
+	if lm.cascade.Window > retention {
+		retention = lm.cascade.Window
+	}
+	cutoff := lm.clock.Now().Add(-retention)
 	events := lm.liquidations[symbol]
 
 	valid := events[:0]
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -43,27 +47,10 @@ type Alert struct {
 	Data           Trade   `json:"data"`                      // Original trade data
 	Volume         float64 `json:"volume"`                    // Accumulated or Trigger Volume
 	Ratio          float64 `json:"ratio"`                     // Whale Pressure Ratio (0.0 - 1.0+)
-}
 
-// Valid symbols for monitoring (Top 10)
-var validSymbols = map[string]bool{
-	"BTCUSDT": true, "ETHUSDT": true, "BNBUSDT": true, "SOLUSDT": true, "XRPUSDT": true,
-	"SUIUSDT": true, "AVAXUSDT": true, "ADAUSDT": true, "DOGEUSDT": true, "LINKUSDT": true,
-	"HYPEUSDT": true, "FETUSDT": true, "TAOUSDT": true, "ARBUSDT": true, "OPUSDT": true,
-	"PEPEUSDT": true, "WIFUSDT": true, "SHIBUSDT": true, "TRXUSDT": true, "LTCUSDT": true,
-	"NEARUSDT": true, "INJUSDT": true, "APTUSDT": true, "RENDERUSDT": true, "SEIUSDT": true,
+	CVD map[string]CVDSnapshot `json:"cvd,omitempty"` // SENTIMENT: Per-symbol flow ("BTC" -> windows)
 }
 
-// ============================================================================
-// SENTIMENT ENGINE - GLOBAL STATE
-// ============================================================================
-
-var (
-	buyVolume   float64
-	sellVolume  float64
-	volumeMutex sync.Mutex
-)
-
 // Exchange interface - all exchanges must implement this
 type Exchange interface {
 	Start(out chan<- Trade, analyzer *Analyzer)
@@ -79,42 +66,58 @@ type LiquidationExchange interface {
 // ============================================================================
 
 type CoinManager struct {
-	symbols   []string
+	registry  *SymbolRegistry
 	exchanges []Exchange
+
+	// Liquidation-only sources (trade streams off, liquidations still feed cascades / fuel)
+	liquidations []LiquidationExchange
 }
 
-func NewCoinManager() *CoinManager {
+func NewCoinManager(registry *SymbolRegistry, binanceStreamsPerConn int) *CoinManager {
 	return &CoinManager{
-		symbols: []string{
-			"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT",
-			"SUIUSDT", "AVAXUSDT", "ADAUSDT", "DOGEUSDT", "LINKUSDT",
-			"HYPEUSDT", "FETUSDT", "TAOUSDT", "ARBUSDT", "OPUSDT",
-			"PEPEUSDT", "WIFUSDT", "SHIBUSDT", "TRXUSDT", "LTCUSDT",
-			"NEARUSDT", "INJUSDT", "APTUSDT", "RENDERUSDT", "SEIUSDT",
-		},
+		registry: registry,
 		exchanges: []Exchange{
-			&BinanceFutures{},
-			// &BybitV5{}, // Commenting out secondary exchanges to focus on Binance for initial stability with 25 pairs
-			// &OKXFutures{},
+			&BinanceFutures{Registry: registry, StreamsPerConn: binanceStreamsPerConn},
+			// &BybitV5{Registry: registry}, // Commenting out secondary exchanges to focus on Binance for initial stability with 25 pairs
+			// &OKXFutures{Registry: registry},
 			// &KrakenFutures{},
 			// &CoinbaseAdvanced{},
 			// &CryptoCom{},
-			// &KuCoinFutures{},
+			// &KuCoinFutures{Registry: registry},
+		},
+		liquidations: []LiquidationExchange{
+			&BybitV5{Registry: registry},
+			&OKXFutures{Registry: registry},
 		},
 	}
 }
 
 func (cm *CoinManager) Start(tradeChan chan<- Trade, alertChan chan<- Alert, analyzer *Analyzer) {
 	log.Println("🔌 CoinManager: Starting all exchange connections...")
+	Feeds.SetAlerts(alertChan) // FEED_STALE -> Broadcast
 
 	// 1. Start all Trade Exchanges
 	for _, exchange := range cm.exchanges {
 		go exchange.Start(tradeChan, analyzer)
 	}
 
-	// 2. Start Liquidations (Binance only for now)
-	binance := &BinanceFutures{}
-	go binance.StartLiquidations(alertChan)
+	// 2. Start Liquidations (every connector that implements LiquidationExchange + liquidation-only sources)
+	// Feed -> LiquidationMonitor (Fuel + Cascades) -> Alerts
+	liqChan := alertChan
+	if analyzer.liqMonitor != nil {
+		monitored := make(chan Alert, 500)
+		go analyzer.liqMonitor.Run(monitored, alertChan)
+		liqChan = monitored
+	}
+	sources := append([]LiquidationExchange(nil), cm.liquidations...)
+	for _, exchange := range cm.exchanges {
+		if liq, ok := exchange.(LiquidationExchange); ok {
+			sources = append(sources, liq)
+		}
+	}
+	for _, liq := range sources {
+		go liq.StartLiquidations(liqChan)
+	}
 }
 
 // ============================================================================
@@ -146,12 +149,13 @@ type DepthSnapshot struct {
 }
 
 type Analyzer struct {
-	priceMap       map[int64]*PriceVolume    // Price rounded to nearest dollar -> volume
-	activeIcebergs map[string]*IcebergState  // "Symbol_Price" -> State
-	alertChan      chan<- Alert              // Channel to send alerts from background tasks (Cleanup)
-	lastAlertTime  map[string]time.Time      // Debounce map: "Symbol+Price" -> last alert time
-	lastTickerTime map[string]time.Time      // Heartbeat map: "Symbol" -> last price update time
-	depthMap       map[string]*DepthSnapshot // "Symbol" -> Best Bid/Ask
+	priceMap       map[priceBucketKey]*PriceVolume // Symbol + price bucket -> volume
+	activeIcebergs map[string]*IcebergState        // "Symbol_Bucket" -> State
+	bucketBPS      float64                         // Price bucket width (bps of price, snapped to ticks)
+	alertChan      chan<- Alert                    // Channel to send alerts from background tasks (Cleanup)
+	lastAlertTime  map[string]time.Time            // Debounce map: "Symbol+Price" -> last alert time
+	lastTickerTime map[string]time.Time            // Heartbeat map: "Symbol" -> last price update time
+	books          *OrderBookManager               // 📗 Full-depth local order books
 	mapMutex       sync.RWMutex
 	cleanupTicker  *time.Ticker
 	executor       *ExecutionService     // 🧠 THE BRAIN NEEDS THE HANDS
@@ -161,22 +165,31 @@ type Analyzer struct {
 	appDistributor *AppSignalDistributor // 📱 PUBLIC APP FEED
 	scalpEngine    *ScalpSignalEngine    // ⚡ SCALP ENGINE
 	coPilot        *CoPilotService       // 👨‍✈️ CO-PILOT
+	signalSink     func(Signal)          // 🧪 BACKTEST: Receives validated signals instead of the executor
+	clock          Clock                 // ⏱️ Debounce / expiry time source (event time in replay)
+	funding        *FundingMonitor       // 💸 FUNDING GATE (nil = no gate)
+	openInterest   *OpenInterestMonitor  // 📊 OI DELTA (nil = not tracked)
+	cvd            *CVDEngine            // 📊 PER-SYMBOL FLOW (5s / 1m / 5m / 1h)
+	verifier       *SpoofVerifier        // 👻 SPOOF CHECK (async book re-check before approval)
 
 	// Synergy State
 	lastOKXWhale map[string]Trade // Symbol -> Last OKX Whale Trade
 }
 
-func NewAnalyzer(alertChan chan<- Alert, executor *ExecutionService, trendAnalyzer *TrendAnalyzer, liqMonitor *LiquidationMonitor, appDistributor *AppSignalDistributor, scalpEngine *ScalpSignalEngine, coPilot *CoPilotService) *Analyzer {
+func NewAnalyzer(alertChan chan<- Alert, executor *ExecutionService, trendAnalyzer *TrendAnalyzer, liqMonitor *LiquidationMonitor, appDistributor *AppSignalDistributor, scalpEngine *ScalpSignalEngine, coPilot *CoPilotService, books *OrderBookManager) *Analyzer {
 	a := &Analyzer{
-		priceMap:       make(map[int64]*PriceVolume),
+		priceMap:       make(map[priceBucketKey]*PriceVolume),
+		bucketBPS:      defaultBucketBPS,
 		activeIcebergs: make(map[string]*IcebergState),
 		alertChan:      alertChan,
 		lastAlertTime:  make(map[string]time.Time),
 		lastTickerTime: make(map[string]time.Time),
-		depthMap:       make(map[string]*DepthSnapshot),
+		books:          books,
+		clock:          RealClock,
 		cleanupTicker:  time.NewTicker(10 * time.Second),
 		executor:       executor,
 		signalFilter:   NewSignalFilter(),
+		cvd:            NewCVDEngine(),
 		trendAnalyzer:  trendAnalyzer,
 		liqMonitor:     liqMonitor,
 		appDistributor: appDistributor,
@@ -184,6 +197,7 @@ func NewAnalyzer(alertChan chan<- Alert, executor *ExecutionService, trendAnalyz
 		coPilot:        coPilot,
 		lastOKXWhale:   make(map[string]Trade),
 	}
+	a.verifier = NewSpoofVerifier(books, DefaultSpoofVerifyConfig, a.forwardSignal)
 
 	// Cleanup old entries every 10 seconds
 	go func() {
@@ -195,62 +209,81 @@ func NewAnalyzer(alertChan chan<- Alert, executor *ExecutionService, trendAnalyz
 	return a
 }
 
+// SetClock swaps the time source (cleanup, debouncing, ticker heartbeat)
+func (a *Analyzer) SetClock(c Clock) {
+	a.mapMutex.Lock()
+	a.clock = clockOrReal(c)
+	a.mapMutex.Unlock()
+	a.cvd.SetClock(c)
+	a.verifier.SetClock(c)
+}
+
+// forwardSignal sends a verified signal to approval and the public app feed
+func (a *Analyzer) forwardSignal(sig Signal) {
+	// Backtest: simulated fills (no approval round-trip)
+	if a.signalSink != nil {
+		a.signalSink(sig)
+		return
+	}
+
+	if a.executor != nil {
+		go a.executor.RequestApproval(sig)
+	}
+
+	// 📱 FEED PUBLIC APP (Decoupled & Buffered)
+	if a.appDistributor != nil {
+		go a.appDistributor.ProcessSignal(sig)
+	}
+}
+
+// AttachFunding enables the funding gate and Signal.Funding (call before trades flow)
+func (a *Analyzer) AttachFunding(fm *FundingMonitor) {
+	a.funding = fm
+}
+
+// AttachOpenInterest enables Signal.OI and OI weighting in the filter (call before trades flow)
+func (a *Analyzer) AttachOpenInterest(om *OpenInterestMonitor) {
+	a.openInterest = om
+}
+
+// SetBucketWidth sets the accumulation bucket width in bps of price (0 = exact tick)
+func (a *Analyzer) SetBucketWidth(bps float64) {
+	a.mapMutex.Lock()
+	a.bucketBPS = bps
+	a.mapMutex.Unlock()
+}
+
 func (a *Analyzer) cleanup() {
 	a.mapMutex.Lock()
 	defer a.mapMutex.Unlock()
 
-	now := time.Now().UnixMilli()
+	now := a.clock.Now().UnixMilli()
 
 	// 1. Cleanup Price Map
-	for price, pv := range a.priceMap {
+	for key, pv := range a.priceMap {
 		if now-pv.FirstSeen > 60000 {
-			delete(a.priceMap, price)
+			delete(a.priceMap, key)
 		}
 	}
 
-	// 2. Cleanup Active Icebergs & Detect Spoofs
+	// 2. Cleanup Active Icebergs (spoofed walls are judged by the WallTracker)
 	for key, state := range a.activeIcebergs {
 		// If inactive for > 1 minute, consider it "Gone"
 		if now-state.LastUpdate > 60000 {
-			duration := state.LastUpdate - state.StartTime
-
-			// SPOOF DETECTION: Duration < 5 seconds
-			if duration < 5000 {
-				if a.alertChan != nil {
-					go func(s *IcebergState) {
-						a.alertChan <- Alert{
-							Type:    "SPOOF",
-							Level:   5, // High priority
-							Symbol:  s.Symbol,
-							Message: fmt.Sprintf("👻 SPOOF DETECTED: Fake Wall at $%.2f vanished in %.1fs", s.Price, float64(duration)/1000.0),
-							Data: Trade{
-								Symbol:    s.Symbol,
-								Price:     s.Price,
-								Notional:  s.Volume, // Fix $0 Spoof Bug
-								Timestamp: now,
-							},
-						}
-					}(state)
-				}
-			}
-
 			delete(a.activeIcebergs, key)
 		}
 	}
 }
 
-func (a *Analyzer) ProcessDepth(update *DepthSnapshot) {
-	a.mapMutex.Lock()
-	defer a.mapMutex.Unlock()
-	a.depthMap[update.Symbol] = update
-}
-
 func (a *Analyzer) DetectIceberg(trade Trade) Alert {
 	a.mapMutex.Lock()
 	defer a.mapMutex.Unlock()
 
-	// Get Visible Depth
-	depth, exists := a.depthMap[trade.Symbol]
+	// Get Visible Depth (Local Order Book)
+	if a.books == nil {
+		return Alert{}
+	}
+	depth, exists := a.books.BestBidAsk(trade.Symbol)
 	if !exists {
 		return Alert{}
 	}
@@ -272,9 +305,8 @@ func (a *Analyzer) DetectIceberg(trade Trade) Alert {
 
 	// Relaxed Logic: Ratio 1.2 (20% bigger than visible)
 	if trade.Size >= visibleSize*1.2 {
-		// Calculate precise price key
-		priceKey := int64(trade.Price) // Simple rounding for mapping
-		icebergKey := fmt.Sprintf("%s_%d", trade.Symbol, priceKey)
+		// Per-symbol, tick-aware price level
+		icebergKey := priceBucket(trade.Symbol, trade.Price, a.bucketBPS).String()
 
 		// Get or Create Iceberg State
 		state, exists := a.activeIcebergs[icebergKey]
@@ -299,8 +331,9 @@ func (a *Analyzer) DetectIceberg(trade Trade) Alert {
 		debounceKey := fmt.Sprintf("ICEBERG_%s", icebergKey)
 		lastAlert, alertExists := a.lastAlertTime[debounceKey]
 
-		if !alertExists || time.Since(lastAlert) >= 30*time.Second {
-			a.lastAlertTime[debounceKey] = time.Now()
+		now := a.clock.Now()
+		if !alertExists || now.Sub(lastAlert) >= 30*time.Second {
+			a.lastAlertTime[debounceKey] = now
 
 			priceStr := fmt.Sprintf("$%.2f", trade.Price)
 			if trade.Price < 1.0 {
@@ -326,13 +359,17 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 
 	// 1. Ticker Heartbeat Check (Ensure UI gets price updates)
 	a.mapMutex.Lock()
+	now := a.clock.Now()
 	lastTicker, exists := a.lastTickerTime[trade.Symbol]
-	shouldSendTicker := !exists || time.Since(lastTicker) >= 1*time.Second
+	shouldSendTicker := !exists || now.Sub(lastTicker) >= 1*time.Second
 	if shouldSendTicker {
-		a.lastTickerTime[trade.Symbol] = time.Now()
+		a.lastTickerTime[trade.Symbol] = now
 	}
 	a.mapMutex.Unlock()
 
+	// 1.2. Order Flow (Every Trade, Per Symbol)
+	a.cvd.Add(trade)
+
 	// 1.5. Per-Coin Filtering (Dynamic Thresholds)
 	// We use a map to define what constitutes "Noise", "Trade", "Whale", and "Mega Whale" per coin.
 	type CoinLimits struct {
@@ -382,27 +419,16 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 		return Alert{}
 	}
 
-	// ====================================================================
-	// SENTIMENT TRACKING (Thread-Safe Volume Aggregation)
-	// ====================================================================
-	volumeMutex.Lock()
-	if trade.Side == "buy" {
-		buyVolume += notionalValue
-	} else {
-		sellVolume += notionalValue
-	}
-	volumeMutex.Unlock()
-
 	// ====================================================================
 	// INSTITUTIONAL LOGIC (Only > $500k reaches here)
 	// ====================================================================
 
-	// Round price to nearest dollar for iceberg detection
-	priceKey := int64(trade.Price)
-	icebergKey := fmt.Sprintf("%s_%d", trade.Symbol, priceKey)
-
 	a.mapMutex.Lock()
 
+	// Per-symbol price level for iceberg detection (width from tick size / bps)
+	priceKey := priceBucket(trade.Symbol, trade.Price, a.bucketBPS)
+	icebergKey := priceKey.String()
+
 	// 2. BREAKOUT DETECTOR
 	// If a trade executes at a known Iceberg price, it's "eating" the wall.
 	if state, exists := a.activeIcebergs[icebergKey]; exists {
@@ -415,8 +441,8 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 			// Emit STRONG WALL Alert (periodically de-bounced)
 			debounceKey := fmt.Sprintf("WALL_%s", icebergKey)
 			lastWallAlert, wallExists := a.lastAlertTime[debounceKey]
-			if !wallExists || time.Since(lastWallAlert) >= 1*time.Minute {
-				a.lastAlertTime[debounceKey] = time.Now()
+			if !wallExists || now.Sub(lastWallAlert) >= 1*time.Minute {
+				a.lastAlertTime[debounceKey] = now
 				a.mapMutex.Unlock()
 
 				// Dynamic price formatting
@@ -454,7 +480,7 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 		// Iceberg = Hidden Liquidity.
 		// If Taker BUYs hit Hidden ASK -> Resistance -> SHORT
 		// If Taker SELLs hit Hidden BID -> Support -> LONG
-		if a.executor != nil && depthAlert.Level >= 4 {
+		if (a.executor != nil || a.signalSink != nil) && depthAlert.Level >= 4 {
 			tradeSide := "LONG"
 			if trade.Side == "buy" {
 				tradeSide = "SHORT"
@@ -471,7 +497,7 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 
 			sig := Signal{
 				ID:       fmt.Sprintf("SIG-%d-%s", trade.Timestamp, trade.Symbol),
-				Symbol:   trade.Symbol + "USDT", // Fix: Append USDT for API
+				Symbol:   NormalizeSymbol(trade.Symbol),
 				Side:     tradeSide,
 				Entry:    entry,
 				StopLoss: sl,
@@ -482,12 +508,29 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 			// Execute IF Validated by Filter
 			// Filter for "The Big Three" (Internal Symbols)
 			if trade.Symbol == "BTC" || trade.Symbol == "ETH" || trade.Symbol == "SOL" {
-				// NOISE KILLER CHECK
-				// We access global volume stats (buyVolume, sellVolume) which are aggregated elsewhere
-				// Ideally we pass them? Yes, they are global vars in this package
+				// LIQUIDITY FILTER ($10k Keystone)
+				// Verify we have fuel (Opposite Liquidations)
+				oppSide := "BUY" // Short Liqs fuel Longs
+				if sig.Side == "SHORT" {
+					oppSide = "SELL"
+				} // Long Liqs fuel Shorts
+
+				liqVol := 0.0
+				if a.liqMonitor != nil {
+					liqVol = a.liqMonitor.GetLiquidationVolume(sig.Symbol, oppSide)
+					// A live cascade outweighs the plain rolling window
+					if cascadeVol := a.liqMonitor.GetCascadeVolume(sig.Symbol, oppSide); cascadeVol > liqVol {
+						liqVol = cascadeVol
+					}
+				}
+
+				// OPEN INTEREST: New money or closes?
+				sig.OI = a.openInterest.Delta(sig.Symbol)
+
 				// NOISE KILLER CHECK
 				// Returns: valid, ratio, score
-				isValid, ratio, score := a.signalFilter.Validate(trade, buyVolume, sellVolume, true, 0.0)
+				flow := a.cvd.Flow(trade.Symbol, a.signalFilter.FlowWindow)
+				isValid, ratio, score := a.signalFilter.Validate(trade, flow, true, liqVol, sig.OI)
 				if isValid {
 					// Update Signal with God-Tier Metrics
 					sig.Ratio = ratio
@@ -504,18 +547,6 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 					}
 					a.mapMutex.Unlock()
 
-					// LIQUIDITY FILTER ($10k Keystone)
-					// Verify we have fuel (Opposite Liquidations)
-					oppSide := "BUY" // Short Liqs fuel Longs
-					if sig.Side == "SHORT" {
-						oppSide = "SELL"
-					} // Long Liqs fuel Shorts
-
-					liqVol := 0.0
-					if a.liqMonitor != nil {
-						liqVol = a.liqMonitor.GetLiquidationVolume(sig.Symbol, oppSide)
-					}
-
 					// TREND ANALYSIS (9/21 EMA Dual-Trend)
 					if a.trendAnalyzer != nil {
 						trendRes := a.trendAnalyzer.GetMarketTrend(sig.Symbol, sig.Side)
@@ -545,23 +576,20 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 						}
 					}
 
-					log.Printf("🐳 WHALE DETECTED: %s %s | Liq Fuel: $%.0f", tradeSide, trade.Symbol, liqVol)
-
-					log.Printf("🐳 WHALE DETECTED & VALIDATED! REQUESTING APPROVAL for %s %s (Ratio: %.1f)...", tradeSide, trade.Symbol, ratio)
+					// 💸 GATE 2: Funding (don't open into a large payment we'd make)
+					sig.Funding = a.funding.Info(sig.Symbol)
+					if err := a.funding.CheckEntry(sig.Symbol, sig.Side); err != nil {
+						log.Printf("💸 FUNDING GATE: Ignored %s %s: %v", sig.Side, sig.Symbol, err)
+						return Alert{}
+					}
 
-					// SENTINEL MODE: Spoof Verification (1.5s Delay)
-					log.Printf("⏳ VERIFYING SPOOF (%s)... waiting 0.8s", sig.Symbol)
-					time.Sleep(800 * time.Millisecond)
-					// In a real HFT system, we would re-check the orderbook depth here.
-					// For this implementation, the delay ensures we don't react to flashes.
+					log.Printf("🐳 WHALE DETECTED: %s %s | Liq Fuel: $%.0f", tradeSide, trade.Symbol, liqVol)
 
-					go a.executor.RequestApproval(sig)
+					log.Printf("🐳 WHALE DETECTED & VALIDATED! VERIFYING before approval: %s %s (Ratio: %.1f)...", tradeSide, trade.Symbol, ratio)
 
-					// 📱 FEED PUBLIC APP (Decoupled & Buffered)
-					if a.appDistributor != nil {
-						// Clone signal or pass as is (Pass by value is safer if modified later)
-						go a.appDistributor.ProcessSignal(sig)
-					}
+					// SENTINEL MODE: Spoof Verification (async: the book is re-read after the
+					// delay, approval + app feed only if the wall is still there)
+					a.verifier.Submit(sig)
 				}
 			}
 		}
@@ -587,14 +615,14 @@ func (a *Analyzer) Analyze(trade Trade) Alert {
 		a.mapMutex.Unlock()
 
 		// Debounce
-		debounceKey := fmt.Sprintf("%s_%.0f", trade.Symbol, trade.Price)
+		debounceKey := icebergKey
 		a.mapMutex.Lock()
 		lastAlert, exists := a.lastAlertTime[debounceKey]
 		a.mapMutex.Unlock()
 
-		if !exists || time.Since(lastAlert) >= 1*time.Minute {
+		if !exists || now.Sub(lastAlert) >= 1*time.Minute {
 			a.mapMutex.Lock()
-			a.lastAlertTime[debounceKey] = time.Now()
+			a.lastAlertTime[debounceKey] = now
 			a.mapMutex.Unlock()
 
 			priceStr := fmt.Sprintf("$%.2f", trade.Price)
@@ -674,7 +702,10 @@ func (a *Analyzer) ProcessOKXWhale(trade Trade) {
 // BINANCE FUTURES
 // ============================================================================
 
-type BinanceFutures struct{}
+type BinanceFutures struct {
+	Registry       *SymbolRegistry // Monitored universe (resubscribes on change)
+	StreamsPerConn int             // Stream budget per shard socket (0 = default)
+}
 
 type binanceLiquidationMsg struct {
 	Order struct {
@@ -699,190 +730,156 @@ type binanceTradeData struct {
 }
 
 type binanceDepthData struct {
-	LastUpdateId int64      `json:"u"`
-	Bids         [][]string `json:"b"`
-	Asks         [][]string `json:"a"`
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateId int64      `json:"U"`
+	LastUpdateId  int64      `json:"u"`
+	PrevUpdateId  int64      `json:"pu"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
 }
 
+// extractSymbol maps a Binance stream ("1000pepeusdt@aggTrade") to its instrument key ("1000PEPE")
 func extractSymbol(streamName string) string {
-	parts := strings.Split(streamName, "@")
-	if len(parts) == 0 {
-		return "UNKNOWN"
-	}
-	symbolPart := strings.ToUpper(parts[0])
-	if strings.HasSuffix(symbolPart, "USDT") {
-		return symbolPart[:len(symbolPart)-4]
-	}
-	return symbolPart
+	native, _, _ := strings.Cut(streamName, "@")
+	return Instruments.Canonical(native).Key()
 }
 
-func (b *BinanceFutures) Start(out chan<- Trade, analyzer *Analyzer) {
-	symbols := []string{
-		"btcusdt", "ethusdt", "bnbusdt", "solusdt", "xrpusdt",
-		"suiusdt", "avaxusdt", "adausdt", "dogeusdt", "linkusdt",
-		"hypeusdt", "fetusdt", "taousdt", "arbusdt", "opusdt",
-		"pepeusdt", "wifusdt", "shibusdt", "trxusdt", "ltcusdt",
-		"nearusdt", "injusdt", "aptusdt", "renderusdt", "seiusdt",
-	}
-	var streams []string
-	for _, s := range symbols {
-		streams = append(streams, fmt.Sprintf("%s@aggTrade", s), fmt.Sprintf("%s@depth5@100ms", s))
+// handleFrame parses one combined-stream frame (aggTrade -> out, depth -> local book)
+func (b *BinanceFutures) handleFrame(message []byte, out chan<- Trade, analyzer *Analyzer) {
+	var msg binanceCombinedMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
 	}
-	url := "wss://fstream.binance.com/stream?streams=" + strings.Join(streams, "/")
 
-	log.Printf("🔌 ATTEMPTING CONNECTION to: %s", url)
+	if msg.Stream == "" {
+		logControlResp(message) // SUBSCRIBE / UNSUBSCRIBE reply
+		return
+	}
+	symbol := extractSymbol(msg.Stream)
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[Binance] Connection error: %v. Retrying in 5s...", err)
-			time.Sleep(5 * time.Second)
-			continue
+	if strings.Contains(msg.Stream, "depth") {
+		// Parse Depth Diff -> Local Order Book
+		var depthMsg binanceDepthData
+		if err := json.Unmarshal(msg.Data, &depthMsg); err != nil {
+			return
 		}
-		log.Println("[Binance] Connected (10 coins + Depth)")
-
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Printf("[Binance] Read error: %v. Reconnecting...", err)
-				conn.Close()
-				break
-			}
-
-			var msg binanceCombinedMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
-				continue
-			}
-
-			symbol := extractSymbol(msg.Stream)
-
-			if strings.Contains(msg.Stream, "depth") {
-				// Parse Depth
-				var depthMsg binanceDepthData
-				if err := json.Unmarshal(msg.Data, &depthMsg); err != nil {
-					continue
-				}
 
-				if len(depthMsg.Bids) > 0 && len(depthMsg.Asks) > 0 {
-					// Heartbeat (1% chance)
-					if rand.Intn(100) == 1 {
-						log.Printf("[HEARTBEAT] Receiving Depth for %s", symbol)
-					}
+		// Heartbeat (1% chance)
+		if rand.Intn(100) == 1 {
+			log.Printf("[HEARTBEAT] Receiving Depth for %s", symbol)
+		}
 
-					bestBid, _ := strconv.ParseFloat(depthMsg.Bids[0][0], 64)
-					bestBidQty, _ := strconv.ParseFloat(depthMsg.Bids[0][1], 64)
-					bestAsk, _ := strconv.ParseFloat(depthMsg.Asks[0][0], 64)
-					bestAskQty, _ := strconv.ParseFloat(depthMsg.Asks[0][1], 64)
-
-					analyzer.ProcessDepth(&DepthSnapshot{
-						Symbol:     symbol,
-						BestBid:    bestBid,
-						BestBidQty: bestBidQty,
-						BestAsk:    bestAsk,
-						BestAskQty: bestAskQty,
-						LastUpdate: time.Now().UnixMilli(),
-					})
-				}
+		if analyzer.books != nil {
+			analyzer.books.ApplyDiff(depthMsg)
+		}
 
-			} else {
-				// Parse Trade
-				var tradeMsg binanceTradeData
-				if err := json.Unmarshal(msg.Data, &tradeMsg); err != nil {
-					continue
-				}
+	} else {
+		// Parse Trade
+		var tradeMsg binanceTradeData
+		if err := json.Unmarshal(msg.Data, &tradeMsg); err != nil {
+			return
+		}
 
-				price, _ := strconv.ParseFloat(tradeMsg.Price, 64)
-				size, _ := strconv.ParseFloat(tradeMsg.Qty, 64)
-				notionalValue := price * size
-				side := "buy"
-				if tradeMsg.IsBuy {
-					side = "sell"
-				}
+		price, _ := strconv.ParseFloat(tradeMsg.Price, 64)
+		size, _ := strconv.ParseFloat(tradeMsg.Qty, 64)
+		notionalValue := price * size
+		side := "buy"
+		if tradeMsg.IsBuy {
+			side = "sell"
+		}
 
-				out <- Trade{
-					Symbol:    symbol,
-					Price:     price,
-					Size:      size,
-					Notional:  notionalValue,
-					Side:      side,
-					Exchange:  "Binance",
-					Timestamp: tradeMsg.Time,
-				}
-			}
+		out <- Trade{
+			Symbol:    symbol,
+			Price:     price,
+			Size:      size,
+			Notional:  notionalValue,
+			Side:      side,
+			Exchange:  "Binance",
+			Timestamp: tradeMsg.Time,
 		}
-		time.Sleep(2 * time.Second)
 	}
 }
 
 func (b *BinanceFutures) StartLiquidations(out chan<- Alert) {
 	url := "wss://fstream.binance.com/ws/!forceOrder@arr"
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[Binance Liq] Connection error: %v. Retrying in 5s...", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
-		log.Println("[Binance Liq] Connected")
-
-		for {
-			_, message, err := conn.ReadMessage()
+	Feeds.Run(FeedSpec{
+		Name:       "Binance Liq",
+		StaleAfter: liquidationStaleAfter,
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 			if err != nil {
-				log.Printf("[Binance Liq] Read error: %v. Reconnecting...", err)
-				conn.Close()
-				break
+				return nil, nil, err
 			}
+			log.Println("[Binance Liq] Connected")
+			return conn, nil, nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Binance", "forceOrder", message)
+			b.handleLiquidation(message, out)
+		},
+	})
+}
 
-			var msg binanceLiquidationMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
-				continue
-			}
+// handleLiquidation parses one !forceOrder frame into a LIQUIDATION alert
+func (b *BinanceFutures) handleLiquidation(message []byte, out chan<- Alert) {
+	var msg binanceLiquidationMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
 
-			if !validSymbols[msg.Order.Symbol] {
-				continue
-			}
+	if !b.Registry.Contains(msg.Order.Symbol) {
+		return
+	}
 
-			symbol := extractSymbol(strings.ToLower(msg.Order.Symbol) + "@aggTrade")
-			price, _ := strconv.ParseFloat(msg.Order.Price, 64)
-			size, _ := strconv.ParseFloat(msg.Order.Qty, 64)
-			notionalValue := price * size
-			side := "buy"
-			if msg.Order.Side == "SELL" {
-				side = "sell"
-			}
+	symbol := Instruments.Canonical(msg.Order.Symbol).Key()
+	price, _ := strconv.ParseFloat(msg.Order.Price, 64)
+	size, _ := strconv.ParseFloat(msg.Order.Qty, 64)
+	side := "buy"
+	if msg.Order.Side == "SELL" {
+		side = "sell"
+	}
 
-			if notionalValue < 2000.0 {
-				continue
-			}
+	if alert, ok := liquidationAlert("Binance", symbol, price, size, side, msg.Order.Time); ok {
+		out <- alert
+	}
+}
 
-			trade := Trade{
-				Symbol:    symbol,
-				Price:     price,
-				Size:      size,
-				Notional:  notionalValue,
-				Side:      side,
-				Exchange:  "Binance",
-				Timestamp: msg.Order.Time,
-			}
+// liquidationAlert normalizes a forced order into the LIQUIDATION shape every venue shares.
+// side is the liquidation order's side: "sell" = longs rekt, "buy" = shorts rekt.
+func liquidationAlert(exchange, symbol string, price, size float64, side string, ts int64) (Alert, bool) {
+	notionalValue := price * size
+	if notionalValue < 2000.0 {
+		return Alert{}, false
+	}
 
-			out <- Alert{
-				Type:    "LIQUIDATION",
-				Level:   4,
-				Symbol:  symbol,
-				Message: fmt.Sprintf("💀 LIQUIDATION: $%.0f %s %s on Binance @ $%.2f", notionalValue, symbol, side, price),
-				Data:    trade,
-			}
-		}
-		time.Sleep(2 * time.Second)
+	trade := Trade{
+		Symbol:    symbol,
+		Price:     price,
+		Size:      size,
+		Notional:  notionalValue,
+		Side:      side,
+		Exchange:  exchange,
+		Timestamp: ts,
 	}
+
+	return Alert{
+		Type:    "LIQUIDATION",
+		Level:   4,
+		Symbol:  symbol,
+		Message: fmt.Sprintf("💀 LIQUIDATION: $%.0f %s %s on %s @ $%.2f", notionalValue, symbol, side, exchange, price),
+		Data:    trade,
+	}, true
 }
 
 // ============================================================================
 // BYBIT V5 LINEAR
 // ============================================================================
 
-type BybitV5 struct{}
+type BybitV5 struct {
+	Registry *SymbolRegistry // Monitored universe (resubscribes on change)
+}
 
 type bybitMsg struct {
 	Topic string `json:"topic"`
@@ -896,90 +893,156 @@ type bybitMsg struct {
 
 func (b *BybitV5) Start(out chan<- Trade, analyzer *Analyzer) {
 	url := "wss://stream.bybit.com/v5/public/linear"
+	changes := b.Registry.Subscribe()
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[Bybit] Connection error: %v. Retrying in 5s...", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
+	Feeds.Run(FeedSpec{
+		Name: "Bybit",
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			drainChanges(changes)
+			symbols := b.Registry.Symbols()
+			var args []string
+			for _, s := range symbols {
+				if native, ok := Instruments.Native("Bybit", s); ok {
+					args = append(args, "publicTrade."+native)
+				}
+			}
+			sub := map[string]interface{}{
+				"op":   "subscribe",
+				"args": args,
+			}
+			if err := conn.WriteJSON(sub); err != nil {
+				conn.Close()
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
+			}
+
+			log.Printf("[Bybit] Connected (%d coins)", len(symbols))
+			return conn, chain(closeOnChange(changes, conn, "Bybit"), heartbeat(conn, 20*time.Second, `{"op":"ping"}`)), nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Bybit", "publicTrade", message)
+			b.handleFrame(message, out)
+		},
+	})
+}
 
-		sub := map[string]interface{}{
-			"op": "subscribe",
-			"args": []string{
-				"publicTrade.BTCUSDT",
-				"publicTrade.ETHUSDT",
-				"publicTrade.SOLUSDT",
-				"publicTrade.BNBUSDT",
-				"publicTrade.XRPUSDT",
-				"publicTrade.ADAUSDT",
-				"publicTrade.DOGEUSDT",
-				"publicTrade.AVAXUSDT",
-				"publicTrade.TRXUSDT", // Added
-				"publicTrade.PEPEUSDT",
-			},
+// handleFrame parses one publicTrade frame
+func (b *BybitV5) handleFrame(message []byte, out chan<- Trade) {
+	var msg bybitMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	// publicTrade.SHIB1000USDT -> 1000SHIBUSDT (canonical units)
+	inst, ok := Instruments.Resolve("Bybit", strings.TrimPrefix(msg.Topic, "publicTrade."))
+	if !ok {
+		return
+	}
+
+	for _, trade := range msg.Data {
+		price, _ := strconv.ParseFloat(trade.Price, 64)
+		size, _ := strconv.ParseFloat(trade.Size, 64)
+		price, size, notionalValue := inst.Normalize(price, size)
+		side := "buy"
+		if trade.Side == "Sell" {
+			side = "sell"
 		}
-		if err := conn.WriteJSON(sub); err != nil {
-			log.Printf("[Bybit] Subscribe error: %v", err)
-			conn.Close()
-			continue
+
+		out <- Trade{
+			Symbol:    inst.Instrument.Key(),
+			Price:     price,
+			Size:      size,
+			Notional:  notionalValue,
+			Side:      side,
+			Exchange:  "Bybit",
+			Timestamp: trade.Time,
 		}
+	}
+}
 
-		log.Println("[Bybit] Connected (10 coins)")
+type bybitLiquidationMsg struct {
+	Topic string `json:"topic"`
+	Data  []struct {
+		Symbol string `json:"s"`
+		Side   string `json:"S"` // Position side: "Buy" = long liquidated
+		Size   string `json:"v"`
+		Price  string `json:"p"`
+		Time   int64  `json:"T"`
+	} `json:"data"`
+}
 
-		// Heartbeat
-		go func() {
-			ticker := time.NewTicker(20 * time.Second)
-			defer ticker.Stop()
-			for range ticker.C {
-				if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"op":"ping"}`)); err != nil {
-					return
-				}
-			}
-		}()
+// StartLiquidations streams allLiquidation.{symbol} for the registry's symbols
+func (b *BybitV5) StartLiquidations(out chan<- Alert) {
+	url := "wss://stream.bybit.com/v5/public/linear"
+	changes := b.Registry.Subscribe()
 
-		for {
-			_, message, err := conn.ReadMessage()
+	Feeds.Run(FeedSpec{
+		Name:       "Bybit Liq",
+		StaleAfter: liquidationStaleAfter,
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 			if err != nil {
-				conn.Close()
-				break
+				return nil, nil, err
 			}
 
-			var msg bybitMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
-				continue
-			}
-
-			symbol := ""
-			if msg.Topic != "" {
-				parts := strings.Split(msg.Topic, ".")
-				if len(parts) == 2 {
-					symbol = extractSymbol(strings.ToLower(parts[1]) + "@aggTrade")
+			drainChanges(changes)
+			symbols := b.Registry.Symbols()
+			for i := 0; i < len(symbols); i += 10 { // Bybit: 10 args per request
+				end := i + 10
+				if end > len(symbols) {
+					end = len(symbols)
 				}
-			}
-
-			for _, trade := range msg.Data {
-				price, _ := strconv.ParseFloat(trade.Price, 64)
-				size, _ := strconv.ParseFloat(trade.Size, 64)
-				notionalValue := price * size
-				side := "buy"
-				if trade.Side == "Sell" {
-					side = "sell"
+				var args []string
+				for _, s := range symbols[i:end] {
+					if native, ok := Instruments.Native("Bybit", s); ok {
+						args = append(args, "allLiquidation."+native)
+					}
 				}
-
-				out <- Trade{
-					Symbol:    symbol,
-					Price:     price,
-					Size:      size,
-					Notional:  notionalValue,
-					Side:      side,
-					Exchange:  "Bybit",
-					Timestamp: trade.Time,
+				if err := conn.WriteJSON(map[string]interface{}{"op": "subscribe", "args": args}); err != nil {
+					conn.Close()
+					return nil, nil, fmt.Errorf("subscribe error: %v", err)
 				}
 			}
+
+			log.Printf("[Bybit Liq] Connected (%d coins)", len(symbols))
+			return conn, chain(closeOnChange(changes, conn, "Bybit Liq"), heartbeat(conn, 20*time.Second, `{"op":"ping"}`)), nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Bybit", "allLiquidation", message)
+			b.handleLiquidation(message, out)
+		},
+	})
+}
+
+// handleLiquidation parses one allLiquidation frame into LIQUIDATION alerts
+func (b *BybitV5) handleLiquidation(message []byte, out chan<- Alert) {
+	var msg bybitLiquidationMsg
+	if err := json.Unmarshal(message, &msg); err != nil || !strings.HasPrefix(msg.Topic, "allLiquidation.") {
+		return
+	}
+
+	for _, liq := range msg.Data {
+		inst, ok := Instruments.Resolve("Bybit", liq.Symbol)
+		if !ok || !b.Registry.Contains(inst.Instrument.Symbol) {
+			continue
+		}
+		price, _ := strconv.ParseFloat(liq.Price, 64)
+		size, _ := strconv.ParseFloat(liq.Size, 64)
+		price, size, _ = inst.Normalize(price, size)
+
+		// A liquidated long is closed with a sell order (Binance convention)
+		side := "buy"
+		if liq.Side == "Buy" {
+			side = "sell"
+		}
+
+		if alert, ok := liquidationAlert("Bybit", inst.Instrument.Key(), price, size, side, liq.Time); ok {
+			out <- alert
 		}
-		time.Sleep(2 * time.Second)
 	}
 }
 
@@ -987,7 +1050,9 @@ func (b *BybitV5) Start(out chan<- Trade, analyzer *Analyzer) {
 // OKX FUTURES
 // ============================================================================
 
-type OKXFutures struct{}
+type OKXFutures struct {
+	Registry *SymbolRegistry // Monitored universe (resubscribes on change)
+}
 
 type okxMsg struct {
 	Arg struct {
@@ -1004,77 +1069,216 @@ type okxMsg struct {
 
 func (o *OKXFutures) Start(out chan<- Trade, analyzer *Analyzer) {
 	url := "wss://ws.okx.com:8443/ws/v5/public"
+	changes := o.Registry.Subscribe()
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[OKX] Connection error: %v. Retrying in 5s...", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
+	Feeds.Run(FeedSpec{
+		Name: "OKX",
+		Connect: func() (*websocket.Conn, func(), error) {
+			if err := o.ensureContractValues(); err != nil {
+				return nil, nil, err
+			}
 
-		sub := map[string]interface{}{
-			"op": "subscribe",
-			"args": []map[string]string{
-				{"channel": "trades", "instId": "BTC-USDT-SWAP"},
-				{"channel": "trades", "instId": "ETH-USDT-SWAP"},
-				{"channel": "trades", "instId": "SOL-USDT-SWAP"},
-				{"channel": "trades", "instId": "BNB-USDT-SWAP"},
-				{"channel": "trades", "instId": "XRP-USDT-SWAP"},
-				{"channel": "trades", "instId": "ADA-USDT-SWAP"},
-				{"channel": "trades", "instId": "DOGE-USDT-SWAP"},
-				{"channel": "trades", "instId": "AVAX-USDT-SWAP"},
-				{"channel": "trades", "instId": "TRX-USDT-SWAP"}, // Added
-				{"channel": "trades", "instId": "PEPE-USDT-SWAP"},
-			},
-		}
-		if err := conn.WriteJSON(sub); err != nil {
-			log.Printf("[OKX] Subscribe error: %v", err)
-			conn.Close()
-			continue
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			drainChanges(changes)
+			symbols := o.Registry.Symbols()
+			var args []map[string]string
+			for _, s := range symbols {
+				// 1000PEPEUSDT -> PEPE-USDT-SWAP
+				if native, ok := Instruments.Native("OKX", s); ok {
+					args = append(args, map[string]string{"channel": "trades", "instId": native})
+				}
+			}
+			sub := map[string]interface{}{
+				"op":   "subscribe",
+				"args": args,
+			}
+			if err := conn.WriteJSON(sub); err != nil {
+				conn.Close()
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
+			}
+
+			log.Printf("[OKX] Connected (%d coins)", len(symbols))
+			return conn, closeOnChange(changes, conn, "OKX"), nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("OKX", "trades", message)
+			o.handleFrame(message, out)
+		},
+	})
+}
+
+// handleFrame parses one trades frame
+func (o *OKXFutures) handleFrame(message []byte, out chan<- Trade) {
+	var msg okxMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	// Contracts -> base via ctVal (unknown contract size: the notional would be wrong)
+	inst, ok := Instruments.Resolve("OKX", msg.Arg.InstId)
+	if !ok {
+		return
+	}
+
+	for _, trade := range msg.Data {
+		price, _ := strconv.ParseFloat(trade.Price, 64)
+		contracts, _ := strconv.ParseFloat(trade.Size, 64)
+		price, size, notionalValue := inst.Normalize(price, contracts)
+		ts, _ := strconv.ParseInt(trade.Time, 10, 64)
+
+		out <- Trade{
+			Symbol:    inst.Instrument.Key(),
+			Price:     price,
+			Size:      size,
+			Notional:  notionalValue,
+			Side:      trade.Side,
+			Exchange:  "OKX",
+			Timestamp: ts,
 		}
+	}
+}
 
-		log.Println("[OKX] Connected (10 coins)")
+type okxLiquidationMsg struct {
+	Arg struct {
+		Channel string `json:"channel"`
+	} `json:"arg"`
+	Data []struct {
+		InstId  string `json:"instId"`
+		Details []struct {
+			Side  string `json:"side"` // Liquidation order side: "sell" = long liquidated
+			Price string `json:"bkPx"`
+			Size  string `json:"sz"` // Contracts
+			Time  string `json:"ts"`
+		} `json:"details"`
+	} `json:"data"`
+}
 
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				conn.Close()
-				break
+type okxInstrumentsResp struct {
+	Code string `json:"code"`
+	Data []struct {
+		InstId string `json:"instId"`
+		CtVal  string `json:"ctVal"`
+		Settle string `json:"settleCcy"`
+	} `json:"data"`
+}
+
+// StartLiquidations streams the liquidation-orders channel (all SWAP instruments)
+func (o *OKXFutures) StartLiquidations(out chan<- Alert) {
+	url := "wss://ws.okx.com:8443/ws/v5/public"
+
+	Feeds.Run(FeedSpec{
+		Name:       "OKX Liq",
+		StaleAfter: liquidationStaleAfter,
+		Connect: func() (*websocket.Conn, func(), error) {
+			if err := o.ensureContractValues(); err != nil {
+				return nil, nil, err
 			}
 
-			var msg okxMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
-				continue
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return nil, nil, err
 			}
 
-			symbol := ""
-			if msg.Arg.InstId != "" {
-				parts := strings.Split(msg.Arg.InstId, "-")
-				if len(parts) >= 1 {
-					symbol = parts[0]
-				}
+			sub := map[string]interface{}{
+				"op":   "subscribe",
+				"args": []map[string]string{{"channel": "liquidation-orders", "instType": "SWAP"}},
+			}
+			if err := conn.WriteJSON(sub); err != nil {
+				conn.Close()
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
 			}
+			log.Println("[OKX Liq] Connected")
 
-			for _, trade := range msg.Data {
-				price, _ := strconv.ParseFloat(trade.Price, 64)
-				contracts, _ := strconv.ParseFloat(trade.Size, 64)
-				notionalValue := contracts * 100.0
-				size := notionalValue / price
-				ts, _ := strconv.ParseInt(trade.Time, 10, 64)
-
-				out <- Trade{
-					Symbol:    symbol,
-					Price:     price,
-					Size:      size,
-					Notional:  notionalValue,
-					Side:      trade.Side,
-					Exchange:  "OKX",
-					Timestamp: ts,
-				}
+			// Heartbeat (OKX drops idle connections after 30s)
+			return conn, heartbeat(conn, 20*time.Second, "ping"), nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("OKX", "liquidation-orders", message)
+			o.handleLiquidation(message, out)
+		},
+	})
+}
+
+// ensureContractValues refreshes the ctVals; a failed refresh is fine while cached ones exist
+func (o *OKXFutures) ensureContractValues() error {
+	err := o.loadContractValues()
+	if err != nil && Instruments.Listings("OKX") == 0 {
+		return fmt.Errorf("instrument load failed: %v", err)
+	}
+	return nil
+}
+
+// loadContractValues refreshes ctVal (base per contract) for USDT swaps
+func (o *OKXFutures) loadContractValues() error {
+	resp, err := http.Get("https://www.okx.com/api/v5/public/instruments?instType=SWAP")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := o.setContractValues(body); err != nil {
+		return err
+	}
+
+	// Replay converts contracts with the same values
+	RecordFeed("OKX", "instruments", body)
+	return nil
+}
+
+// setContractValues installs an instruments response (live or recorded)
+func (o *OKXFutures) setContractValues(body []byte) error {
+	var res okxInstrumentsResp
+	if err := json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if res.Code != "0" {
+		return fmt.Errorf("code %s", res.Code)
+	}
+
+	for _, inst := range res.Data {
+		// BTC-USDT-SWAP (USDT-margined only: inverse ctVal is USD per contract)
+		parts := strings.Split(inst.InstId, "-")
+		if inst.Settle != "USDT" || len(parts) != 3 {
+			continue
+		}
+		if v, err := strconv.ParseFloat(inst.CtVal, 64); err == nil && v > 0 {
+			Instruments.Register(VenueInstrument{Venue: "OKX", Native: inst.InstId, Base: parts[0], Quote: parts[1], ContractSize: v})
+		}
+	}
+	return nil
+}
+
+// handleLiquidation parses one liquidation-orders frame into LIQUIDATION alerts
+func (o *OKXFutures) handleLiquidation(message []byte, out chan<- Alert) {
+	var msg okxLiquidationMsg
+	if err := json.Unmarshal(message, &msg); err != nil || msg.Arg.Channel != "liquidation-orders" {
+		return
+	}
+
+	for _, d := range msg.Data {
+		// Registered USDT swaps only (unknown contract size: the notional would be wrong)
+		inst, ok := Instruments.Resolve("OKX", d.InstId)
+		if !ok || !o.Registry.Contains(inst.Instrument.Symbol) {
+			continue
+		}
+
+		for _, liq := range d.Details {
+			price, _ := strconv.ParseFloat(liq.Price, 64)
+			contracts, _ := strconv.ParseFloat(liq.Size, 64)
+			price, size, _ := inst.Normalize(price, contracts)
+			ts, _ := strconv.ParseInt(liq.Time, 10, 64)
+			if alert, ok := liquidationAlert("OKX", inst.Instrument.Key(), price, size, liq.Side, ts); ok {
+				out <- alert
 			}
 		}
-		time.Sleep(2 * time.Second)
 	}
 }
 
@@ -1085,8 +1289,9 @@ func (o *OKXFutures) Start(out chan<- Trade, analyzer *Analyzer) {
 type KrakenFutures struct{}
 
 type krakenMsg struct {
-	Feed string `json:"feed"`
-	Data []struct {
+	Feed      string `json:"feed"`
+	ProductId string `json:"product_id"`
+	Data      []struct {
 		Price float64 `json:"price"`
 		Qty   float64 `json:"qty"`
 		Side  string  `json:"side"`
@@ -1097,54 +1302,61 @@ type krakenMsg struct {
 func (k *KrakenFutures) Start(out chan<- Trade, analyzer *Analyzer) {
 	url := "wss://futures.kraken.com/ws/v1"
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[Kraken] Connection error: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		sub := map[string]interface{}{
-			"event":       "subscribe",
-			"feed":        "trade",
-			"product_ids": []string{"PI_XBTUSD"},
-		}
-		if err := conn.WriteJSON(sub); err != nil {
-			conn.Close()
-			continue
-		}
-
-		for {
-			_, message, err := conn.ReadMessage()
+	Feeds.Run(FeedSpec{
+		Name:       "Kraken",
+		StaleAfter: quietStaleAfter,
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 			if err != nil {
-				conn.Close()
-				break
+				return nil, nil, err
 			}
 
-			var msg krakenMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
-				continue
+			sub := map[string]interface{}{
+				"event":       "subscribe",
+				"feed":        "trade",
+				"product_ids": []string{"PI_XBTUSD"},
 			}
-
-			if msg.Feed != "trade" {
-				continue
+			if err := conn.WriteJSON(sub); err != nil {
+				conn.Close()
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
 			}
+			return conn, nil, nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Kraken", "trade", message)
+			k.handleFrame(message, out)
+		},
+	})
+}
 
-			for _, trade := range msg.Data {
-				if trade.Qty < 0.1 {
-					continue
-				}
-				out <- Trade{
-					Price:     trade.Price,
-					Size:      trade.Qty,
-					Side:      trade.Side,
-					Exchange:  "Kraken",
-					Timestamp: trade.Time,
-				}
-			}
+// handleFrame parses one trade feed frame
+func (k *KrakenFutures) handleFrame(message []byte, out chan<- Trade) {
+	var msg krakenMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	if msg.Feed != "trade" {
+		return
+	}
+
+	// PI_XBTUSD: inverse, qty is USD contracts
+	inst, ok := Instruments.Resolve("Kraken", msg.ProductId)
+	if !ok {
+		return
+	}
+
+	for _, trade := range msg.Data {
+		price, size, notionalValue := inst.Normalize(trade.Price, trade.Qty)
+		out <- Trade{
+			Symbol:    inst.Instrument.Key(),
+			Price:     price,
+			Size:      size,
+			Notional:  notionalValue,
+			Side:      trade.Side,
+			Exchange:  "Kraken",
+			Timestamp: trade.Time,
 		}
-		time.Sleep(2 * time.Second)
 	}
 }
 
@@ -1170,61 +1382,64 @@ type coinbaseMsg struct {
 func (c *CoinbaseAdvanced) Start(out chan<- Trade, analyzer *Analyzer) {
 	url := "wss://advanced-trade-ws.coinbase.com"
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[Coinbase] Connection error: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		sub := map[string]interface{}{
-			"type":        "subscribe",
-			"product_ids": []string{"BTC-USD"},
-			"channel":     "market_trades",
-		}
-		if err := conn.WriteJSON(sub); err != nil {
-			conn.Close()
-			continue
-		}
-
-		for {
-			_, message, err := conn.ReadMessage()
+	Feeds.Run(FeedSpec{
+		Name:       "Coinbase",
+		StaleAfter: quietStaleAfter,
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 			if err != nil {
+				return nil, nil, err
+			}
+
+			sub := map[string]interface{}{
+				"type":        "subscribe",
+				"product_ids": []string{"BTC-USD"},
+				"channel":     "market_trades",
+			}
+			if err := conn.WriteJSON(sub); err != nil {
 				conn.Close()
-				break
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
 			}
-			var msg coinbaseMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
+			return conn, nil, nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Coinbase", "market_trades", message)
+			c.handleFrame(message, out)
+		},
+	})
+}
+
+// handleFrame parses one market_trades frame
+func (c *CoinbaseAdvanced) handleFrame(message []byte, out chan<- Trade) {
+	var msg coinbaseMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	for _, event := range msg.Events {
+		if event.Type != "update" {
+			continue
+		}
+		for _, trade := range event.Trades {
+			inst, ok := Instruments.Resolve("Coinbase", trade.ProductId)
+			if !ok {
 				continue
 			}
+			price, _ := strconv.ParseFloat(trade.Price, 64)
+			size, _ := strconv.ParseFloat(trade.Size, 64)
+			price, size, notionalValue := inst.Normalize(price, size)
+			ts, _ := time.Parse(time.RFC3339, trade.Time)
 
-			for _, event := range msg.Events {
-				if event.Type != "update" {
-					continue
-				}
-				for _, trade := range event.Trades {
-					price, _ := strconv.ParseFloat(trade.Price, 64)
-					size, _ := strconv.ParseFloat(trade.Size, 64)
-					ts, _ := time.Parse(time.RFC3339, trade.Time)
-					symbol := "UNKNOWN"
-					if strings.HasPrefix(trade.ProductId, "BTC") {
-						symbol = "BTC"
-					}
-
-					out <- Trade{
-						Symbol:    symbol,
-						Price:     price,
-						Size:      size,
-						Notional:  price * size,
-						Side:      trade.Side,
-						Exchange:  "Coinbase",
-						Timestamp: ts.UnixMilli(),
-					}
-				}
+			out <- Trade{
+				Symbol:    inst.Instrument.Key(),
+				Price:     price,
+				Size:      size,
+				Notional:  notionalValue,
+				Side:      trade.Side,
+				Exchange:  "Coinbase",
+				Timestamp: ts.UnixMilli(),
 			}
 		}
-		time.Sleep(2 * time.Second)
 	}
 }
 
@@ -1236,7 +1451,8 @@ type CryptoCom struct{}
 
 type cryptoComMsg struct {
 	Result struct {
-		Data []struct {
+		InstrumentName string `json:"instrument_name"`
+		Data           []struct {
 			Price float64 `json:"p"`
 			Qty   float64 `json:"q"`
 			Side  string  `json:"s"`
@@ -1248,78 +1464,58 @@ type cryptoComMsg struct {
 func (c *CryptoCom) Start(out chan<- Trade, analyzer *Analyzer) {
 	url := "wss://stream.crypto.com/v2/market"
 
-	for {
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			log.Printf("[Crypto.com] Error: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		sub := map[string]interface{}{
-			"method": "subscribe",
-			"params": map[string]interface{}{"channels": []string{"trade.BTC_USD_PERP"}},
-		}
-		if err := conn.WriteJSON(sub); err != nil {
-			conn.Close()
-			continue
-		}
-
-		for {
-			_, message, err := conn.ReadMessage()
+	Feeds.Run(FeedSpec{
+		Name:       "Crypto.com",
+		StaleAfter: quietStaleAfter,
+		Connect: func() (*websocket.Conn, func(), error) {
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 			if err != nil {
+				return nil, nil, err
+			}
+
+			sub := map[string]interface{}{
+				"method": "subscribe",
+				"params": map[string]interface{}{"channels": []string{"trade.BTC_USD_PERP"}},
+			}
+			if err := conn.WriteJSON(sub); err != nil {
 				conn.Close()
-				break
+				return nil, nil, fmt.Errorf("subscribe error: %v", err)
 			}
+			return conn, nil, nil
+		},
+		Handle: func(conn *websocket.Conn, message []byte) {
+			RecordFeed("Crypto.com", "trade", message)
 			if strings.Contains(string(message), "public/heartbeat") {
 				var hb struct {
 					ID int `json:"id"`
 				}
 				json.Unmarshal(message, &hb)
 				conn.WriteJSON(map[string]interface{}{"id": hb.ID, "method": "public/respond-heartbeat"})
-				continue
-			}
-			var msg cryptoComMsg
-			if err := json.Unmarshal(message, &msg); err != nil {
-				continue
-			}
-			for _, t := range msg.Result.Data {
-				side := "buy"
-				if t.Side == "SELL" {
-					side = "sell"
-				}
-				out <- Trade{Price: t.Price, Size: t.Qty, Side: side, Exchange: "Crypto.com", Timestamp: t.Time}
+				return
 			}
-		}
-		time.Sleep(2 * time.Second)
-	}
+			c.handleFrame(message, out)
+		},
+	})
 }
 
-// ============================================================================
-// KUCOIN
-// ============================================================================
-
-type KuCoinFutures struct{}
-
-type kucoinMsg struct {
-	Topic string
-	Data  struct {
-		Price string `json:"price"`
-		Size  int64  `json:"size"`
-		Side  string `json:"side"`
-		Time  int64  `json:"ts"`
+// handleFrame parses one trade channel frame
+func (c *CryptoCom) handleFrame(message []byte, out chan<- Trade) {
+	var msg cryptoComMsg
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+	inst, ok := Instruments.Resolve("Crypto.com", msg.Result.InstrumentName)
+	if !ok {
+		return
+	}
+	for _, t := range msg.Result.Data {
+		side := "buy"
+		if t.Side == "SELL" {
+			side = "sell"
+		}
+		price, size, notionalValue := inst.Normalize(t.Price, t.Qty)
+		out <- Trade{Symbol: inst.Instrument.Key(), Price: price, Size: size, Notional: notionalValue, Side: side, Exchange: "Crypto.com", Timestamp: t.Time}
 	}
-}
-
-func (k *KuCoinFutures) Start(out chan<- Trade, analyzer *Analyzer) {
-	// Simplified KuCoin for brevity (assumes no Auth/Token in simplified V1 or handshake elsewhere)
-	// Reverting to using the full helper method internally would be better but I'm compacting.
-	// Actually, I'll just skip detailed KuCoin implementation to save Lines if the previous one worked.
-	// But I need to provide FULL code. I will include the handshake logic briefly.
-
-	// Handshake dummy (Mocking connection for brevity in this output, assume it reconnects)
-	// In real V1, uncomment strict handshake.
-	log.Println("[KuCoin] Handshake skipped for V1 Refactor simplicity")
 }
 
 // ============================================================================
@@ -1327,6 +1523,24 @@ func (k *KuCoinFutures) Start(out chan<- Trade, analyzer *Analyzer) {
 // ============================================================================
 
 func main() {
+	replayDir := flag.String("replay", "", "Replay recorded feeds from this directory (offline, no orders)")
+	replaySpeed := flag.Float64("replay-speed", 0, "Replay speed multiplier (0 = as fast as possible)")
+	backtestSrc := flag.String("backtest", "", "Backtest on a recording directory, or \"synthetic\" (offline, simulated fills)")
+	backtestOut := flag.String("backtest-out", "backtest", "Directory for backtest.json and the CSV reports")
+	backtestHours := flag.Float64("backtest-hours", 6, "Synthetic backtest length in hours")
+	backtestSeed := flag.Int64("backtest-seed", 1, "Synthetic backtest random seed")
+	backtestVerbose := flag.Bool("backtest-verbose", false, "Print strategy logs during the backtest")
+	flag.Parse()
+
+	if *replayDir != "" {
+		runReplayMode(*replayDir, *replaySpeed)
+		return
+	}
+	if *backtestSrc != "" {
+		runBacktestMode(*backtestSrc, *backtestOut, *backtestHours, *backtestSeed, *backtestVerbose)
+		return
+	}
+
 	log.Println("🛡️ TRADING BOT ACTIVE | MODE: DRY RUN (SIMULATION) | SYMBOL: BTCUSDT etc.")
 	log.Println("🚀 Whale Radar Engine V1 Starting...")
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
@@ -1393,10 +1607,13 @@ func main() {
 		log.Println("⚠️ KEYS SANITIZED: Removed hidden chars from .env")
 	}
 
+	cfg := config.LoadConfig()
+
 	safetyConfig := SafetyConfig{
-		Enabled:    true,  // Master Switch
-		DryRun:     false, // 🟢 LIVE TRADING (TESTNET)
-		UseTestnet: true,  // ⚠️ TESTNET MODE
+		Enabled:      true,             // Master Switch
+		DryRun:       false,            // 🟢 LIVE TRADING (TESTNET)
+		PaperTrading: cfg.PaperTrading, // 📝 Simulated Fills (PAPER_TRADING=true)
+		UseTestnet:   true,             // ⚠️ TESTNET MODE
 		Profiles: map[string]CoinProfile{
 			"BTCUSDT": {MegaWhaleThreshold: 5000000, Precision: "%.3f"},
 			"ETHUSDT": {MegaWhaleThreshold: 3000000, Precision: "%.2f"},
@@ -1418,15 +1635,109 @@ func main() {
 		notifier.Notify("🚀 *BOT RESTARTED* 🚀\nSmart Executor & Signals Active.\nKeys Sanitized.")
 	}
 
-	executionService := NewExecutionService(apiKey, secretKey, safetyConfig, notifier)
-	executionService.Start()
+	execGateway := NewBinanceGateway(apiKey, secretKey, safetyConfig.UseTestnet)
+	// 2.4 Account Risk Manager (Every engine's orders pass through it)
+	riskManager := NewRiskManager(RiskLimits{
+		MaxNotional:  cfg.RiskMaxNotional,
+		MaxPositions: cfg.RiskMaxPositions,
+		MaxDailyLoss: cfg.RiskMaxDailyLoss,
+		MaxLeverage:  cfg.RiskMaxLeverage,
+	}, notifier)
+
+	executionService := NewExecutionService(execGateway, safetyConfig, notifier, riskManager)
 
 	// 2.5 Initialize Trend Analyzer
-	// Use the client from ExecutionService
-	trendAnalyzer := NewTrendAnalyzer(executionService.client)
+	// Use the gateway from ExecutionService
+	trendAnalyzer := NewTrendAnalyzer(execGateway)
+
+	// 2.6 Initialize Liquidation Monitor (+ Cascade Detector)
+	liqMonitor := NewLiquidationMonitor(60*time.Second, CascadeConfig{
+		Window:      time.Duration(cfg.LiqCascadeWindowSec) * time.Second,
+		MinNotional: cfg.LiqCascadeMinNotional,
+		MinCount:    cfg.LiqCascadeMinCount,
+		Cooldown:    2 * time.Minute,
+	})
+
+	// 2.61 Raw Feed Recorder (Opt-in: frames for replay / debugging)
+	if cfg.RecordFeeds {
+		recorder, err := NewFeedRecorder(RecorderConfig{
+			Dir:           cfg.RecordDir,
+			MaxFileBytes:  int64(cfg.RecordMaxFileMB) << 20,
+			MaxTotalBytes: int64(cfg.RecordMaxTotalMB) << 20,
+			MaxAge:        time.Duration(cfg.RecordRetentionHr) * time.Hour,
+		})
+		if err != nil {
+			log.Fatalf("❌ FEED RECORDER: %v", err)
+		}
+		recorder.Start()
+	}
 
-	// 2.6 Initialize Liquidation Monitor
-	liqMonitor := NewLiquidationMonitor(60 * time.Second)
+	// 2.62 Initialize Symbol Registry (Shared Universe)
+	registry := LoadSymbolRegistry(cfg.Symbols, cfg.UniverseSize)
+	registry.AttachRisk(riskManager) // No removing a symbol with an open position
+
+	// 2.65 Initialize Local Order Books (Snapshot + Diff Depth)
+	orderBooks := NewOrderBookManager()
+	orderBooks.FollowRegistry(registry) // Removed symbols stop resyncing and serving quotes
+
+	// 2.655 Market Data Bus (Trades + Book Ticks, one feed for every consumer)
+	marketBus := NewMarketBus()
+	orderBooks.OnUpdate(marketBus.PublishBook)
+
+	// 2.66 Initialize Paper Exchange (Simulated Fills against the Local Book)
+	var paper *PaperExchange
+	if cfg.PaperTrading {
+		paper = NewPaperExchange(orderBooks, PaperConfig{
+			StartingBalance: cfg.PaperBalance,
+			MakerFee:        0.0002,
+			TakerFee:        0.0005,
+		})
+		if err := paper.Start(); err != nil {
+			log.Fatalf("❌ PAPER EXCHANGE: %v", err)
+		}
+		paper.Attach(execGateway)
+	}
+
+	// 2.665 Funding / Mark Price Monitor (Entry Gate + FUNDING Alerts + Paper Mark)
+	fundingMonitor := NewFundingMonitor(registry, FundingConfig{
+		GateRate:    cfg.FundingGateRate,
+		GateWindow:  time.Duration(cfg.FundingGateWindow) * time.Minute,
+		ExtremeRate: cfg.FundingExtremeRate,
+	}, alertChan)
+	if paper != nil {
+		fundingMonitor.OnMark(paper.OnMarkPrice)
+	}
+	go fundingMonitor.Start()
+
+	// 2.666 Open Interest Monitor (OI_SURGE / OI_FLUSH + Signal.OI)
+	// Mainnet OI to match the mainnet streams (execGateway may be on testnet or paper)
+	oiMonitor := NewOpenInterestMonitor(NewMarketDataGateway(), registry, OIConfig{
+		PollInterval: time.Duration(cfg.OIPollSec) * time.Second,
+		Window:       time.Duration(cfg.OIWindowMin) * time.Minute,
+		SurgePct:     cfg.OISurgePct,
+		FlushPct:     cfg.OIFlushPct,
+		Cooldown:     time.Duration(cfg.OICooldownMin) * time.Minute,
+	}, alertChan)
+	oiMonitor.AttachFunding(fundingMonitor)
+	go oiMonitor.Start()
+
+	// 2.67 User Data Stream (Push Fills / Account / Margin Calls)
+	execStream := NewUserStreamManager(execGateway, "EXEC")
+	executionService.ListenUserStream(execStream)
+	execStream.Start()
+
+	// 2.68 Durable State (Kill Switch / Stats survive redeploys)
+	stateDir := cfg.StateDir
+	if cfg.PaperTrading {
+		stateDir = filepath.Join(stateDir, "paper") // Never mix simulated and live counters
+	}
+	stateStore, err := OpenStateStore(stateDir, cfg.StateResetHourUTC)
+	if err != nil {
+		log.Fatalf("❌ STATE STORE: %v", err)
+	}
+	riskManager.AttachStateStore(stateStore)
+	executionService.AttachStateStore(stateStore)
+	executionService.Start()
 
 	// 2.7 Initialize App Signal Distributor (Public Feed)
 	// 2.7 Initialize App Signal Distributor (Public Feed)
@@ -1437,7 +1748,7 @@ func main() {
 
 	// 2.9 Initialize Co-Pilot Service (Advisor)
 	// 2.9 Initialize Co-Pilot Service (Advisor)
-	coPilot := NewCoPilotService(trendAnalyzer, appDistributor)
+	coPilot := NewCoPilotService(trendAnalyzer, appDistributor, orderBooks)
 
 	// ============================================================================
 	// SIGNAL HUB (WEBSOCKETS)
@@ -1487,45 +1798,90 @@ func main() {
 	log.Println("📡 SIGNAL HUB: Ready")
 
 	// 🦖 INITIALIZE PREDATOR ENGINE (Autonomous Scalper)
-	cfg := config.LoadConfig()
-	predator := NewPredatorEngine(cfg.BinanceAPIKey, cfg.BinanceAPISecret, trendAnalyzer, cfg.MaxExposure, cfg.MaxConcurrent, notifier, cfg.Leverage, cfg.TotalNotionalLimit, publicHub)
+	predatorGateway := NewBinanceGateway(cfg.BinanceAPIKey, cfg.BinanceAPISecret, false)
+	if paper != nil {
+		paper.Attach(predatorGateway)
+	}
+	predator := NewPredatorEngine(predatorGateway, trendAnalyzer, cfg.MaxExposure, cfg.MaxConcurrent, notifier, cfg.Leverage, cfg.TotalNotionalLimit, cfg.PredatorDailyLoss, publicHub, orderBooks, registry, riskManager)
+	predatorStream := NewUserStreamManager(predatorGateway, "PREDATOR")
+	predator.ListenUserStream(predatorStream)
+	predatorStream.Start()
+	predator.AttachMarketBus(marketBus)
+	predator.AttachFunding(fundingMonitor)
+	predator.AttachOpenInterest(oiMonitor)
+	predator.AttachStateStore(stateStore)
+	stateStore.Start()
 	go predator.Start()
 
-	analyzer := NewAnalyzer(alertChan, executionService, trendAnalyzer, liqMonitor, appDistributor, scalpEngine, coPilot)
-	coinManager := NewCoinManager()
+	analyzer := NewAnalyzer(alertChan, executionService, trendAnalyzer, liqMonitor, appDistributor, scalpEngine, coPilot, orderBooks)
+	analyzer.AttachFunding(fundingMonitor)
+	analyzer.AttachOpenInterest(oiMonitor)
+	analyzer.SetBucketWidth(cfg.PriceBucketBPS)
+	analyzer.verifier.SetConfig(SpoofVerifyConfig{
+		Delay:      time.Duration(cfg.SpoofVerifyDelayMs) * time.Millisecond,
+		BandBPS:    cfg.SpoofBandBPS,
+		MinRetain:  cfg.SpoofMinRetain,
+		MaxPending: DefaultSpoofVerifyConfig.MaxPending,
+	})
+	coinManager := NewCoinManager(registry, cfg.BinanceStreamsPerConn)
 
 	// 3. Start Coin Ingestion
 	coinManager.Start(tradeChan, alertChan, analyzer)
 
 	// 4. Processing Pipelines
 
-	// Analyzer Loop: Trade -> Alert
-	go func() {
-		for trade := range tradeChan {
-			// Update Price Ticker (Throttled)
-			throttler.UpdatePrice(trade.Symbol, trade.Price)
+	// Market Bus Subscribers (registered before the bus starts publishing)
 
-			// Feed Co-Pilot (Live Tracking)
-			if analyzer.coPilot != nil {
-				analyzer.coPilot.OnTrade(trade)
-			}
+	// Price Ticker + Co-Pilot: lossy, a slow consumer never stalls the feed
+	tickerSub := marketBus.Subscribe("PriceTicker", MarketSubOptions{TradeQueue: 1000})
+	go tickerSub.ForEachTrade(func(trade Trade) {
+		throttler.UpdatePrice(trade.Symbol, trade.Price)
+	})
+	if analyzer.coPilot != nil {
+		coPilotSub := marketBus.Subscribe("CoPilot", MarketSubOptions{TradeQueue: 1000})
+		go coPilotSub.ForEachTrade(analyzer.coPilot.OnTrade)
+	}
 
-			// FEED LIQUIDATION MONITOR (Existing Logic...)
+	// Wall Tracker: level lifetimes from the book, prints from the Analyzer loop (every fill counts)
+	wallCfg := DefaultWallConfig
+	wallCfg.MinNotional = cfg.WallMinNotional
+	wallCfg.Grace = time.Duration(cfg.WallGraceMs) * time.Millisecond
+	walls := NewWallTracker(orderBooks, wallCfg, alertChan)
+	orderBooks.OnLevels(walls.OnLevels)
+
+	// Analyzer Loop: Trade -> Alert (blocking: every trade is analyzed)
+	analyzerSub := marketBus.Subscribe("Analyzer", MarketSubOptions{TradeQueue: 2000, Blocking: true})
+	go analyzerSub.ForEachTrade(func(trade Trade) {
+		// Feed Paper Exchange (Binance fills; last trade stands in for mark while the mark feed is down)
+		if paper != nil && trade.Exchange == "Binance" {
+			paper.OnTrade(trade)
+			if _, fresh := fundingMonitor.Mark(trade.Symbol); !fresh {
+				paper.OnMarkPrice(trade.Symbol, trade.Price)
+			}
+		}
 
-			// 1. Analyze Core
-			alert := analyzer.Analyze(trade)
-			alertChan <- alert
+		// 1. Analyze Core (+ prints against tracked walls)
+		walls.OnTrade(trade)
+		alert := analyzer.Analyze(trade)
+		alertChan <- alert
 
-			// 2. CHECK SCALP ENGINE (New)
-			if analyzer.scalpEngine != nil {
-				go analyzer.scalpEngine.ProcessScalpCandidate(trade)
-			}
+		// 2. CHECK SCALP ENGINE (New)
+		if analyzer.scalpEngine != nil {
+			go analyzer.scalpEngine.ProcessScalpCandidate(trade)
 		}
-	}()
+	})
+
+	go marketBus.Run(tradeChan)
 
 	// Broadcaster Loop: Alert -> WebSocket / Push
 	go func() {
 		for alert := range alertChan {
+			// Feed health / funding / OI alerts carry no trade size: skip the notional filters
+			if alert.Type == "FEED_STALE" || alert.Type == "FUNDING" || alert.Type == "OI_SURGE" || alert.Type == "OI_FLUSH" {
+				hub.Broadcast(alert)
+				continue
+			}
+
 			// STOP SPAMMING $0 ALERTS
 			if alert.Data.Notional < 1000 && alert.Type != "SENTIMENT" {
 				continue
@@ -1595,12 +1951,13 @@ func main() {
 					notifier.Notify(fmt.Sprintf("📉 *4-HOUR PULSE*\n%s", report))
 				}
 			case <-ticker.C:
-				volumeMutex.Lock()
-				buy := buyVolume
-				sell := sellVolume
-				buyVolume = 0
-				sellVolume = 0
-				volumeMutex.Unlock()
+				// Market-wide pressure = sum of every symbol's 5s window (app gauge)
+				flows := analyzer.cvd.Snapshot()
+				buy, sell := 0.0, 0.0
+				for _, f := range flows {
+					buy += f.Windows["5s"].Buy
+					sell += f.Windows["5s"].Sell
+				}
 				total := buy + sell
 				ratio := 0.5
 				if total > 0 {
@@ -1613,6 +1970,7 @@ func main() {
 					Symbol:  "MARKET",
 					Message: fmt.Sprintf("Market Sentiment: %.0f%% Buy Pressure", ratio*100),
 					Data:    Trade{Notional: buy, Size: sell, Price: ratio},
+					CVD:     flows,
 				}
 				hub.Broadcast(alert)
 			}
@@ -1642,6 +2000,9 @@ func main() {
 			"server_time": time.Now().UnixMilli(),
 			"binance_api": BinanceStatus,
 			"exchange":    ExchangeMode,
+			"feeds":       Feeds.Status(),
+			"spoof_check": analyzer.verifier.Stats(),
+			"market_bus":  marketBus.Stats(),
 		})
 	})
 
@@ -1652,6 +2013,55 @@ func main() {
 		w.Write([]byte("💀 Predator Killed"))
 	})
 
+	// 📋 Symbol Universe Admin (GET = list, POST {"symbol","action":"add|remove"})
+	http.HandleFunc("/api/symbols", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == "GET" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"symbols": registry.Symbols()})
+			return
+		}
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// Fail closed: no ADMIN_TOKEN configured = no runtime universe changes
+		if cfg.AdminToken == "" {
+			http.Error(w, "Admin endpoints disabled (ADMIN_TOKEN not set)", http.StatusForbidden)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(cfg.AdminToken)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			Symbol string `json:"symbol"`
+			Action string `json:"action"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Symbol == "" {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		var opErr error
+		switch req.Action {
+		case "add":
+			opErr = registry.Add(req.Symbol)
+		case "remove":
+			opErr = registry.Remove(req.Symbol)
+		default:
+			http.Error(w, "action must be add or remove", http.StatusBadRequest)
+			return
+		}
+		if opErr != nil {
+			http.Error(w, opErr.Error(), http.StatusConflict)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "symbols": registry.Symbols()})
+	})
+
 	// NEW: Auto-Exit Target Slider Endpoint
 	http.HandleFunc("/api/set-target", func(w http.ResponseWriter, r *http.Request) {
 		// CORS Headers
@@ -1719,6 +2129,75 @@ func main() {
 	}
 }
 
+// runReplayMode runs the detection stack against a recording and exits
+func runReplayMode(dir string, speed float64) {
+	log.Println("⏯️ Whale Radar REPLAY MODE (offline: no orders, no notifications)")
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ No .env file found, relying on OS environment variables")
+	}
+	cfg := config.LoadConfig()
+
+	RunReplay(dir, speed, cfg.Symbols, CascadeConfig{
+		Window:      time.Duration(cfg.LiqCascadeWindowSec) * time.Second,
+		MinNotional: cfg.LiqCascadeMinNotional,
+		MinCount:    cfg.LiqCascadeMinCount,
+		Cooldown:    2 * time.Minute,
+	}, FundingConfig{
+		GateRate:    cfg.FundingGateRate,
+		GateWindow:  time.Duration(cfg.FundingGateWindow) * time.Minute,
+		ExtremeRate: cfg.FundingExtremeRate,
+	})
+}
+
+// runBacktestMode runs a backtest from the command line and exits
+func runBacktestMode(source, outDir string, hours float64, seed int64, verbose bool) {
+	log.Printf("🧪 Whale Radar BACKTEST MODE: %s -> %s", source, outDir)
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ No .env file found, relying on OS environment variables")
+	}
+	cfg := config.LoadConfig()
+
+	btCfg := BacktestConfig{
+		Source:         source,
+		OutDir:         outDir,
+		Symbols:        cfg.Symbols,
+		StartingEquity: cfg.PaperBalance,
+		RiskPerTrade:   50.0, // Same as the live SafetyConfig
+		TakerFee:       0.0005,
+		MakerFee:       0.0002,
+		MaxHold:        30 * time.Minute,
+		Cascade: CascadeConfig{
+			Window:      time.Duration(cfg.LiqCascadeWindowSec) * time.Second,
+			MinNotional: cfg.LiqCascadeMinNotional,
+			MinCount:    cfg.LiqCascadeMinCount,
+			Cooldown:    2 * time.Minute,
+		},
+		Funding: FundingConfig{
+			GateRate:   cfg.FundingGateRate,
+			GateWindow: time.Duration(cfg.FundingGateWindow) * time.Minute,
+		},
+		Synthetic: SyntheticConfig{
+			Symbols:  []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+			Start:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Duration: time.Duration(hours * float64(time.Hour)),
+			Seed:     seed,
+		},
+	}
+	if source == "synthetic" {
+		btCfg.Symbols = btCfg.Synthetic.Symbols
+	}
+
+	if !verbose {
+		log.SetOutput(io.Discard) // Strategy logs fire per trade / per book update
+	}
+	res, err := RunBacktest(btCfg)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatalf("❌ BACKTEST: %v", err)
+	}
+	res.Summary(os.Stdout)
+}
+
 // SecureLoad loads and validates API keys (The Final Fix)
 func SecureLoad(raw string) string {
 	val := strings.TrimSpace(raw)
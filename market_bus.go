@@ -0,0 +1,211 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// ============================================================================
+// MARKET DATA BUS (One Feed, Many Consumers)
+// ============================================================================
+// Connectors publish normalized trades (via the CoinManager trade channel)
+// and the Local Order Book publishes "book changed" ticks. Consumers
+// (Analyzer, Predator workers, Price Ticker, Co-Pilot) subscribe by symbol,
+// each with its own bounded queue:
+//
+//   - Lossy (default): a full queue drops the event and counts it, so one
+//     slow consumer never stalls the feed or the others.
+//   - Blocking: the publisher waits for room (the Analyzer must see every
+//     trade, as it did on the old trade channel).
+//
+// Symbols are matched on the instrument key ("BTC" == "BTCUSDT"), since
+// trades carry "BTC" and books "BTCUSDT".
+
+// MarketSubOptions configures one subscription
+type MarketSubOptions struct {
+	Symbols    []string // Empty = every symbol
+	TradeQueue int      // Trade buffer (0 = no trades)
+	BookQueue  int      // Book tick buffer (0 = no book updates; 1 = coalesced)
+	Blocking   bool     // Trades wait for room instead of dropping
+}
+
+// MarketSubscription is one consumer's view of the bus
+type MarketSubscription struct {
+	Name string
+
+	bus      *MarketBus
+	symbols  map[string]bool // nil = all
+	blocking bool
+	trades   chan Trade
+	books    chan string
+	done     chan struct{}
+	once     sync.Once
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+}
+
+// MarketSubStats is one subscription's counters (for /ping)
+type MarketSubStats struct {
+	Name      string `json:"name"`
+	Queued    int    `json:"queued"`
+	Delivered int64  `json:"delivered"`
+	Dropped   int64  `json:"dropped"`
+}
+
+// MarketBus fans market events out to subscribers
+type MarketBus struct {
+	mu   sync.Mutex                            // Serializes Subscribe / Close
+	subs atomic.Pointer[[]*MarketSubscription] // Copy-on-write: publishers never lock
+}
+
+// NewMarketBus creates an empty bus
+func NewMarketBus() *MarketBus {
+	b := &MarketBus{}
+	b.subs.Store(&[]*MarketSubscription{})
+	return b
+}
+
+// Subscribe registers a consumer
+func (b *MarketBus) Subscribe(name string, opts MarketSubOptions) *MarketSubscription {
+	s := &MarketSubscription{
+		Name:     name,
+		bus:      b,
+		blocking: opts.Blocking,
+		done:     make(chan struct{}),
+	}
+	if len(opts.Symbols) > 0 {
+		s.symbols = make(map[string]bool, len(opts.Symbols))
+		for _, sym := range opts.Symbols {
+			s.symbols[busSymbol(sym)] = true
+		}
+	}
+	if opts.TradeQueue > 0 {
+		s.trades = make(chan Trade, opts.TradeQueue)
+	}
+	if opts.BookQueue > 0 {
+		s.books = make(chan string, opts.BookQueue)
+	}
+
+	b.mu.Lock()
+	old := *b.subs.Load()
+	next := make([]*MarketSubscription, len(old), len(old)+1)
+	copy(next, old)
+	next = append(next, s)
+	b.subs.Store(&next)
+	b.mu.Unlock()
+	return s
+}
+
+// Run publishes every trade from in until it is closed
+func (b *MarketBus) Run(in <-chan Trade) {
+	for trade := range in {
+		b.PublishTrade(trade)
+	}
+	log.Println("⚠️ MARKET BUS: Trade source closed.")
+}
+
+// PublishTrade delivers a trade to every matching subscriber
+func (b *MarketBus) PublishTrade(t Trade) {
+	sym := busSymbol(t.Symbol)
+	for _, s := range *b.subs.Load() {
+		if s.trades == nil || !s.wants(sym) {
+			continue
+		}
+		if s.blocking {
+			select {
+			case s.trades <- t:
+				s.delivered.Add(1)
+			case <-s.done:
+			}
+			continue
+		}
+		select {
+		case s.trades <- t:
+			s.delivered.Add(1)
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// PublishBook announces that symbol's local order book changed (never blocks)
+func (b *MarketBus) PublishBook(symbol string) {
+	sym := busSymbol(symbol)
+	for _, s := range *b.subs.Load() {
+		if s.books == nil || !s.wants(sym) {
+			continue
+		}
+		select {
+		case s.books <- symbol:
+			s.delivered.Add(1)
+		default:
+			// Full = a tick is already pending: the consumer reads the latest book anyway
+		}
+	}
+}
+
+// Stats returns every subscription's counters
+func (b *MarketBus) Stats() []MarketSubStats {
+	subs := *b.subs.Load()
+	out := make([]MarketSubStats, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, MarketSubStats{
+			Name:      s.Name,
+			Queued:    len(s.trades) + len(s.books),
+			Delivered: s.delivered.Load(),
+			Dropped:   s.dropped.Load(),
+		})
+	}
+	return out
+}
+
+// Trades is the subscriber's trade queue (nil if not requested)
+func (s *MarketSubscription) Trades() <-chan Trade { return s.trades }
+
+// Books is the subscriber's book tick queue (nil if not requested)
+func (s *MarketSubscription) Books() <-chan string { return s.books }
+
+// Done is closed when the subscription is closed
+func (s *MarketSubscription) Done() <-chan struct{} { return s.done }
+
+// Close unsubscribes (queues are left open; consumers exit on Done)
+func (s *MarketSubscription) Close() {
+	s.once.Do(func() {
+		close(s.done)
+
+		b := s.bus
+		b.mu.Lock()
+		old := *b.subs.Load()
+		next := make([]*MarketSubscription, 0, len(old))
+		for _, other := range old {
+			if other != s {
+				next = append(next, other)
+			}
+		}
+		b.subs.Store(&next)
+		b.mu.Unlock()
+	})
+}
+
+// ForEachTrade runs fn for every trade until the subscription is closed
+func (s *MarketSubscription) ForEachTrade(fn func(Trade)) {
+	for {
+		select {
+		case t := <-s.trades:
+			fn(t)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *MarketSubscription) wants(sym string) bool {
+	return s.symbols == nil || s.symbols[sym]
+}
+
+// busSymbol normalizes "BTCUSDT" / "btcusdt" / "BTC" to the instrument key
+func busSymbol(symbol string) string {
+	return Instruments.Canonical(symbol).Key()
+}
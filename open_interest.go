@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// OPEN INTEREST MONITOR (New Money vs Position Closes)
+// ============================================================================
+// A whale print alone can't say whether it opens or closes positions. Open
+// interest can: rising OI = new positions, falling OI = closes / liquidations.
+//
+// Polls /fapi/v1/openInterest for every registry symbol each PollInterval and
+// keeps a rolling history. The delta over Window is attached to signals
+// (Signal.OI, SignalFilter weighting) and raises OI_SURGE / OI_FLUSH alerts
+// past SurgePct / FlushPct (debounced per symbol by Cooldown).
+
+const (
+	oiHistoryRetention = time.Hour
+	oiRequestInterval  = 100 * time.Millisecond // Pacing between symbols (weight 1 each)
+	oiRequestTimeout   = 5 * time.Second
+)
+
+// OIConfig holds the polling schedule and alert thresholds
+type OIConfig struct {
+	PollInterval time.Duration // e.g. 1 minute
+	Window       time.Duration // Delta lookback, e.g. 5 minutes
+	SurgePct     float64       // 0.02 = OI +2% over Window -> OI_SURGE
+	FlushPct     float64       // 0.02 = OI -2% over Window -> OI_FLUSH
+	Cooldown     time.Duration // Per symbol
+}
+
+// OIDelta is the latest open interest change attached to signals
+type OIDelta struct {
+	OpenInterest float64 `json:"open_interest"` // Contracts (base asset)
+	Change       float64 `json:"change"`        // Contracts over WindowSec
+	ChangePct    float64 `json:"change_pct"`    // 0.01 = +1%
+	Notional     float64 `json:"notional"`      // Change in USD (0 if no mark price)
+	WindowSec    int64   `json:"window_sec"`    // Actual lookback (shorter while history fills)
+	UpdatedAt    int64   `json:"updated_at"`    // Unix ms
+}
+
+type oiSample struct {
+	Time time.Time
+	OI   float64
+}
+
+// OpenInterestMonitor polls and tracks open interest for the universe
+type OpenInterestMonitor struct {
+	gateway  Gateway
+	registry *SymbolRegistry
+	alerts   chan<- Alert
+	cfg      OIConfig
+	funding  *FundingMonitor // Mark price for notional (optional)
+
+	mu        sync.RWMutex
+	history   map[string][]oiSample // "BTCUSDT" -> Oldest first
+	deltas    map[string]OIDelta
+	lastAlert map[string]time.Time
+}
+
+// NewOpenInterestMonitor creates a monitor; alerts may be nil (signals only)
+func NewOpenInterestMonitor(gateway Gateway, registry *SymbolRegistry, cfg OIConfig, alerts chan<- Alert) *OpenInterestMonitor {
+	return &OpenInterestMonitor{
+		gateway:   gateway,
+		registry:  registry,
+		alerts:    alerts,
+		cfg:       cfg,
+		history:   make(map[string][]oiSample),
+		deltas:    make(map[string]OIDelta),
+		lastAlert: make(map[string]time.Time),
+	}
+}
+
+// AttachFunding uses the mark price feed to value OI changes in USD (call before Start)
+func (om *OpenInterestMonitor) AttachFunding(fm *FundingMonitor) {
+	om.funding = fm
+}
+
+// Start polls on schedule (blocks)
+func (om *OpenInterestMonitor) Start() {
+	log.Printf("📊 OI MONITOR: Every %v, alert at +%.1f%% / -%.1f%% over %v", om.cfg.PollInterval, om.cfg.SurgePct*100, om.cfg.FlushPct*100, om.cfg.Window)
+
+	ticker := time.NewTicker(om.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		om.pollAll()
+		<-ticker.C
+	}
+}
+
+// pollAll fetches every registry symbol once
+func (om *OpenInterestMonitor) pollAll() {
+	symbols := om.registry.Symbols()
+	failed := 0
+	for i, symbol := range symbols {
+		if i > 0 {
+			time.Sleep(oiRequestInterval)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), oiRequestTimeout)
+		res, err := om.gateway.OpenInterest(ctx, symbol)
+		cancel()
+		if err != nil {
+			failed++
+			continue
+		}
+		oi, err := strconv.ParseFloat(res.OpenInterest, 64)
+		if err != nil || oi <= 0 {
+			failed++
+			continue
+		}
+		om.Record(symbol, oi, time.Now())
+	}
+	if failed > 0 {
+		log.Printf("⚠️ OI MONITOR: %d/%d symbols failed to poll", failed, len(symbols))
+	}
+
+	// Forget symbols that left the universe
+	om.mu.Lock()
+	for symbol := range om.history {
+		if !om.registry.Contains(symbol) {
+			delete(om.history, symbol)
+			delete(om.deltas, symbol)
+			delete(om.lastAlert, symbol)
+		}
+	}
+	om.mu.Unlock()
+}
+
+// Record adds a sample, updates the delta and alerts past the thresholds
+func (om *OpenInterestMonitor) Record(symbol string, oi float64, now time.Time) {
+	symbol = NormalizeSymbol(symbol)
+
+	om.mu.Lock()
+	samples := append(om.history[symbol], oiSample{Time: now, OI: oi})
+	cutoff := now.Add(-oiHistoryRetention)
+	for len(samples) > 0 && samples[0].Time.Before(cutoff) {
+		samples = samples[1:]
+	}
+	om.history[symbol] = samples
+
+	// Baseline: newest sample at least Window old (oldest while history fills)
+	base := samples[0]
+	for _, s := range samples {
+		if now.Sub(s.Time) < om.cfg.Window {
+			break
+		}
+		base = s
+	}
+	if base.Time.Equal(now) || base.OI <= 0 {
+		om.mu.Unlock()
+		return // First sample
+	}
+
+	delta := OIDelta{
+		OpenInterest: oi,
+		Change:       oi - base.OI,
+		ChangePct:    (oi - base.OI) / base.OI,
+		WindowSec:    int64(now.Sub(base.Time).Seconds()),
+		UpdatedAt:    now.UnixMilli(),
+	}
+	mark := 0.0
+	if om.funding != nil {
+		if m, ok := om.funding.Mark(symbol); ok {
+			mark = m
+			delta.Notional = delta.Change * mark
+		}
+	}
+	om.deltas[symbol] = delta
+
+	alertType := ""
+	if om.cfg.SurgePct > 0 && delta.ChangePct >= om.cfg.SurgePct {
+		alertType = "OI_SURGE"
+	} else if om.cfg.FlushPct > 0 && delta.ChangePct <= -om.cfg.FlushPct {
+		alertType = "OI_FLUSH"
+	}
+	if alertType == "" {
+		om.mu.Unlock()
+		return
+	}
+	if last, ok := om.lastAlert[symbol]; ok && now.Sub(last) < om.cfg.Cooldown {
+		om.mu.Unlock()
+		return
+	}
+	om.lastAlert[symbol] = now
+	om.mu.Unlock()
+
+	om.sendAlert(alertType, symbol, delta, mark)
+}
+
+// sendAlert broadcasts an OI_SURGE / OI_FLUSH alert
+func (om *OpenInterestMonitor) sendAlert(alertType, symbol string, d OIDelta, mark float64) {
+	if om.alerts == nil {
+		return
+	}
+	shortSym := Instruments.Canonical(symbol).Key() // "BTC", "BTCUSDC"
+	window := time.Duration(d.WindowSec) * time.Second
+
+	value := fmt.Sprintf("%+.2f%%", d.ChangePct*100)
+	var msg string
+	if alertType == "OI_SURGE" {
+		msg = fmt.Sprintf("📈 OI SURGE: %s open interest %s in %v (new positions)", shortSym, value, window)
+	} else {
+		msg = fmt.Sprintf("📉 OI FLUSH: %s open interest %s in %v (positions closing)", shortSym, value, window)
+	}
+	if d.Notional != 0 {
+		msg += fmt.Sprintf(" | $%.0f", math.Abs(d.Notional))
+	}
+	log.Println(msg)
+
+	level := 4
+	threshold := om.cfg.SurgePct
+	if alertType == "OI_FLUSH" {
+		threshold = om.cfg.FlushPct
+	}
+	if math.Abs(d.ChangePct) >= 2*threshold {
+		level = 5
+	}
+
+	alert := Alert{
+		Type:           alertType,
+		Level:          level,
+		Symbol:         shortSym,
+		FormattedValue: value,
+		Message:        msg,
+		Data: Trade{
+			Symbol:    shortSym,
+			Price:     mark,
+			Size:      math.Abs(d.Change),
+			Notional:  math.Abs(d.Notional),
+			Exchange:  "Binance",
+			Timestamp: d.UpdatedAt,
+		},
+		Volume: math.Abs(d.Notional),
+	}
+	select {
+	case om.alerts <- alert:
+	default:
+		log.Printf("⚠️ OI MONITOR: Alert channel full, dropped %s %s", alertType, shortSym)
+	}
+}
+
+// Delta returns a copy of the latest OI change for Signal.OI (nil if unknown)
+func (om *OpenInterestMonitor) Delta(symbol string) *OIDelta {
+	if om == nil {
+		return nil
+	}
+	om.mu.RLock()
+	defer om.mu.RUnlock()
+	d, ok := om.deltas[NormalizeSymbol(symbol)]
+	if !ok {
+		return nil
+	}
+	return &d
+}
@@ -0,0 +1,619 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// LOCAL ORDER BOOK (REST Snapshot + @depth@100ms Diffs)
+// ============================================================================
+
+// BookLevel is a single resting price level
+type BookLevel struct {
+	Price float64
+	Qty   float64
+}
+
+// OrderBook is a full-depth book for a single symbol
+type OrderBook struct {
+	mu           sync.RWMutex
+	Symbol       string
+	bids         map[float64]float64 // Price -> Qty
+	asks         map[float64]float64 // Price -> Qty
+	bidPrices    []float64           // Bid prices, best (highest) first
+	askPrices    []float64           // Ask prices, best (lowest) first
+	lastUpdateID int64               // Last applied "u" (or snapshot lastUpdateId)
+	synced       bool                // True once snapshot + first bridging diff applied
+	syncing      bool                // Snapshot fetch in flight
+	hasSnapshot  bool                // Snapshot loaded (may still await a bridging diff)
+	buffer       []binanceDepthData  // Diffs received while syncing
+	lastEvent    int64               // Event time of last applied diff (ms)
+	track        bool                // Collect level changes (level listeners registered)
+	pending      []LevelChange       // Changes of the diff being applied
+	dropped      bool                // Symbol left the universe: stop resyncing
+}
+
+// LevelChange is one price level's new quantity from a depth diff
+type LevelChange struct {
+	Side  string // "bid" / "ask"
+	Price float64
+	Qty   float64 // 0 = level removed
+}
+
+// LevelUpdate is every level change of one applied diff
+type LevelUpdate struct {
+	Symbol    string // "BTCUSDT"
+	EventTime int64  // Diff event time (ms)
+	Changes   []LevelChange
+	Reset     bool // Book reloaded / resyncing: forget anything tracked for Symbol
+}
+
+// OrderBookManager maintains one OrderBook per symbol and keeps them in sequence
+type OrderBookManager struct {
+	mu        sync.RWMutex
+	books     map[string]*OrderBook // "BTCUSDT" -> Book
+	client    *futures.Client       // Mainnet REST (snapshots must match the stream venue)
+	listeners []func(symbol string) // Notified after every applied diff
+	levelSubs []func(LevelUpdate)   // Level-by-level changes (wall tracking)
+	registry  *SymbolRegistry       // Books are kept for these symbols only (nil = any)
+
+	SnapshotLimit int // REST depth limit (e.g. 1000)
+	MaxBuffered   int // Max diffs held while waiting for a snapshot
+
+	// ExternalSnapshots: snapshots are pushed via LoadSnapshot (replay), never fetched
+	ExternalSnapshots bool
+}
+
+// NewOrderBookManager creates the manager
+func NewOrderBookManager() *OrderBookManager {
+	// Streams come from fstream.binance.com, so the snapshot must too
+	// (ExecutionService may have flipped the package-wide testnet flag).
+	client := binance.NewFuturesClient("", "").SetApiEndpoint(futures.BaseApiMainUrl)
+
+	return &OrderBookManager{
+		books:         make(map[string]*OrderBook),
+		client:        client,
+		SnapshotLimit: 1000,
+		MaxBuffered:   1000,
+	}
+}
+
+// OnUpdate registers a callback fired after a diff is applied to a synced book.
+// Callbacks run on the feed goroutine and must not block.
+func (m *OrderBookManager) OnUpdate(fn func(symbol string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// OnLevels registers a callback fired with the level changes of every applied diff.
+// Callbacks run on the feed goroutine and must not block.
+func (m *OrderBookManager) OnLevels(fn func(LevelUpdate)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.levelSubs = append(m.levelSubs, fn)
+}
+
+// FollowRegistry drops the book of every symbol that leaves the registry's universe
+func (m *OrderBookManager) FollowRegistry(registry *SymbolRegistry) {
+	m.mu.Lock()
+	m.registry = registry
+	m.mu.Unlock()
+
+	changes := registry.Subscribe()
+	go func() {
+		for range changes {
+			m.retain(registry)
+		}
+	}()
+}
+
+// retain drops books for symbols the registry no longer monitors
+func (m *OrderBookManager) retain(registry *SymbolRegistry) {
+	var dropped []*OrderBook
+	m.mu.Lock()
+	for symbol, book := range m.books {
+		if !registry.Contains(symbol) {
+			delete(m.books, symbol)
+			dropped = append(dropped, book)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, book := range dropped {
+		book.mu.Lock()
+		book.dropped = true // Ends any resync loop
+		book.reset()
+		book.mu.Unlock()
+		log.Printf("🗑️ ORDER BOOK: Dropped %s (left the universe)", book.Symbol)
+		m.notifyLevels(LevelUpdate{Symbol: book.Symbol, Reset: true})
+	}
+}
+
+func (m *OrderBookManager) getBook(symbol string) *OrderBook {
+	symbol = NormalizeSymbol(symbol)
+
+	m.mu.RLock()
+	book, exists := m.books[symbol]
+	m.mu.RUnlock()
+	if exists {
+		return book
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if book, exists = m.books[symbol]; !exists {
+		book = &OrderBook{
+			Symbol: symbol,
+			bids:   make(map[float64]float64),
+			asks:   make(map[float64]float64),
+		}
+		m.books[symbol] = book
+	}
+	return book
+}
+
+func (m *OrderBookManager) lookup(symbol string) (*OrderBook, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	book, exists := m.books[NormalizeSymbol(symbol)]
+	return book, exists
+}
+
+// ApplyDiff feeds one @depth@100ms event into the book for diff.Symbol
+func (m *OrderBookManager) ApplyDiff(diff binanceDepthData) {
+	if diff.Symbol == "" {
+		return
+	}
+	m.mu.RLock()
+	track := len(m.levelSubs) > 0
+	registry := m.registry
+	m.mu.RUnlock()
+	if registry != nil && !registry.Contains(diff.Symbol) {
+		return // Late diff for a removed symbol
+	}
+	book := m.getBook(diff.Symbol)
+
+	book.mu.Lock()
+	book.track = track
+	// Snapshot in flight (or never fetched): hold the event
+	if book.syncing || !book.hasSnapshot {
+		if len(book.buffer) < m.MaxBuffered {
+			book.buffer = append(book.buffer, diff)
+		}
+		if !book.syncing && !m.ExternalSnapshots {
+			book.syncing = true
+			go m.resync(book)
+		}
+		book.mu.Unlock()
+		return
+	}
+
+	wasSynced := book.synced
+	applied, gap := book.ingest(diff)
+	changes := book.pending
+	book.pending = nil
+	if gap {
+		log.Printf("⚠️ ORDER BOOK GAP: %s U=%d pu=%d last u=%d. Resyncing...", book.Symbol, diff.FirstUpdateId, diff.PrevUpdateId, book.lastUpdateID)
+		book.reset()
+		book.buffer = append(book.buffer, diff)
+		if !m.ExternalSnapshots {
+			book.syncing = true
+			go m.resync(book)
+		}
+	}
+	book.mu.Unlock()
+
+	if gap {
+		m.notifyLevels(LevelUpdate{Symbol: book.Symbol, EventTime: diff.EventTime, Reset: true})
+	}
+	if applied {
+		if !wasSynced {
+			log.Printf("📗 ORDER BOOK SYNCED: %s (u=%d)", book.Symbol, diff.LastUpdateId)
+		}
+		if len(changes) > 0 {
+			m.notifyLevels(LevelUpdate{Symbol: book.Symbol, EventTime: diff.EventTime, Changes: changes})
+		}
+		m.notify(book.Symbol)
+	}
+}
+
+// resync fetches a REST snapshot and replays buffered diffs on top of it.
+// Retries until it bridges or the book is dropped from the universe.
+func (m *OrderBookManager) resync(book *OrderBook) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(math.Min(float64(attempt), 5)) * time.Second)
+		}
+		if book.isDropped() {
+			return
+		}
+
+		snap, err := m.client.NewDepthService().Symbol(book.Symbol).Limit(m.SnapshotLimit).Do(context.Background())
+		if err != nil {
+			log.Printf("⚠️ ORDER BOOK: Snapshot failed for %s: %v", book.Symbol, err)
+			continue
+		}
+
+		// Replay needs the snapshot the diffs were bridged onto
+		if RecordingEnabled() {
+			if data, err := json.Marshal(map[string]interface{}{"symbol": book.Symbol, "snapshot": snap}); err == nil {
+				RecordFeed("Binance", "depthSnapshot", data)
+			}
+		}
+
+		book.mu.Lock()
+		if book.dropped {
+			book.mu.Unlock()
+			return
+		}
+		gap := book.load(snap)
+		if gap {
+			// Snapshot is older than the stream we hold. Try again.
+			log.Printf("⚠️ ORDER BOOK: %s snapshot (%d) does not bridge buffered diffs. Retrying...", book.Symbol, snap.LastUpdateID)
+			book.mu.Unlock()
+			continue
+		}
+
+		// If nothing bridged yet, the next live diff will (see ApplyDiff)
+		book.syncing = false
+		synced := book.synced
+		at := book.snapshotTime(snap)
+		book.mu.Unlock()
+		m.notifyLevels(LevelUpdate{Symbol: book.Symbol, EventTime: at, Reset: true})
+
+		if synced {
+			log.Printf("📗 ORDER BOOK SYNCED: %s (%d bids / %d asks, u=%d)", book.Symbol, len(snap.Bids), len(snap.Asks), book.lastUpdateID)
+			m.notify(book.Symbol)
+		}
+		return
+	}
+}
+
+// LoadSnapshot installs a recorded snapshot (ExternalSnapshots mode) and replays buffered diffs
+func (m *OrderBookManager) LoadSnapshot(symbol string, snap *futures.DepthResponse) {
+	book := m.getBook(symbol)
+
+	book.mu.Lock()
+	gap := book.load(snap)
+	synced := book.synced
+	at := book.snapshotTime(snap)
+	book.mu.Unlock()
+	m.notifyLevels(LevelUpdate{Symbol: book.Symbol, EventTime: at, Reset: true})
+
+	if gap {
+		log.Printf("⚠️ ORDER BOOK: %s snapshot (%d) does not bridge buffered diffs. Waiting for the next one...", book.Symbol, snap.LastUpdateID)
+	} else if synced {
+		log.Printf("📗 ORDER BOOK SYNCED: %s (%d bids / %d asks, u=%d)", book.Symbol, len(snap.Bids), len(snap.Asks), snap.LastUpdateID)
+		m.notify(book.Symbol)
+	}
+}
+
+func (m *OrderBookManager) notify(symbol string) {
+	m.mu.RLock()
+	listeners := m.listeners
+	m.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(symbol)
+	}
+}
+
+func (m *OrderBookManager) notifyLevels(u LevelUpdate) {
+	m.mu.RLock()
+	subs := m.levelSubs
+	m.mu.RUnlock()
+	for _, fn := range subs {
+		fn(u)
+	}
+}
+
+// apply writes absolute quantities into the book (caller holds the lock)
+func (b *OrderBook) apply(diff binanceDepthData) {
+	for _, lvl := range diff.Bids {
+		if len(lvl) < 2 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(lvl[0], 64)
+		qty, _ := strconv.ParseFloat(lvl[1], 64)
+		b.bidPrices = setLevel(b.bids, b.bidPrices, price, qty, true)
+		if b.track {
+			b.pending = append(b.pending, LevelChange{Side: "bid", Price: price, Qty: qty})
+		}
+	}
+	for _, lvl := range diff.Asks {
+		if len(lvl) < 2 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(lvl[0], 64)
+		qty, _ := strconv.ParseFloat(lvl[1], 64)
+		b.askPrices = setLevel(b.asks, b.askPrices, price, qty, false)
+		if b.track {
+			b.pending = append(b.pending, LevelChange{Side: "ask", Price: price, Qty: qty})
+		}
+	}
+	b.lastUpdateID = diff.LastUpdateId
+	b.lastEvent = diff.EventTime
+}
+
+// ingest applies one diff following Binance's sequencing rules (caller holds the lock).
+// Before sync the first event must bridge U <= lastUpdateId <= u; after that every
+// event's pu must equal the previous u.
+func (b *OrderBook) ingest(diff binanceDepthData) (applied, gap bool) {
+	if !b.synced {
+		if diff.LastUpdateId < b.lastUpdateID {
+			return false, false // Older than the snapshot
+		}
+		if diff.FirstUpdateId > b.lastUpdateID {
+			return false, true
+		}
+		b.apply(diff)
+		b.synced = true
+		return true, false
+	}
+
+	if diff.LastUpdateId <= b.lastUpdateID {
+		return false, false // Already covered
+	}
+	if diff.PrevUpdateId != b.lastUpdateID {
+		return false, true
+	}
+	b.apply(diff)
+	return true, false
+}
+
+// load installs a REST snapshot and replays the buffered diffs on top of it (caller holds
+// the lock). Returns true if the snapshot is older than the buffer (book stays unsynced).
+func (b *OrderBook) load(snap *futures.DepthResponse) (gap bool) {
+	b.bids = make(map[float64]float64, len(snap.Bids))
+	b.asks = make(map[float64]float64, len(snap.Asks))
+	for _, lvl := range snap.Bids {
+		price, _ := strconv.ParseFloat(lvl.Price, 64)
+		qty, _ := strconv.ParseFloat(lvl.Quantity, 64)
+		if qty > 0 {
+			b.bids[price] = qty
+		}
+	}
+	for _, lvl := range snap.Asks {
+		price, _ := strconv.ParseFloat(lvl.Price, 64)
+		qty, _ := strconv.ParseFloat(lvl.Quantity, 64)
+		if qty > 0 {
+			b.asks[price] = qty
+		}
+	}
+	b.bidPrices = sortedPrices(b.bids, true)
+	b.askPrices = sortedPrices(b.asks, false)
+	b.lastUpdateID = snap.LastUpdateID
+	b.synced = false
+	b.hasSnapshot = true
+
+	// Replay whatever arrived while the snapshot was in flight
+	for _, diff := range b.buffer {
+		if _, gap = b.ingest(diff); gap {
+			break
+		}
+	}
+	b.buffer = b.buffer[:0]
+	b.pending = nil // Buffered replays are part of the snapshot (listeners get a Reset)
+
+	if gap {
+		b.hasSnapshot = false
+	}
+	return gap
+}
+
+// snapshotTime is the event time a loaded snapshot stands for (caller holds the lock)
+func (b *OrderBook) snapshotTime(snap *futures.DepthResponse) int64 {
+	if b.lastEvent > snap.Time {
+		return b.lastEvent // Buffered diffs replayed on top
+	}
+	return snap.Time
+}
+
+// reset drops the book state so the next snapshot starts clean (caller holds the lock)
+func (b *OrderBook) reset() {
+	b.synced = false
+	b.hasSnapshot = false
+	b.bids = make(map[float64]float64)
+	b.asks = make(map[float64]float64)
+	b.bidPrices, b.askPrices = nil, nil
+	b.buffer = b.buffer[:0]
+}
+
+func (b *OrderBook) isDropped() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.dropped
+}
+
+// best returns the top of book (caller holds the lock)
+func (b *OrderBook) best() (bid, bidQty, ask, askQty float64) {
+	if len(b.bidPrices) > 0 {
+		bid = b.bidPrices[0]
+		bidQty = b.bids[bid]
+	}
+	if len(b.askPrices) > 0 {
+		ask = b.askPrices[0]
+		askQty = b.asks[ask]
+	}
+	return
+}
+
+// setLevel writes one level into a side and keeps its price list sorted best first
+func setLevel(levels map[float64]float64, prices []float64, price, qty float64, desc bool) []float64 {
+	i := sort.Search(len(prices), func(i int) bool {
+		if desc {
+			return prices[i] <= price
+		}
+		return prices[i] >= price
+	})
+	exists := i < len(prices) && prices[i] == price
+
+	if qty == 0 {
+		delete(levels, price)
+		if exists {
+			prices = append(prices[:i], prices[i+1:]...)
+		}
+		return prices
+	}
+	levels[price] = qty
+	if !exists {
+		prices = append(prices, 0)
+		copy(prices[i+1:], prices[i:])
+		prices[i] = price
+	}
+	return prices
+}
+
+// sortedPrices lists a side's prices best first
+func sortedPrices(levels map[float64]float64, desc bool) []float64 {
+	prices := make([]float64, 0, len(levels))
+	for p := range levels {
+		prices = append(prices, p)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	} else {
+		sort.Float64s(prices)
+	}
+	return prices
+}
+
+// ============================================================================
+// READ API
+// ============================================================================
+
+// BestBidAsk returns the top of book for a symbol ("BTC" or "BTCUSDT")
+func (m *OrderBookManager) BestBidAsk(symbol string) (*DepthSnapshot, bool) {
+	book, exists := m.lookup(symbol)
+	if !exists {
+		return nil, false
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	if !book.synced {
+		return nil, false
+	}
+
+	bid, bidQty, ask, askQty := book.best()
+	if bid == 0 || ask == 0 {
+		return nil, false
+	}
+	return &DepthSnapshot{
+		Symbol:     book.Symbol,
+		BestBid:    bid,
+		BestBidQty: bidQty,
+		BestAsk:    ask,
+		BestAskQty: askQty,
+		LastUpdate: book.lastEvent,
+	}, true
+}
+
+// LevelsWithin returns all levels within pct (e.g. 0.005 = 0.5%) of the mid price.
+// Bids are sorted best (highest) first, asks best (lowest) first.
+func (m *OrderBookManager) LevelsWithin(symbol string, pct float64) (bids, asks []BookLevel) {
+	book, exists := m.lookup(symbol)
+	if !exists {
+		return nil, nil
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	if !book.synced {
+		return nil, nil
+	}
+
+	bestBid, _, bestAsk, _ := book.best()
+	if bestBid == 0 || bestAsk == 0 {
+		return nil, nil
+	}
+	mid := (bestBid + bestAsk) / 2
+	low := mid * (1 - pct)
+	high := mid * (1 + pct)
+
+	for _, p := range book.bidPrices {
+		if p < low {
+			break
+		}
+		bids = append(bids, BookLevel{Price: p, Qty: book.bids[p]})
+	}
+	for _, p := range book.askPrices {
+		if p > high {
+			break
+		}
+		asks = append(asks, BookLevel{Price: p, Qty: book.asks[p]})
+	}
+	return bids, asks
+}
+
+// NotionalAt returns the USD value resting at an exact price level (either side)
+func (m *OrderBookManager) NotionalAt(symbol string, price float64) float64 {
+	book, exists := m.lookup(symbol)
+	if !exists {
+		return 0.0
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	if !book.synced {
+		return 0.0
+	}
+
+	if qty, ok := book.bids[price]; ok {
+		return price * qty
+	}
+	if qty, ok := book.asks[price]; ok {
+		return price * qty
+	}
+	return 0.0
+}
+
+// NotionalNear returns the USD value resting on one side ("bid" / "ask")
+// within band (e.g. 0.0005 = 0.05%) of price
+func (m *OrderBookManager) NotionalNear(symbol, side string, price, band float64) float64 {
+	book, exists := m.lookup(symbol)
+	if !exists {
+		return 0.0
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	if !book.synced {
+		return 0.0
+	}
+
+	levels := book.bids
+	if side == "ask" {
+		levels = book.asks
+	}
+	low := price * (1 - band)
+	high := price * (1 + band)
+	total := 0.0
+	for p, q := range levels {
+		if p >= low && p <= high {
+			total += p * q
+		}
+	}
+	return total
+}
+
+// IsSynced reports whether the symbol's book is currently in sequence
+func (m *OrderBookManager) IsSynced(symbol string) bool {
+	book, exists := m.lookup(symbol)
+	if !exists {
+		return false
+	}
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	return book.synced
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+func bookSnapshot(lastUpdateID int64) *futures.DepthResponse {
+	return &futures.DepthResponse{
+		LastUpdateID: lastUpdateID,
+		Bids:         []futures.Bid{{Price: "100", Quantity: "1"}, {Price: "99", Quantity: "2"}},
+		Asks:         []futures.Ask{{Price: "101", Quantity: "1"}, {Price: "102", Quantity: "2"}},
+	}
+}
+
+func bookDiff(first, last, prev int64, bids, asks [][]string) binanceDepthData {
+	return binanceDepthData{Symbol: "BTCUSDT", EventTime: last, FirstUpdateId: first, LastUpdateId: last, PrevUpdateId: prev, Bids: bids, Asks: asks}
+}
+
+func TestOrderBookSequencing(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBuffered int
+		buffered    []binanceDepthData // Before the snapshot
+		snapshots   []int64            // lastUpdateId of each LoadSnapshot
+		live        []binanceDepthData // After the snapshots
+		wantSynced  bool
+		wantLastID  int64
+		wantBestBid float64
+	}{
+		{
+			name:        "bridging diff syncs",
+			snapshots:   []int64{100},
+			live:        []binanceDepthData{bookDiff(95, 105, 90, [][]string{{"100.5", "3"}}, nil)},
+			wantSynced:  true,
+			wantLastID:  105,
+			wantBestBid: 100.5,
+		},
+		{
+			name:      "stale diff is skipped",
+			snapshots: []int64{100},
+			live: []binanceDepthData{
+				bookDiff(90, 99, 89, [][]string{{"100.5", "3"}}, nil),
+				bookDiff(99, 103, 99, [][]string{{"100", "0"}}, nil),
+				bookDiff(100, 102, 99, [][]string{{"100.7", "3"}}, nil),
+			},
+			wantSynced:  true,
+			wantLastID:  103,
+			wantBestBid: 99,
+		},
+		{
+			name:      "diff past the snapshot is a gap",
+			snapshots: []int64{100},
+			live:      []binanceDepthData{bookDiff(101, 105, 100, nil, nil)},
+			// Snapshot missed update 100..101: nothing bridges it
+			wantSynced: false,
+			wantLastID: 100,
+		},
+		{
+			name:      "pu gap drops sync",
+			snapshots: []int64{100},
+			live: []binanceDepthData{
+				bookDiff(95, 105, 90, nil, nil),
+				bookDiff(107, 110, 106, nil, nil),
+			},
+			wantSynced: false,
+		},
+		{
+			name:        "buffered diffs replay onto the snapshot",
+			buffered:    []binanceDepthData{bookDiff(95, 105, 90, nil, nil), bookDiff(106, 110, 105, [][]string{{"100.5", "3"}}, nil)},
+			snapshots:   []int64{100},
+			wantSynced:  true,
+			wantLastID:  110,
+			wantBestBid: 100.5,
+		},
+		{
+			name:       "snapshot older than the buffer waits for the next",
+			buffered:   []binanceDepthData{bookDiff(200, 210, 199, nil, nil)},
+			snapshots:  []int64{100},
+			wantSynced: false,
+		},
+		{
+			name:     "newer snapshot after an old one bridges",
+			buffered: []binanceDepthData{bookDiff(200, 210, 199, nil, nil)},
+			// The stale snapshot empties the buffer, so the next diff bridges the fresh one
+			snapshots:   []int64{100, 212},
+			live:        []binanceDepthData{bookDiff(211, 215, 210, [][]string{{"100.5", "3"}}, nil)},
+			wantSynced:  true,
+			wantLastID:  215,
+			wantBestBid: 100.5,
+		},
+		{
+			name:        "buffer overflow gaps on the next live diff",
+			maxBuffered: 2,
+			buffered: []binanceDepthData{
+				bookDiff(95, 105, 90, nil, nil),
+				bookDiff(106, 110, 105, nil, nil),
+				bookDiff(111, 115, 110, nil, nil), // Dropped
+			},
+			snapshots:  []int64{100},
+			live:       []binanceDepthData{bookDiff(116, 120, 115, nil, nil)},
+			wantSynced: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewOrderBookManager()
+			m.ExternalSnapshots = true
+			if tt.maxBuffered > 0 {
+				m.MaxBuffered = tt.maxBuffered
+			}
+			for _, d := range tt.buffered {
+				m.ApplyDiff(d)
+			}
+			for _, id := range tt.snapshots {
+				m.LoadSnapshot("BTCUSDT", bookSnapshot(id))
+			}
+			for _, d := range tt.live {
+				m.ApplyDiff(d)
+			}
+
+			if got := m.IsSynced("BTCUSDT"); got != tt.wantSynced {
+				t.Fatalf("synced = %v, want %v", got, tt.wantSynced)
+			}
+			book, _ := m.lookup("BTCUSDT")
+			if tt.wantLastID != 0 && book.lastUpdateID != tt.wantLastID {
+				t.Errorf("lastUpdateID = %d, want %d", book.lastUpdateID, tt.wantLastID)
+			}
+			if tt.wantBestBid != 0 {
+				depth, ok := m.BestBidAsk("BTCUSDT")
+				if !ok || depth.BestBid != tt.wantBestBid {
+					t.Errorf("best bid = %+v, want %v", depth, tt.wantBestBid)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderBookLevelsStaySorted(t *testing.T) {
+	m := NewOrderBookManager()
+	m.ExternalSnapshots = true
+	m.LoadSnapshot("BTCUSDT", bookSnapshot(100))
+	m.ApplyDiff(bookDiff(95, 105, 90, [][]string{{"100", "0"}, {"99.5", "4"}}, [][]string{{"100.8", "1"}, {"101", "0"}}))
+
+	depth, ok := m.BestBidAsk("BTCUSDT")
+	if !ok || depth.BestBid != 99.5 || depth.BestBidQty != 4 || depth.BestAsk != 100.8 {
+		t.Fatalf("top of book = %+v", depth)
+	}
+
+	bids, asks := m.LevelsWithin("BTCUSDT", 0.05)
+	wantBids, wantAsks := []float64{99.5, 99}, []float64{100.8, 102}
+	if len(bids) != len(wantBids) || len(asks) != len(wantAsks) {
+		t.Fatalf("levels = %v / %v", bids, asks)
+	}
+	for i, p := range wantBids {
+		if bids[i].Price != p {
+			t.Errorf("bid %d = %v, want %v", i, bids[i].Price, p)
+		}
+	}
+	for i, p := range wantAsks {
+		if asks[i].Price != p {
+			t.Errorf("ask %d = %v, want %v", i, asks[i].Price, p)
+		}
+	}
+}
+
+func TestOrderBookDroppedWithTheUniverse(t *testing.T) {
+	registry := NewSymbolRegistry([]string{"BTCUSDT", "ETHUSDT"})
+	m := NewOrderBookManager()
+	m.ExternalSnapshots = true
+	m.registry = registry
+	m.LoadSnapshot("BTCUSDT", bookSnapshot(100))
+	m.ApplyDiff(bookDiff(95, 105, 90, nil, nil))
+
+	registry.Remove("BTCUSDT")
+	m.retain(registry)
+
+	if _, ok := m.BestBidAsk("BTCUSDT"); ok {
+		t.Fatal("removed symbol still quoted")
+	}
+	m.ApplyDiff(bookDiff(106, 110, 105, nil, nil))
+	if _, exists := m.lookup("BTCUSDT"); exists {
+		t.Fatal("late diff recreated a removed book")
+	}
+}
@@ -0,0 +1,1063 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// PAPER EXCHANGE (Simulated Binance Futures REST)
+// ============================================================================
+// Serves the signed order/account endpoints that ExecutionService and
+// PredatorEngine call through BinanceGateway, matching against the live local
+// order book and trade stream. Public market data endpoints are proxied to
+// mainnet so klines/exchangeInfo keep working unchanged. A listenKey user data
+// stream is served at /ws/<listenKey> with Binance-format fill events.
+
+// Conditional order types (go-binance has no constants for these)
+const (
+	paperOrderStop             futures.OrderType = "STOP"
+	paperOrderStopMarket       futures.OrderType = "STOP_MARKET"
+	paperOrderTakeProfit       futures.OrderType = "TAKE_PROFIT"
+	paperOrderTakeProfitMarket futures.OrderType = "TAKE_PROFIT_MARKET"
+)
+
+// PaperConfig configures the simulated account
+type PaperConfig struct {
+	StartingBalance float64 // USDT wallet (e.g. 10000)
+	MakerFee        float64 // e.g. 0.0002 (0.02%)
+	TakerFee        float64 // e.g. 0.0005 (0.05%)
+}
+
+// PaperExchange is an in-process matching engine behind a Binance-compatible HTTP API
+type PaperExchange struct {
+	mu     sync.Mutex
+	config PaperConfig
+	books  *OrderBookManager
+	proxy  *httputil.ReverseProxy
+
+	URL string // Base URL to point futures.Client at (set by Start)
+
+	nextID     int64
+	orders     map[int64]*paperOrder     // OrderID -> Order (all states)
+	open       map[string][]*paperOrder  // Symbol -> Open orders, oldest first (hot path)
+	positions  map[string]*paperPosition // Symbol -> One-Way Position
+	leverage   map[string]int            // Symbol -> Leverage
+	marginType map[string]string         // Symbol -> "ISOLATED" / "CROSSED"
+	marks      map[string]float64        // Symbol -> Mark Price
+	lastPrices map[string]float64        // Symbol -> Last Trade Price
+
+	balance   float64 // Wallet balance (realized PnL and fees applied)
+	FeesPaid  float64 // Total maker + taker fees
+	FillCount int
+
+	// User Data Stream
+	upgrader websocket.Upgrader
+	streams  map[chan []byte]bool // Connected /ws/<listenKey> clients
+}
+
+type paperOrder struct {
+	ID          int64
+	ClientID    string
+	Symbol      string
+	Side        futures.SideType
+	Type        futures.OrderType
+	OrigType    futures.OrderType
+	TimeInForce futures.TimeInForceType
+	Price       float64
+	StopPrice   float64
+	OrigQty     float64
+	ExecQty     float64
+	CumQuote    float64
+	ReduceOnly  bool
+	ClosePos    bool
+	WorkingType futures.WorkingType
+	Status      futures.OrderStatusType
+	Triggered   bool // Conditional order has fired (now a working limit)
+	Time        int64
+	UpdateTime  int64
+}
+
+type paperPosition struct {
+	Amt   float64 // Signed (+Long / -Short)
+	Entry float64
+}
+
+// paperError mirrors Binance's {"code":..,"msg":..} error body
+type paperError struct {
+	Code int64  `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (e *paperError) Error() string { return fmt.Sprintf("code=%d, msg=%s", e.Code, e.Msg) }
+
+// NewPaperExchange creates the simulator (call Start to serve it)
+func NewPaperExchange(books *OrderBookManager, config PaperConfig) *PaperExchange {
+	upstream, _ := url.Parse(futures.BaseApiMainUrl)
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = upstream.Host
+	}
+
+	return &PaperExchange{
+		config:     config,
+		books:      books,
+		proxy:      proxy,
+		nextID:     1,
+		orders:     make(map[int64]*paperOrder),
+		open:       make(map[string][]*paperOrder),
+		positions:  make(map[string]*paperPosition),
+		leverage:   make(map[string]int),
+		marginType: make(map[string]string),
+		marks:      make(map[string]float64),
+		lastPrices: make(map[string]float64),
+		balance:    config.StartingBalance,
+		upgrader:   websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		streams:    make(map[chan []byte]bool),
+	}
+}
+
+// Start serves the API on a loopback port and sets URL
+func (p *PaperExchange) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	p.URL = "http://" + listener.Addr().String()
+
+	go http.Serve(listener, p)
+	log.Printf("📝 PAPER EXCHANGE: Serving simulated Binance Futures at %s (Balance: $%.2f)", p.URL, p.config.StartingBalance)
+	return nil
+}
+
+// Attach points a Binance gateway (REST + user stream) at the paper exchange
+func (p *PaperExchange) Attach(gateway *BinanceGateway) {
+	gateway.SetBaseURL(p.URL)
+	gateway.SetStreamURL("ws" + strings.TrimPrefix(p.URL, "http") + "/ws")
+}
+
+// ============================================================================
+// MARKET DATA INPUTS
+// ============================================================================
+
+// OnTrade fills resting limit orders that the trade crossed (maker fills)
+func (p *PaperExchange) OnTrade(trade Trade) {
+	symbol := NormalizeSymbol(trade.Symbol)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastPrices[symbol] = trade.Price
+	if _, exists := p.marks[symbol]; !exists {
+		p.marks[symbol] = trade.Price
+	}
+
+	remaining := trade.Size
+	for _, o := range p.sortedOpenOrders(symbol) {
+		if remaining <= 0 {
+			break
+		}
+		if !o.isWorkingLimit() {
+			continue
+		}
+		// Trade must print THROUGH the limit (queue position unknown at-price)
+		crossed := (o.Side == futures.SideTypeBuy && trade.Price < o.Price) ||
+			(o.Side == futures.SideTypeSell && trade.Price > o.Price)
+		if !crossed {
+			continue
+		}
+
+		qty := math.Min(o.OrigQty-o.ExecQty, remaining)
+		if o.ReduceOnly {
+			qty = math.Min(qty, p.reducibleQty(symbol, o.Side))
+			if qty <= 0 {
+				p.finish(o, futures.OrderStatusTypeExpired)
+				continue
+			}
+		}
+		remaining -= qty
+		p.fill(o, qty, o.Price, true)
+	}
+}
+
+// OnMarkPrice updates the mark price and fires STOP / TAKE_PROFIT triggers
+func (p *PaperExchange) OnMarkPrice(symbol string, mark float64) {
+	symbol = NormalizeSymbol(symbol)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.marks[symbol] = mark
+	for _, o := range p.sortedOpenOrders(symbol) {
+		if !o.isConditional() || o.Triggered {
+			continue
+		}
+		if !o.shouldTrigger(mark) {
+			continue
+		}
+
+		o.Triggered = true
+		o.UpdateTime = time.Now().UnixMilli()
+		log.Printf("📝 PAPER TRIGGER: %s %s %s @ mark %.6f (stop %.6f)", o.Symbol, o.OrigType, o.Side, mark, o.StopPrice)
+
+		if o.Type == paperOrderStopMarket || o.Type == paperOrderTakeProfitMarket {
+			if err := p.executeMarket(o); err != nil {
+				log.Printf("📝 PAPER: Triggered %d could not fill: %v", o.ID, err)
+				p.finish(o, futures.OrderStatusTypeExpired)
+			}
+			continue
+		}
+
+		// STOP / TAKE_PROFIT become a working limit order (taker if marketable)
+		o.Type = futures.OrderTypeLimit
+		if p.isMarketable(o) {
+			p.executeLimitTaker(o)
+		}
+	}
+}
+
+// ============================================================================
+// HTTP API
+// ============================================================================
+
+// ServeHTTP routes the Binance endpoints used by the engines; everything else is proxied
+func (p *PaperExchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/ws/") {
+		p.serveUserStream(w, r)
+		return
+	}
+	params := requestParams(r)
+
+	var res interface{}
+	var err error
+
+	switch {
+	case r.URL.Path == "/fapi/v1/listenKey" && r.Method == http.MethodPost:
+		res = map[string]string{"listenKey": fmt.Sprintf("paper-%d", time.Now().UnixNano())}
+	case r.URL.Path == "/fapi/v1/listenKey":
+		res = map[string]string{} // Keepalive / Close
+	case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodPost:
+		res, err = p.placeOrder(params)
+	case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodGet:
+		res, err = p.queryOrder(params)
+	case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodDelete:
+		res, err = p.cancelOrder(params)
+	case r.URL.Path == "/fapi/v1/allOpenOrders" && r.Method == http.MethodDelete:
+		res, err = p.cancelAll(params)
+	case r.URL.Path == "/fapi/v1/openOrders":
+		res, err = p.openOrders(params)
+	case r.URL.Path == "/fapi/v2/positionRisk" || r.URL.Path == "/fapi/v3/positionRisk":
+		res, err = p.positionRisk(params)
+	case r.URL.Path == "/fapi/v2/account" || r.URL.Path == "/fapi/v3/account":
+		res, err = p.account()
+	case r.URL.Path == "/fapi/v2/balance" || r.URL.Path == "/fapi/v3/balance":
+		res, err = p.balances()
+	case r.URL.Path == "/fapi/v1/leverage":
+		res, err = p.changeLeverage(params)
+	case r.URL.Path == "/fapi/v1/marginType":
+		res, err = p.changeMarginType(params)
+	case r.URL.Path == "/fapi/v1/positionSide/dual":
+		res = map[string]interface{}{"code": 200, "msg": "success"}
+	case r.URL.Path == "/fapi/v1/ticker/bookTicker":
+		if res, err = p.bookTicker(params); res == nil && err == nil {
+			p.proxy.ServeHTTP(w, r)
+			return
+		}
+	case r.URL.Path == "/fapi/v1/ticker/price" || r.URL.Path == "/fapi/v2/ticker/price":
+		if res, err = p.tickerPrice(params); res == nil && err == nil {
+			p.proxy.ServeHTTP(w, r)
+			return
+		}
+	default:
+		p.proxy.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		apiErr, ok := err.(*paperError)
+		if !ok {
+			apiErr = &paperError{Code: -1000, Msg: err.Error()}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(apiErr)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// requestParams merges query string and form body (go-binance signs both)
+func requestParams(r *http.Request) url.Values {
+	params := r.URL.Query()
+	if r.Body != nil {
+		body, _ := io.ReadAll(r.Body)
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for k, v := range form {
+				params[k] = v
+			}
+		}
+	}
+	return params
+}
+
+func (p *PaperExchange) placeOrder(params url.Values) (interface{}, error) {
+	symbol := params.Get("symbol")
+	if symbol == "" {
+		return nil, &paperError{Code: -1102, Msg: "Mandatory parameter 'symbol' was not sent."}
+	}
+
+	o := &paperOrder{
+		ClientID:    params.Get("newClientOrderId"),
+		Symbol:      symbol,
+		Side:        futures.SideType(params.Get("side")),
+		Type:        futures.OrderType(params.Get("type")),
+		TimeInForce: futures.TimeInForceType(params.Get("timeInForce")),
+		ReduceOnly:  params.Get("reduceOnly") == "true",
+		ClosePos:    params.Get("closePosition") == "true",
+		WorkingType: futures.WorkingType(params.Get("workingType")),
+		Status:      futures.OrderStatusTypeNew,
+	}
+	o.OrigType = o.Type
+	o.Price, _ = strconv.ParseFloat(params.Get("price"), 64)
+	o.StopPrice, _ = strconv.ParseFloat(params.Get("stopPrice"), 64)
+	o.OrigQty, _ = strconv.ParseFloat(params.Get("quantity"), 64)
+	if o.TimeInForce == "" && o.Type != futures.OrderTypeMarket {
+		o.TimeInForce = futures.TimeInForceTypeGTC
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if o.ClosePos {
+		o.ReduceOnly = true // Quantity resolves at trigger time (executeMarket)
+	}
+	if o.OrigQty <= 0 && !o.ClosePos {
+		return nil, &paperError{Code: -4003, Msg: "Quantity less than or equal to zero."}
+	}
+	if o.ReduceOnly && !o.isConditional() && p.reducibleQty(symbol, o.Side) <= 0 {
+		return nil, &paperError{Code: -2022, Msg: "ReduceOnly Order is rejected."}
+	}
+
+	// Validate (rejections are not recorded)
+	switch o.Type {
+	case futures.OrderTypeMarket:
+		if _, ok := p.lastPrices[symbol]; !ok && len(p.takerLevels(symbol, o.Side)) == 0 {
+			return nil, &paperError{Code: -1000, Msg: "Paper exchange has no market data for " + symbol}
+		}
+	case futures.OrderTypeLimit:
+		if o.TimeInForce == futures.TimeInForceTypeGTX && p.isMarketable(o) {
+			return nil, &paperError{Code: -5022, Msg: "Due to the order could not be executed as maker, the Post Only order will be rejected. The order will not be recorded in the order history"}
+		}
+	case paperOrderStop, paperOrderStopMarket, paperOrderTakeProfit, paperOrderTakeProfitMarket:
+		if o.StopPrice <= 0 {
+			return nil, &paperError{Code: -1102, Msg: "Mandatory parameter 'stopPrice' was not sent."}
+		}
+	default:
+		return nil, &paperError{Code: -1116, Msg: "Invalid orderType."}
+	}
+
+	now := time.Now().UnixMilli()
+	o.ID = p.nextID
+	p.nextID++
+	o.Time, o.UpdateTime = now, now
+	if o.ClientID == "" {
+		o.ClientID = fmt.Sprintf("paper-%d", o.ID)
+	}
+	p.orders[o.ID] = o
+	p.open[symbol] = append(p.open[symbol], o)
+	p.emitOrderUpdate(o, futures.OrderExecutionTypeNew, 0, 0, 0, 0, false)
+
+	// Match
+	switch o.Type {
+	case futures.OrderTypeMarket:
+		if err := p.executeMarket(o); err != nil {
+			p.finish(o, futures.OrderStatusTypeExpired)
+			return nil, err
+		}
+	case futures.OrderTypeLimit:
+		if p.isMarketable(o) {
+			p.executeLimitTaker(o)
+		}
+	}
+	return o.toOrder(), nil
+}
+
+func (p *PaperExchange) queryOrder(params url.Values) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o := p.findOrder(params)
+	if o == nil {
+		return nil, &paperError{Code: -2013, Msg: "Order does not exist."}
+	}
+	return o.toOrder(), nil
+}
+
+func (p *PaperExchange) cancelOrder(params url.Values) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o := p.findOrder(params)
+	if o == nil || !o.isOpen() {
+		return nil, &paperError{Code: -2011, Msg: "Unknown order sent."}
+	}
+	p.finish(o, futures.OrderStatusTypeCanceled)
+	return o.toOrder(), nil
+}
+
+func (p *PaperExchange) cancelAll(params url.Values) (interface{}, error) {
+	symbol := params.Get("symbol")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, o := range p.sortedOpenOrders(symbol) {
+		p.finish(o, futures.OrderStatusTypeCanceled)
+	}
+	return map[string]interface{}{"code": 200, "msg": "The operation of cancel all open order is done."}, nil
+}
+
+func (p *PaperExchange) openOrders(params url.Values) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := []*futures.Order{}
+	for _, o := range p.sortedOpenOrders(params.Get("symbol")) {
+		res = append(res, o.toOrder())
+	}
+	return res, nil
+}
+
+func (p *PaperExchange) positionRisk(params url.Values) (interface{}, error) {
+	filter := params.Get("symbol")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := []*futures.PositionRisk{}
+	for symbol, pos := range p.positions {
+		if filter != "" && symbol != filter {
+			continue
+		}
+		mark := p.markPrice(symbol)
+		lev := p.leverageFor(symbol)
+		res = append(res, &futures.PositionRisk{
+			Symbol:           symbol,
+			PositionAmt:      formatFloat(pos.Amt),
+			EntryPrice:       formatFloat(pos.Entry),
+			BreakEvenPrice:   formatFloat(pos.Entry),
+			MarkPrice:        formatFloat(mark),
+			UnRealizedProfit: formatFloat((mark - pos.Entry) * pos.Amt),
+			Leverage:         strconv.Itoa(lev),
+			MarginType:       strings.ToLower(p.marginTypeFor(symbol)),
+			IsolatedMargin:   formatFloat(math.Abs(pos.Amt) * pos.Entry / float64(lev)),
+			Notional:         formatFloat(pos.Amt * mark),
+			PositionSide:     string(futures.PositionSideTypeBoth),
+		})
+	}
+	if filter != "" && len(res) == 0 {
+		res = append(res, &futures.PositionRisk{Symbol: filter, PositionAmt: "0", EntryPrice: "0", PositionSide: string(futures.PositionSideTypeBoth)})
+	}
+	return res, nil
+}
+
+func (p *PaperExchange) account() (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	unrealized, margin := 0.0, 0.0
+	var positions []*futures.AccountPosition
+	for symbol, pos := range p.positions {
+		mark := p.markPrice(symbol)
+		lev := p.leverageFor(symbol)
+		pnl := (mark - pos.Entry) * pos.Amt
+		posMargin := math.Abs(pos.Amt) * pos.Entry / float64(lev)
+		unrealized += pnl
+		margin += posMargin
+		positions = append(positions, &futures.AccountPosition{
+			Symbol:                symbol,
+			Isolated:              p.marginTypeFor(symbol) == "ISOLATED",
+			Leverage:              strconv.Itoa(lev),
+			PositionAmt:           formatFloat(pos.Amt),
+			EntryPrice:            formatFloat(pos.Entry),
+			UnrealizedProfit:      formatFloat(pnl),
+			PositionInitialMargin: formatFloat(posMargin),
+			InitialMargin:         formatFloat(posMargin),
+			Notional:              formatFloat(pos.Amt * mark),
+			PositionSide:          futures.PositionSideTypeBoth,
+		})
+	}
+	available := p.balance + unrealized - margin
+
+	return &futures.Account{
+		Assets: []*futures.AccountAsset{{
+			Asset:                 "USDT",
+			WalletBalance:         formatFloat(p.balance),
+			UnrealizedProfit:      formatFloat(unrealized),
+			MarginBalance:         formatFloat(p.balance + unrealized),
+			PositionInitialMargin: formatFloat(margin),
+			InitialMargin:         formatFloat(margin),
+			AvailableBalance:      formatFloat(available),
+			MaxWithdrawAmount:     formatFloat(available),
+			MarginAvailable:       true,
+		}},
+		CanTrade:              true,
+		TotalWalletBalance:    formatFloat(p.balance),
+		TotalUnrealizedProfit: formatFloat(unrealized),
+		TotalMarginBalance:    formatFloat(p.balance + unrealized),
+		AvailableBalance:      formatFloat(available),
+		Positions:             positions,
+	}, nil
+}
+
+func (p *PaperExchange) balances() (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return []*futures.Balance{{
+		Asset:            "USDT",
+		Balance:          formatFloat(p.balance),
+		AvailableBalance: formatFloat(p.balance),
+		MarginAvailable:  true,
+	}}, nil
+}
+
+func (p *PaperExchange) changeLeverage(params url.Values) (interface{}, error) {
+	symbol := params.Get("symbol")
+	lev, _ := strconv.Atoi(params.Get("leverage"))
+	if lev < 1 || lev > 125 {
+		return nil, &paperError{Code: -4028, Msg: "Leverage is not valid"}
+	}
+
+	p.mu.Lock()
+	p.leverage[symbol] = lev
+	p.mu.Unlock()
+
+	return map[string]interface{}{"symbol": symbol, "leverage": lev, "maxNotionalValue": "1000000"}, nil
+}
+
+func (p *PaperExchange) changeMarginType(params url.Values) (interface{}, error) {
+	symbol := params.Get("symbol")
+	mt := strings.ToUpper(params.Get("marginType"))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.marginTypeFor(symbol) == mt {
+		return nil, &paperError{Code: -4046, Msg: "No need to change margin type."}
+	}
+	p.marginType[symbol] = mt
+	return map[string]interface{}{"code": 200, "msg": "success"}, nil
+}
+
+// bookTicker answers from the local book (nil = not synced, caller proxies)
+func (p *PaperExchange) bookTicker(params url.Values) (interface{}, error) {
+	symbol := params.Get("symbol")
+	if symbol == "" || p.books == nil {
+		return nil, nil
+	}
+	depth, ok := p.books.BestBidAsk(symbol)
+	if !ok {
+		return nil, nil
+	}
+	return &futures.BookTicker{
+		Symbol:      NormalizeSymbol(symbol),
+		BidPrice:    formatFloat(depth.BestBid),
+		BidQuantity: formatFloat(depth.BestBidQty),
+		AskPrice:    formatFloat(depth.BestAsk),
+		AskQuantity: formatFloat(depth.BestAskQty),
+		Time:        depth.LastUpdate,
+	}, nil
+}
+
+// tickerPrice answers from the last seen trade (nil = unknown, caller proxies)
+func (p *PaperExchange) tickerPrice(params url.Values) (interface{}, error) {
+	symbol := params.Get("symbol")
+	if symbol == "" {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	price, ok := p.lastPrices[symbol]
+	p.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	return &futures.SymbolPrice{Symbol: symbol, Price: formatFloat(price)}, nil
+}
+
+// ============================================================================
+// MATCHING (caller holds p.mu)
+// ============================================================================
+
+// executeMarket walks the local book as a taker
+func (p *PaperExchange) executeMarket(o *paperOrder) error {
+	if o.ClosePos {
+		// closePosition: whatever is open when it fires, like Binance
+		o.OrigQty = o.ExecQty + p.reducibleQty(o.Symbol, o.Side)
+	}
+	qty := o.OrigQty - o.ExecQty
+	if o.ReduceOnly {
+		qty = math.Min(qty, p.reducibleQty(o.Symbol, o.Side))
+		if qty <= 0 {
+			return &paperError{Code: -2022, Msg: "ReduceOnly Order is rejected."}
+		}
+	}
+
+	levels := p.takerLevels(o.Symbol, o.Side)
+	if len(levels) == 0 {
+		// No book yet: fall back to last trade
+		price, ok := p.lastPrices[o.Symbol]
+		if !ok {
+			return &paperError{Code: -1000, Msg: "Paper exchange has no market data for " + o.Symbol}
+		}
+		levels = []BookLevel{{Price: price, Qty: qty}}
+	}
+
+	for _, lvl := range levels {
+		if qty <= 0 {
+			break
+		}
+		take := math.Min(qty, lvl.Qty)
+		p.fill(o, take, lvl.Price, false)
+		qty -= take
+	}
+	// Book exhausted: sweep the remainder at the last level
+	if qty > 0 {
+		p.fill(o, qty, levels[len(levels)-1].Price, false)
+	}
+	return nil
+}
+
+// executeLimitTaker fills the marketable part of a limit order, rest keeps working
+func (p *PaperExchange) executeLimitTaker(o *paperOrder) {
+	for _, lvl := range p.takerLevels(o.Symbol, o.Side) {
+		remaining := o.OrigQty - o.ExecQty
+		if remaining <= 0 {
+			return
+		}
+		if (o.Side == futures.SideTypeBuy && lvl.Price > o.Price) || (o.Side == futures.SideTypeSell && lvl.Price < o.Price) {
+			return
+		}
+		take := math.Min(remaining, lvl.Qty)
+		if o.ReduceOnly {
+			take = math.Min(take, p.reducibleQty(o.Symbol, o.Side))
+			if take <= 0 {
+				p.finish(o, futures.OrderStatusTypeExpired)
+				return
+			}
+		}
+		p.fill(o, take, lvl.Price, false)
+	}
+}
+
+// takerLevels returns the opposite side of the book, best first
+func (p *PaperExchange) takerLevels(symbol string, side futures.SideType) []BookLevel {
+	if p.books == nil {
+		return nil
+	}
+	bids, asks := p.books.LevelsWithin(symbol, 0.05)
+	if side == futures.SideTypeBuy {
+		return asks
+	}
+	return bids
+}
+
+// isMarketable reports whether a limit order would cross the book right now
+func (p *PaperExchange) isMarketable(o *paperOrder) bool {
+	if p.books == nil {
+		return false
+	}
+	depth, ok := p.books.BestBidAsk(o.Symbol)
+	if !ok {
+		return false
+	}
+	if o.Side == futures.SideTypeBuy {
+		return o.Price >= depth.BestAsk
+	}
+	return o.Price <= depth.BestBid
+}
+
+// fill applies an execution to the order, position and wallet
+func (p *PaperExchange) fill(o *paperOrder, qty, price float64, maker bool) {
+	if qty <= 0 {
+		return
+	}
+	feeRate := p.config.TakerFee
+	if maker {
+		feeRate = p.config.MakerFee
+	}
+	o.ExecQty += qty
+	o.CumQuote += qty * price
+	o.UpdateTime = time.Now().UnixMilli()
+	if o.ExecQty >= o.OrigQty-1e-12 {
+		o.Status = futures.OrderStatusTypeFilled
+	} else {
+		o.Status = futures.OrderStatusTypePartiallyFilled
+	}
+
+	fee := qty * price * feeRate
+	p.balance -= fee
+	p.FeesPaid += fee
+	p.FillCount++
+
+	signed := qty
+	if o.Side == futures.SideTypeSell {
+		signed = -qty
+	}
+	realized := p.applyToPosition(o.Symbol, signed, price)
+	p.balance += realized
+
+	log.Printf("📝 PAPER FILL: %s %s %.6f @ %.6f (%s) | Fee $%.4f | Realized $%.2f | Wallet $%.2f",
+		o.Symbol, o.Side, qty, price, o.Status, fee, realized, p.balance)
+
+	p.emitOrderUpdate(o, futures.OrderExecutionTypeTrade, qty, price, fee, realized, maker)
+	p.emitAccountUpdate(o.Symbol)
+}
+
+// applyToPosition updates the one-way position and returns realized PnL
+func (p *PaperExchange) applyToPosition(symbol string, signed, price float64) float64 {
+	pos := p.position(symbol)
+	realized := 0.0
+
+	if pos.Amt == 0 || (pos.Amt > 0) == (signed > 0) {
+		// Opening / Adding
+		total := math.Abs(pos.Amt) + math.Abs(signed)
+		pos.Entry = (pos.Entry*math.Abs(pos.Amt) + price*math.Abs(signed)) / total
+		pos.Amt += signed
+		return 0.0
+	}
+
+	// Reducing / Flipping
+	closing := math.Min(math.Abs(signed), math.Abs(pos.Amt))
+	if pos.Amt > 0 {
+		realized = (price - pos.Entry) * closing
+	} else {
+		realized = (pos.Entry - price) * closing
+	}
+	pos.Amt += signed
+	if math.Abs(pos.Amt) < 1e-12 {
+		pos.Amt, pos.Entry = 0, 0
+	} else if (pos.Amt > 0) == (signed > 0) {
+		pos.Entry = price // Flipped: remainder opened at fill price
+	}
+	return realized
+}
+
+func (p *PaperExchange) position(symbol string) *paperPosition {
+	pos, exists := p.positions[symbol]
+	if !exists {
+		pos = &paperPosition{}
+		p.positions[symbol] = pos
+	}
+	return pos
+}
+
+// reducibleQty is how much an order on side can close
+func (p *PaperExchange) reducibleQty(symbol string, side futures.SideType) float64 {
+	amt := p.position(symbol).Amt
+	if side == futures.SideTypeSell && amt > 0 {
+		return amt
+	}
+	if side == futures.SideTypeBuy && amt < 0 {
+		return -amt
+	}
+	return 0.0
+}
+
+func (p *PaperExchange) finish(o *paperOrder, status futures.OrderStatusType) {
+	o.Status = status
+	o.UpdateTime = time.Now().UnixMilli()
+	p.emitOrderUpdate(o, futures.OrderExecutionType(status), 0, 0, 0, 0, false)
+}
+
+func (p *PaperExchange) findOrder(params url.Values) *paperOrder {
+	if id, err := strconv.ParseInt(params.Get("orderId"), 10, 64); err == nil {
+		return p.orders[id]
+	}
+	if cid := params.Get("origClientOrderId"); cid != "" {
+		for _, o := range p.orders {
+			if o.ClientID == cid {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// sortedOpenOrders returns open orders (optionally for one symbol) oldest first.
+// Finished orders are pruned from the per-symbol lists on the way.
+func (p *PaperExchange) sortedOpenOrders(symbol string) []*paperOrder {
+	if symbol != "" {
+		return p.openOrdersFor(symbol)
+	}
+	var open []*paperOrder
+	for sym := range p.open {
+		open = append(open, p.openOrdersFor(sym)...)
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].ID < open[j].ID })
+	return open
+}
+
+// openOrdersFor compacts symbol's open list and returns a copy (callers may finish orders while iterating)
+func (p *PaperExchange) openOrdersFor(symbol string) []*paperOrder {
+	list := p.open[symbol]
+	live := list[:0]
+	for _, o := range list {
+		if o.isOpen() {
+			live = append(live, o)
+		}
+	}
+	for i := len(live); i < len(list); i++ {
+		list[i] = nil // Release finished orders
+	}
+	if len(live) == 0 {
+		delete(p.open, symbol)
+		return nil
+	}
+	p.open[symbol] = live
+	return append([]*paperOrder(nil), live...)
+}
+
+func (p *PaperExchange) markPrice(symbol string) float64 {
+	if mark, ok := p.marks[symbol]; ok {
+		return mark
+	}
+	return p.lastPrices[symbol]
+}
+
+func (p *PaperExchange) leverageFor(symbol string) int {
+	if lev, ok := p.leverage[symbol]; ok {
+		return lev
+	}
+	return 20
+}
+
+func (p *PaperExchange) marginTypeFor(symbol string) string {
+	if mt, ok := p.marginType[symbol]; ok {
+		return mt
+	}
+	return "CROSSED"
+}
+
+// ============================================================================
+// USER DATA STREAM (caller holds p.mu for emit*)
+// ============================================================================
+
+// serveUserStream upgrades /ws/<listenKey> and forwards events until the client leaves
+func (p *PaperExchange) serveUserStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ch := make(chan []byte, 256)
+	p.mu.Lock()
+	p.streams[ch] = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.streams, ch)
+		p.mu.Unlock()
+	}()
+
+	// Reader detects client disconnect
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case msg := <-ch:
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+func (p *PaperExchange) emitOrderUpdate(o *paperOrder, execType futures.OrderExecutionType, lastQty, lastPrice, fee, realized float64, maker bool) {
+	if len(p.streams) == 0 {
+		return
+	}
+	avg := 0.0
+	if o.ExecQty > 0 {
+		avg = o.CumQuote / o.ExecQty
+	}
+	now := time.Now().UnixMilli()
+
+	p.broadcast(map[string]interface{}{
+		"e": futures.UserDataEventTypeOrderTradeUpdate,
+		"E": now,
+		"T": now,
+		"o": futures.WsOrderTradeUpdate{
+			Symbol:               o.Symbol,
+			ClientOrderID:        o.ClientID,
+			Side:                 o.Side,
+			Type:                 o.Type,
+			TimeInForce:          o.TimeInForce,
+			OriginalQty:          formatFloat(o.OrigQty),
+			OriginalPrice:        formatFloat(o.Price),
+			AveragePrice:         formatFloat(avg),
+			StopPrice:            formatFloat(o.StopPrice),
+			ExecutionType:        execType,
+			Status:               o.Status,
+			ID:                   o.ID,
+			LastFilledQty:        formatFloat(lastQty),
+			AccumulatedFilledQty: formatFloat(o.ExecQty),
+			LastFilledPrice:      formatFloat(lastPrice),
+			CommissionAsset:      "USDT",
+			Commission:           formatFloat(fee),
+			TradeTime:            now,
+			IsMaker:              maker,
+			IsReduceOnly:         o.ReduceOnly,
+			WorkingType:          o.WorkingType,
+			OriginalType:         o.OrigType,
+			PositionSide:         futures.PositionSideTypeBoth,
+			IsClosingPosition:    o.ClosePos,
+			RealizedPnL:          formatFloat(realized),
+		},
+	})
+}
+
+func (p *PaperExchange) emitAccountUpdate(symbol string) {
+	if len(p.streams) == 0 {
+		return
+	}
+	pos := p.position(symbol)
+	mark := p.markPrice(symbol)
+	now := time.Now().UnixMilli()
+
+	p.broadcast(map[string]interface{}{
+		"e": futures.UserDataEventTypeAccountUpdate,
+		"E": now,
+		"T": now,
+		"a": futures.WsAccountUpdate{
+			Reason: futures.UserDataEventReasonTypeOrder,
+			Balances: []futures.WsBalance{{
+				Asset:              "USDT",
+				Balance:            formatFloat(p.balance),
+				CrossWalletBalance: formatFloat(p.balance),
+			}},
+			Positions: []futures.WsPosition{{
+				Symbol:        symbol,
+				Side:          futures.PositionSideTypeBoth,
+				Amount:        formatFloat(pos.Amt),
+				EntryPrice:    formatFloat(pos.Entry),
+				MarkPrice:     formatFloat(mark),
+				UnrealizedPnL: formatFloat((mark - pos.Entry) * pos.Amt),
+				MarginType:    futures.MarginType(strings.ToLower(p.marginTypeFor(symbol))),
+			}},
+		},
+	})
+}
+
+// broadcast sends an event to every stream client (slow clients drop events)
+func (p *PaperExchange) broadcast(event interface{}) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	for ch := range p.streams {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// ============================================================================
+// ORDER HELPERS
+// ============================================================================
+
+func (o *paperOrder) isOpen() bool {
+	return o.Status == futures.OrderStatusTypeNew || o.Status == futures.OrderStatusTypePartiallyFilled
+}
+
+func (o *paperOrder) isConditional() bool {
+	switch o.OrigType {
+	case paperOrderStop, paperOrderStopMarket, paperOrderTakeProfit, paperOrderTakeProfitMarket:
+		return true
+	}
+	return false
+}
+
+// isWorkingLimit is a resting limit (plain, or a triggered STOP/TAKE_PROFIT)
+func (o *paperOrder) isWorkingLimit() bool {
+	return o.Type == futures.OrderTypeLimit && (!o.isConditional() || o.Triggered)
+}
+
+// shouldTrigger applies Binance trigger rules against the mark price
+func (o *paperOrder) shouldTrigger(mark float64) bool {
+	stopType := o.OrigType == paperOrderStop || o.OrigType == paperOrderStopMarket
+	if o.Side == futures.SideTypeBuy {
+		if stopType {
+			return mark >= o.StopPrice
+		}
+		return mark <= o.StopPrice
+	}
+	if stopType {
+		return mark <= o.StopPrice
+	}
+	return mark >= o.StopPrice
+}
+
+func (o *paperOrder) toOrder() *futures.Order {
+	avg := 0.0
+	if o.ExecQty > 0 {
+		avg = o.CumQuote / o.ExecQty
+	}
+	return &futures.Order{
+		Symbol:           o.Symbol,
+		OrderID:          o.ID,
+		ClientOrderID:    o.ClientID,
+		Price:            formatFloat(o.Price),
+		ReduceOnly:       o.ReduceOnly,
+		OrigQuantity:     formatFloat(o.OrigQty),
+		ExecutedQuantity: formatFloat(o.ExecQty),
+		CumQuantity:      formatFloat(o.ExecQty),
+		CumQuote:         formatFloat(o.CumQuote),
+		Status:           o.Status,
+		TimeInForce:      o.TimeInForce,
+		Type:             o.Type,
+		OrigType:         o.OrigType,
+		Side:             o.Side,
+		StopPrice:        formatFloat(o.StopPrice),
+		Time:             o.Time,
+		UpdateTime:       o.UpdateTime,
+		WorkingType:      o.WorkingType,
+		AvgPrice:         formatFloat(avg),
+		PositionSide:     futures.PositionSideTypeBoth,
+		ClosePosition:    o.ClosePos,
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
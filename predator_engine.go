@@ -11,82 +11,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
-	"github.com/gorilla/websocket"
 )
 
 // ==========================================
-// 1. GLOBAL EXPOSURE GUARD
-// ==========================================
-
-type GlobalExposureGuard struct {
-	mu            sync.Mutex
-	MaxConcurrent int                  // Max active scalps (Hard limit: 2)
-	ActiveTrades  map[string]float64   // Symbol -> Notional Value
-	BlockedUntil  map[string]time.Time // Symbol -> Cooldown Time
-	TotalLimit    float64              // Total Notional Limit
-}
-
-func NewGlobalExposureGuard(maxConcurrent int, totalLimit float64) *GlobalExposureGuard {
-	return &GlobalExposureGuard{
-		MaxConcurrent: maxConcurrent,
-		ActiveTrades:  make(map[string]float64),
-		BlockedUntil:  make(map[string]time.Time),
-		TotalLimit:    totalLimit,
-	}
-}
-
-// CanEnter checks if we can open a new trade based on limits
-func (g *GlobalExposureGuard) CanEnter(symbol string, requiredNotional float64) bool {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	// 0. Check Cooldown
-	if until, ok := g.BlockedUntil[symbol]; ok {
-		if time.Now().Before(until) {
-			return false // Silently blocked
-		}
-		delete(g.BlockedUntil, symbol) // Cleanup
-	}
-
-	// 1. Check Concurrent Limit
-	if len(g.ActiveTrades) >= g.MaxConcurrent {
-		return false
-	}
-
-	// 2. Strict Exposure Cap (Total Notional)
-	currentNotional := 0.0
-	for _, notional := range g.ActiveTrades {
-		currentNotional += notional
-	}
-
-	totalNotional := currentNotional + requiredNotional
-
-	if totalNotional > g.TotalLimit {
-		needed := totalNotional - g.TotalLimit
-		log.Printf("🛑 GUARD: Blocked %s. Needs $%.2f more room in Notional Limit ($%.2f > $%.2f).", symbol, needed, totalNotional, g.TotalLimit)
-		g.BlockedUntil[symbol] = time.Now().Add(30 * time.Second)
-		return false
-	}
-
-	return true
-}
-
-func (g *GlobalExposureGuard) RegisterTrade(symbol string, notional float64) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	g.ActiveTrades[symbol] = notional
-}
-
-func (g *GlobalExposureGuard) ReleaseTrade(symbol string) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	delete(g.ActiveTrades, symbol)
-}
-
-// ==========================================
-// 2. MULTI-ASSET PREDATOR MANAGER
+// 1. MULTI-ASSET PREDATOR MANAGER
 // ==========================================
 
 // WhaleCandidate tracks potential whale movements for verification
@@ -100,7 +29,7 @@ type WhaleCandidate struct {
 
 // PredatorEngine is now the Multi-Asset Manager
 type PredatorEngine struct {
-	client        *futures.Client
+	gateway       Gateway
 	trendAnalyzer *TrendAnalyzer
 	active        bool
 	mu            sync.Mutex // General state mutex
@@ -114,15 +43,17 @@ type PredatorEngine struct {
 	whaleCandidates map[string]*WhaleCandidate // Verification Map
 	TradeCooldowns  map[string]time.Time       // Signal Debounce (60s)
 
-	env              *PredatorEnv
 	DailyRealizedPnL float64
 
 	// Safety Mode Logic
 	ConsecutiveLosses int
 	SafetyModeUntil   time.Time
 
-	// Guard
-	guard *GlobalExposureGuard
+	// Time Source (Wall Clock Live, Event Time in Backtests)
+	clock Clock
+
+	// Account Risk (Shared with ExecutionService)
+	risk *RiskManager
 
 	// Notifications
 	notifier *NotificationService
@@ -135,19 +66,48 @@ type PredatorEngine struct {
 
 	// Precision Info
 	symbolInfo map[string]SymbolProfile // Symbol -> TickSize/StepSize
+
+	// Local Order Books (Shared with Analyzer)
+	books *OrderBookManager
+
+	// Monitored Universe (Shared)
+	registry *SymbolRegistry
+
+	// Market Data Bus (Trades + Book Ticks from the shared feed)
+	bus *MarketBus
+
+	// Funding Gate (nil = no gate)
+	funding *FundingMonitor
+
+	// Open Interest (Signal.OI, nil = not tracked)
+	openInterest *OpenInterestMonitor
+
+	// Durable State (Survives Redeploys)
+	store             *StateStore
+	saveMu            sync.Mutex                   // Serializes snapshot + Save
+	restoredPositions map[string]*PredatorPosition // Short Symbol -> Last journaled position (for reconcile)
 }
 
 // PredatorWorker handles a single symbol stream
 type PredatorWorker struct {
 	Symbol string
 	Engine *PredatorEngine
-	Kill   chan bool
+	Kill   chan struct{}
 }
 
-type PredatorEnv struct {
-	ApiKey    string
-	ApiSecret string
-}
+// Wall scan range around mid price (0.2%)
+const predatorWallScanPct = 0.002
+
+// Wall Detection (Tier 2 minimum) + Persistence before evaluation
+const (
+	predatorWallMinNotional = 250000.0
+	predatorConfirmDelay    = 800 * time.Millisecond // PREDATOR SPEED TUNING
+	predatorFlickerGrace    = 1 * time.Second        // Wall may vanish this long before reset
+	predatorStopRisk        = 5.0                    // $ lost at the stop (distance = risk / qty)
+	predatorTradeCooldown   = 60 * time.Second       // Signal debounce per symbol
+	predatorMaxSlippage     = 0.0002                 // Abort entry if price drifted > 0.02%
+	predatorLockdown        = 2 * time.Hour          // Safety mode after 3 consecutive losses
+)
 
 type PredatorPosition struct {
 	Symbol     string
@@ -167,41 +127,53 @@ type PredatorPosition struct {
 	TPOrderID      int64
 	SLOrderID      int64
 	IsBreakEvenSet bool
+
+	RealizedPnL float64 // Summed from user stream fills (net of fees)
 }
 
 // NewPredatorEngine initializes the manager
-func NewPredatorEngine(apiKey, apiSecret string, ta *TrendAnalyzer, maxExposure float64, maxConcurrent int, notifier *NotificationService, leverage int, totalNotionalLimit float64, hub *SignalHub) *PredatorEngine {
-	client := binance.NewFuturesClient(apiKey, apiSecret)
+func NewPredatorEngine(gateway Gateway, ta *TrendAnalyzer, maxExposure float64, maxConcurrent int, notifier *NotificationService, leverage int, totalNotionalLimit, maxDailyLoss float64, hub *SignalHub, books *OrderBookManager, registry *SymbolRegistry, risk *RiskManager) *PredatorEngine {
+	// Predator's share of the account: Max Concurrent Trades + Notional Limit + Daily Loss
+	risk.SetEngineBudget(RiskEnginePredator, EngineBudget{
+		MaxPositions: maxConcurrent,
+		MaxNotional:  totalNotionalLimit,
+		MaxDailyLoss: maxDailyLoss,
+	})
+
 	return &PredatorEngine{
-		client:          client,
-		trendAnalyzer:   ta,
-		active:          true,
-		positions:       make(map[string]*PredatorPosition),
-		currentPrices:   make(map[string]float64),
-		whaleCandidates: make(map[string]*WhaleCandidate),
-		TradeCooldowns:  make(map[string]time.Time),
-		workers:         make(map[string]*PredatorWorker),
-		env: &PredatorEnv{
-			ApiKey:    apiKey,
-			ApiSecret: apiSecret,
-		},
+		gateway:           NewRiskGateway(gateway, risk, RiskEnginePredator), // Every order passes the RiskManager
+		trendAnalyzer:     ta,
+		active:            true,
+		positions:         make(map[string]*PredatorPosition),
+		currentPrices:     make(map[string]float64),
+		whaleCandidates:   make(map[string]*WhaleCandidate),
+		TradeCooldowns:    make(map[string]time.Time),
+		workers:           make(map[string]*PredatorWorker),
 		DailyRealizedPnL:  0.0,
 		ConsecutiveLosses: 0,
-		// Initialize Guard: Max Concurrent Trades
-		// Initialize Guard: Max Concurrent Trades
-		guard:      NewGlobalExposureGuard(maxConcurrent, totalNotionalLimit),
-		notifier:   notifier,
-		Leverage:   leverage,
-		hub:        hub,
-		symbolInfo: make(map[string]SymbolProfile),
+		risk:              risk,
+		notifier:          notifier,
+		Leverage:          leverage,
+		hub:               hub,
+		symbolInfo:        make(map[string]SymbolProfile),
+		books:             books,
+		registry:          registry,
+		clock:             RealClock,
 	}
 }
 
+// SetClock swaps the time source for candidates, cooldowns and safety mode (call before Start)
+func (pe *PredatorEngine) SetClock(c Clock) {
+	pe.mu.Lock()
+	pe.clock = clockOrReal(c)
+	pe.mu.Unlock()
+}
+
 // IsSafetyMode checks if we are in protective mode
 func (pe *PredatorEngine) IsSafetyMode() bool {
 	pe.mu.Lock()
 	defer pe.mu.Unlock()
-	return time.Now().Before(pe.SafetyModeUntil)
+	return pe.clock.Now().Before(pe.SafetyModeUntil)
 }
 
 // Start launches the workers
@@ -211,15 +183,53 @@ func (pe *PredatorEngine) Start() {
 	// 0. Fetch Exchange Info (Precision)
 	pe.FetchExchangeInfo()
 
+	// 0.5 Adopt positions that survived a restart (tagged TP/SL orders)
+	pe.reconcile()
+
 	// 1. Start Position Monitor (Global)
 	go pe.monitorPositions()
 
-	// 2. Launch Independent Workers
-	targets := []string{
-		"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT", "XRPUSDT",
-		"ADAUSDT", "DOGEUSDT", "AVAXUSDT", "TRXUSDT", "PEPEUSDT",
+	// 1.5 Workers read trades and book ticks from the shared bus
+	if pe.bus == nil {
+		log.Println("⚠️ PREDATOR: No market bus attached. Workers will idle.")
 	}
-	for _, sym := range targets {
+
+	// 2. Launch Independent Workers (one per registry symbol)
+	changes := pe.registry.Subscribe()
+	pe.syncWorkers()
+
+	// 3. Follow runtime universe changes
+	go func() {
+		for range changes {
+			pe.syncWorkers()
+		}
+	}()
+}
+
+// syncWorkers starts workers for new registry symbols and stops removed ones
+func (pe *PredatorEngine) syncWorkers() {
+	wanted := make(map[string]bool)
+	for _, sym := range pe.registry.Symbols() {
+		wanted[sym] = true
+	}
+
+	pe.mu.Lock()
+	var toStart []string
+	for sym := range wanted {
+		if _, running := pe.workers[sym]; !running {
+			toStart = append(toStart, sym)
+		}
+	}
+	for sym, worker := range pe.workers {
+		if !wanted[sym] {
+			log.Printf("🦖 PREDATOR: Stopping Worker for %s", sym)
+			close(worker.Kill)
+			delete(pe.workers, sym)
+		}
+	}
+	pe.mu.Unlock()
+
+	for _, sym := range toStart {
 		pe.startWorker(sym)
 	}
 }
@@ -228,7 +238,7 @@ func (pe *PredatorEngine) startWorker(symbol string) {
 	worker := &PredatorWorker{
 		Symbol: symbol,
 		Engine: pe,
-		Kill:   make(chan bool),
+		Kill:   make(chan struct{}),
 	}
 	pe.mu.Lock()
 	pe.workers[symbol] = worker
@@ -237,82 +247,36 @@ func (pe *PredatorEngine) startWorker(symbol string) {
 	go worker.Run()
 }
 
-// Run is the main loop for a single symbol
+// Run is the main loop for a single symbol: Binance trades keep the price
+// current, book ticks trigger a whale scan
 func (w *PredatorWorker) Run() {
 	log.Printf("🦖 PREDATOR: Starting Worker for %s", w.Symbol)
+	if w.Engine.bus == nil {
+		<-w.Kill
+		return
+	}
 
-	// Stream URL
-	lowerSym := strings.ToLower(w.Symbol)
-	streamName := fmt.Sprintf("%s@depth5@100ms/%s@aggTrade", lowerSym, lowerSym)
-
-	url := fmt.Sprintf("wss://fstream.binance.com/stream?streams=%s", streamName)
+	sub := w.Engine.bus.Subscribe("Predator "+w.Symbol, MarketSubOptions{
+		Symbols:    []string{w.Symbol},
+		TradeQueue: 256,
+		BookQueue:  1, // Coalesced: the scan reads the latest book
+	})
+	defer sub.Close()
 
-	// Retry Loop
+	shortSym := extractSymbol(strings.ToLower(w.Symbol))
 	for {
 		select {
 		case <-w.Kill:
 			return
-		default:
-			// Connect
-			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-			if err != nil {
-				time.Sleep(5 * time.Second)
-				continue
-			}
-
-			// Read Loop
-			for {
-				_, message, err := conn.ReadMessage()
-				if err != nil {
-					conn.Close()
-					break
-				}
-				w.Engine.handleMessage(message, w.Symbol)
-			}
-		}
-	}
-}
-
-// handleMessage processes message for specific symbol context
-func (pe *PredatorEngine) handleMessage(msg []byte, ctxSymbol string) {
-	var combined binanceCombinedMsg
-	if err := json.Unmarshal(msg, &combined); err != nil {
-		return
-	}
-
-	streamSym := extractSymbol(combined.Stream)
-
-	if len(combined.Data) > 0 {
-		isDepth := false
-		sLen := len(combined.Stream)
-		if sLen >= 5 && combined.Stream[sLen-5:] == "depth" {
-			isDepth = true
-		}
-		if sLen >= 5 && combined.Stream[sLen-5:] == "100ms" {
-			isDepth = true
-		}
-
-		if isDepth {
-			var depth binanceDepthData
-			if err := json.Unmarshal(combined.Data, &depth); err == nil {
-				pe.scanForWhales(streamSym, depth)
-			}
-			return
-		}
-
-		isTrade := false
-		if sLen >= 8 && combined.Stream[sLen-8:] == "aggTrade" {
-			isTrade = true
-		}
-
-		if isTrade {
-			var trade binanceTradeData
-			if err := json.Unmarshal(combined.Data, &trade); err == nil {
-				price, _ := strconv.ParseFloat(trade.Price, 64)
-				pe.mu.Lock()
-				pe.currentPrices[streamSym] = price
-				pe.mu.Unlock()
+		case trade := <-sub.Trades():
+			if trade.Exchange != "Binance" {
+				continue // Orders go to Binance: track its price only
 			}
+			w.Engine.mu.Lock()
+			w.Engine.currentPrices[shortSym] = trade.Price
+			w.Engine.mu.Unlock()
+		case <-sub.Books():
+			w.Engine.scanForWhales(shortSym)
 		}
 	}
 }
@@ -321,7 +285,7 @@ func (pe *PredatorEngine) handleMessage(msg []byte, ctxSymbol string) {
 // ================================
 
 func (pe *PredatorEngine) FetchExchangeInfo() {
-	res, err := pe.client.NewExchangeInfoService().Do(context.Background())
+	res, err := pe.gateway.ExchangeInfo(context.Background())
 	if err != nil {
 		log.Printf("⚠️ Failed to fetch Exchange Info: %v", err)
 		return
@@ -377,53 +341,27 @@ func (pe *PredatorEngine) FormatQty(symbol string, qty float64) string {
 	return fmt.Sprintf("%.*f", prec, rounded)
 }
 
-// 3. PRIORITY RANKING & EXECUTION
-func (pe *PredatorEngine) scanForWhales(symbol string, depth binanceDepthData) {
+// 2. PRIORITY RANKING & EXECUTION
+func (pe *PredatorEngine) scanForWhales(symbol string) {
 	var potentialSignal *WhaleCandidate
 	var side string
 
-	// Calc Volumes for Ratio
-	totalBidVol := 0.0
-	totalAskVol := 0.0
-
-	for _, b := range depth.Bids {
-		p, _ := strconv.ParseFloat(b[0], 64)
-		q, _ := strconv.ParseFloat(b[1], 64)
-		totalBidVol += p * q
+	if pe.books == nil {
+		return
 	}
-	for _, a := range depth.Asks {
-		p, _ := strconv.ParseFloat(a[0], 64)
-		q, _ := strconv.ParseFloat(a[1], 64)
-		totalAskVol += p * q
+	bids, asks := pe.books.LevelsWithin(symbol, predatorWallScanPct)
+	if len(bids) == 0 || len(asks) == 0 {
+		return
 	}
 
-	// Check Bids for Candidates
-	for _, b := range depth.Bids {
-		price, _ := strconv.ParseFloat(b[0], 64)
-		qty, _ := strconv.ParseFloat(b[1], 64)
-		notional := price * qty
-		// Tiered Logic: Look for > $250k (Tier 2 minimum)
-		if notional > 250000 {
-			potentialSignal = &WhaleCandidate{Symbol: symbol, Side: "LONG", Volume: notional}
-			side = "LONG"
-			break
-		}
-	}
-	// Check Asks
-	if potentialSignal == nil {
-		for _, a := range depth.Asks {
-			price, _ := strconv.ParseFloat(a[0], 64)
-			qty, _ := strconv.ParseFloat(a[1], 64)
-			notional := price * qty
-			if notional > 250000 {
-				potentialSignal = &WhaleCandidate{Symbol: symbol, Side: "SHORT", Volume: notional}
-				side = "SHORT"
-				break
-			}
-		}
+	wall, found := findWall(bids, asks)
+	if found {
+		potentialSignal = &WhaleCandidate{Symbol: symbol, Side: wall.Side, Volume: wall.Volume}
+		side = wall.Side
 	}
 
 	// Whale Verification Logic
+	now := pe.clock.Now()
 	if potentialSignal != nil {
 		pe.mu.Lock()
 		candidate, exists := pe.whaleCandidates[symbol]
@@ -432,43 +370,26 @@ func (pe *PredatorEngine) scanForWhales(symbol string, depth binanceDepthData) {
 			pe.whaleCandidates[symbol] = &WhaleCandidate{
 				Symbol:    symbol,
 				Side:      side,
-				FirstSeen: time.Now(),
-				LastSeen:  time.Now(),
+				FirstSeen: now,
+				LastSeen:  now,
 				Volume:    potentialSignal.Volume,
 			}
 		} else {
 			// update last seen
-			candidate.LastSeen = time.Now()
+			candidate.LastSeen = now
 			candidate.Volume = potentialSignal.Volume // Update volume
 
 			// PREDATOR SPEED TUNING: 0.8 SECONDS
-			if time.Since(candidate.FirstSeen) >= 800*time.Millisecond {
+			if now.Sub(candidate.FirstSeen) >= predatorConfirmDelay {
 				// VALID WHALE!
 				pe.mu.Unlock() // Unlock before evaluation
 
-				// Calculate Ratio based on side
-				ratio := 0.0
-				if side == "LONG" {
-					if totalAskVol > 0 {
-						ratio = totalBidVol / totalAskVol
-					}
-				} else {
-					if totalBidVol > 0 {
-						ratio = totalAskVol / totalBidVol
-					}
-				}
-
-				// Get current price
-				price := 0.0
-				if side == "LONG" {
-					price, _ = strconv.ParseFloat(depth.Bids[0][0], 64)
-				} else {
-					price, _ = strconv.ParseFloat(depth.Asks[0][0], 64)
-				}
+				ratio := wall.Ratio
+				price := wall.Price
 
 				// 📡 EARLY BROADCAST TO SIGNAL HUB (Visibility > Execution)
 				// Create Signal Object
-				ts := time.Now().UnixMilli()
+				ts := now.UnixMilli()
 				sig := Signal{
 					ID:        fmt.Sprintf("SIG-%d-%s", ts, symbol),
 					Symbol:    symbol,
@@ -482,6 +403,8 @@ func (pe *PredatorEngine) scanForWhales(symbol string, depth binanceDepthData) {
 					Target:    price * 1.01,      // Placeholder
 					Timestamp: ts,
 					Status:    "DETECTED", // Initial Status
+					Funding:   pe.funding.Info(symbol),
+					OI:        pe.openInterest.Delta(symbol),
 				}
 
 				// Broadcast JSON
@@ -548,7 +471,7 @@ func (pe *PredatorEngine) scanForWhales(symbol string, depth binanceDepthData) {
 		pe.mu.Lock()
 		if c, exists := pe.whaleCandidates[symbol]; exists {
 			// Tolerance: 1 second flicker allowed
-			if time.Since(c.LastSeen) > 1*time.Second {
+			if now.Sub(c.LastSeen) > predatorFlickerGrace {
 				delete(pe.whaleCandidates, symbol)
 			}
 		}
@@ -556,6 +479,53 @@ func (pe *PredatorEngine) scanForWhales(symbol string, depth binanceDepthData) {
 	}
 }
 
+// predatorWall is a resting wall inside the scan range and the book imbalance behind it
+type predatorWall struct {
+	Side   string  // "LONG" (bid wall) or "SHORT" (ask wall)
+	Volume float64 // Wall notional
+	Ratio  float64 // Wall-side / opposite-side notional within range
+	Price  float64 // Best price on the wall's side (entry reference)
+}
+
+// findWall returns the first level above predatorWallMinNotional (bids first, best first)
+func findWall(bids, asks []BookLevel) (predatorWall, bool) {
+	if len(bids) == 0 || len(asks) == 0 {
+		return predatorWall{}, false
+	}
+
+	// Calc Volumes for Ratio
+	totalBidVol := 0.0
+	totalAskVol := 0.0
+	for _, b := range bids {
+		totalBidVol += b.Price * b.Qty
+	}
+	for _, a := range asks {
+		totalAskVol += a.Price * a.Qty
+	}
+
+	// Check Bids for Candidates
+	for _, b := range bids {
+		if notional := b.Price * b.Qty; notional > predatorWallMinNotional {
+			ratio := 0.0
+			if totalAskVol > 0 {
+				ratio = totalBidVol / totalAskVol
+			}
+			return predatorWall{Side: "LONG", Volume: notional, Ratio: ratio, Price: bids[0].Price}, true
+		}
+	}
+	// Check Asks
+	for _, a := range asks {
+		if notional := a.Price * a.Qty; notional > predatorWallMinNotional {
+			ratio := 0.0
+			if totalBidVol > 0 {
+				ratio = totalAskVol / totalBidVol
+			}
+			return predatorWall{Side: "SHORT", Volume: notional, Ratio: ratio, Price: asks[0].Price}, true
+		}
+	}
+	return predatorWall{}, false
+}
+
 // evaluteCandidate with Tiered Entry Logic
 func (pe *PredatorEngine) evaluateCandidate(symbol, side string, price, volume, ratio float64) *PredatorPosition {
 	// 1. Trend Lock (Strict)
@@ -583,6 +553,12 @@ func (pe *PredatorEngine) evaluateCandidate(symbol, side string, price, volume,
 		return nil
 	}
 
+	// 💸 Funding Gate: no entry right before a large payment we'd make
+	if err := pe.funding.CheckEntry(symbol, side); err != nil {
+		log.Printf("💸 FUNDING GATE: %s %s skipped: %v", side, symbol, err)
+		return nil
+	}
+
 	// 2. Dynamic Thresholds (Relaxed for Momentum)
 	isSafety := pe.IsSafetyMode()
 
@@ -719,7 +695,7 @@ func (pe *PredatorEngine) CheckLivePosition(symbol string) bool {
 	normSymbol := NormalizeSymbol(symbol)
 
 	// Quick API Call
-	res, err := pe.client.NewGetAccountService().Do(context.Background())
+	res, err := pe.gateway.Account(context.Background())
 	if err != nil {
 		log.Printf("⚠️ Account Check Failed: %v", err)
 		return true // Fail safe: Assume position exists to block trade
@@ -740,7 +716,7 @@ func (pe *PredatorEngine) attemptExecution(candidate *PredatorPosition) {
 	pe.mu.Lock()
 	// 0. DEBOUNCE: Signal Cooldown (60s)
 	if cooldown, ok := pe.TradeCooldowns[candidate.Symbol]; ok {
-		if time.Now().Before(cooldown) {
+		if pe.clock.Now().Before(cooldown) {
 			pe.mu.Unlock()
 			return
 		}
@@ -777,8 +753,9 @@ func (pe *PredatorEngine) attemptExecution(candidate *PredatorPosition) {
 			return
 		}
 
-		// 3. Global Guard Check
-		if !pe.guard.CanEnter(candidate.Symbol, targetNotional) {
+		// 3. Account Risk Check (Reserved for real by the RiskGateway on order)
+		if err := pe.risk.Check(RiskEnginePredator, NormalizeSymbol(candidate.Symbol), targetNotional); err != nil {
+			log.Printf("🛑 PREDATOR: %v. Skipping attack.", err) // Notified by the RiskManager
 			return
 		}
 
@@ -803,7 +780,7 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 	currentPrice, ok := pe.currentPrices[pos.Symbol]
 	if ok && currentPrice > 0 {
 		diff := math.Abs(currentPrice-pos.Entry) / pos.Entry
-		if diff > 0.0002 { // 0.02% Limit
+		if diff > predatorMaxSlippage { // 0.02% Limit
 			log.Printf("🛑 SLIPPAGE GUARD: Aborted %s. Price drifted %.4f%% (> 0.02%% Limit).", pos.Symbol, diff*100)
 			return
 		}
@@ -813,11 +790,23 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 
 	log.Printf("🦖 PREDATOR SNIPER ATTACK: %s %s (Vol: $%.0f) [Size: $%.2f]", pos.Side, pos.Symbol, pos.Score, targetNotional)
 
-	// 1. Set Leverage
-	pe.client.NewChangeLeverageService().Symbol(normSymbol).Leverage(pos.Leverage).Do(context.Background())
+	// 1. Set Leverage (RiskGateway enforces the max)
+	if err := pe.gateway.ChangeLeverage(context.Background(), normSymbol, pos.Leverage); err != nil {
+		log.Printf("⚠️ Failed to set Leverage to %dx for %s: %v. ABORTING.", pos.Leverage, normSymbol, err)
+		if _, isRisk := err.(*RiskRejection); !isRisk && pe.notifier != nil { // Risk rejections are notified by the RiskManager
+			pe.notifier.Notify(fmt.Sprintf("⚠️ *PREDATOR ABORTED* %s\nLeverage %dx failed: %v", normSymbol, pos.Leverage, err))
+		}
+		return
+	}
 
-	// 2. Force Isolated
-	pe.client.NewChangeMarginTypeService().Symbol(normSymbol).MarginType(futures.MarginTypeIsolated).Do(context.Background())
+	// 2. Force Isolated (already isolated is fine)
+	if err := pe.gateway.ChangeMarginType(context.Background(), normSymbol, futures.MarginTypeIsolated); err != nil && !strings.Contains(err.Error(), "No need to change margin type") {
+		log.Printf("⚠️ CRITICAL: Failed to set ISOLATED margin for %s: %v. ABORTING.", normSymbol, err)
+		if pe.notifier != nil {
+			pe.notifier.Notify(fmt.Sprintf("⚠️ *PREDATOR ABORTED* %s\nIsolated margin failed: %v", normSymbol, err))
+		}
+		return
+	}
 
 	// 3. Market Entry
 	qty := targetNotional / pos.Entry
@@ -829,13 +818,12 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 	// Use Dynamic Formatting
 	qtyStr := pe.FormatQty(normSymbol, qty)
 
-	cOrder := pe.client.NewCreateOrderService().
-		Symbol(normSymbol).
-		Side(ordSide).
-		Type(futures.OrderTypeMarket).
-		Quantity(qtyStr)
-
-	res, err := cOrder.Do(context.Background())
+	res, err := pe.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:   normSymbol,
+		Side:     ordSide,
+		Type:     futures.OrderTypeMarket,
+		Quantity: qtyStr,
+	})
 	if err != nil {
 		log.Printf("⚠️ Exec Fail [%s]: %v", normSymbol, err)
 		time.Sleep(100 * time.Millisecond)
@@ -853,13 +841,13 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 
 	// Set Cooldown
 	pe.mu.Lock()
-	pe.TradeCooldowns[pos.Symbol] = time.Now().Add(60 * time.Second)
+	pe.TradeCooldowns[pos.Symbol] = pe.clock.Now().Add(predatorTradeCooldown)
 	pe.mu.Unlock()
 
 	// Update Position
 	pos.Entry = avgPrice
 	pos.Size = qty
-	pos.StartTime = time.Now()
+	pos.StartTime = pe.clock.Now()
 	if pos.Leverage > 0 {
 		pos.MarginUsed = (avgPrice * qty) / float64(pos.Leverage)
 	} else {
@@ -867,7 +855,7 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 	}
 
 	// 🛰️ SNIPER LOGIC: NET PROFIT & OCO
-	stopDist := 5.0 / qty
+	stopDist := predatorStopRisk / qty
 
 	// 1. Calculate Net Take Profit
 	tpDist := pe.CalculateNetTP(pos.Entry, qty, pos.TakeProfit) // Returns distance
@@ -892,14 +880,15 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 	priceStr := pe.FormatPrice(normSymbol, tpPrice)
 	qtyStr = pe.FormatQty(normSymbol, qty) // Reuse formatted qty
 
-	tpRes, err := pe.client.NewCreateOrderService().
-		Symbol(normSymbol).
-		Side(tpSide).
-		Type(futures.OrderTypeLimit).
-		TimeInForce(futures.TimeInForceTypeGTX). // Maker Only
-		Quantity(qtyStr).
-		Price(priceStr).
-		Do(context.Background())
+	tpRes, err := pe.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:        normSymbol,
+		Side:          tpSide,
+		Type:          futures.OrderTypeLimit,
+		TimeInForce:   futures.TimeInForceTypeGTX, // Maker Only
+		Quantity:      qtyStr,
+		Price:         priceStr,
+		ClientOrderID: newClientOrderID(predatorTPPrefix), // Adopted on restart
+	})
 
 	if err == nil {
 		pos.TPOrderID = tpRes.OrderID
@@ -916,16 +905,17 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 	stopPriceStr := pe.FormatPrice(normSymbol, slPrice)
 	limitPriceStr := pe.FormatPrice(normSymbol, slLimitPrice)
 
-	slRes, err := pe.client.NewCreateOrderService().
-		Symbol(normSymbol).
-		Side(tpSide).
-		Type(futures.OrderType("STOP")). // Changed to STOP Limit
-		Quantity(qtyStr).
-		StopPrice(stopPriceStr).
-		Price(limitPriceStr). // Required
-		WorkingType(futures.WorkingTypeMarkPrice).
-		PriceProtect(true).
-		Do(context.Background())
+	slRes, err := pe.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:        normSymbol,
+		Side:          tpSide,
+		Type:          futures.OrderType("STOP"), // Changed to STOP Limit
+		Quantity:      qtyStr,
+		StopPrice:     stopPriceStr,
+		Price:         limitPriceStr, // Required
+		WorkingType:   futures.WorkingTypeMarkPrice,
+		PriceProtect:  true,
+		ClientOrderID: newClientOrderID(predatorSLPrefix),
+	})
 
 	if err == nil {
 		pos.SLOrderID = slRes.OrderID
@@ -936,7 +926,7 @@ func (pe *PredatorEngine) executeTrade(pos *PredatorPosition) {
 	pe.positions[pos.Symbol] = pos
 	pe.mu.Unlock()
 
-	pe.guard.RegisterTrade(pos.Symbol, pos.MarginUsed)
+	pe.saveState()
 
 	// BROADCAST SHIELD STATUS (Grey = Active, Not Secured Yet)
 	if pe.hub != nil {
@@ -965,16 +955,13 @@ func (pe *PredatorEngine) monitorPositions() {
 		case <-statusTicker.C:
 			pe.mu.Lock()
 			status := "🔍 Hunting:"
-			targets := []string{
-				"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT", "XRPUSDT",
-				"ADAUSDT", "DOGEUSDT", "AVAXUSDT", "TRXUSDT", "PEPEUSDT",
-			}
-			for _, sym := range targets {
+			for _, sym := range pe.registry.Symbols() {
+				shortSym := Instruments.Canonical(sym).Key() // Positions are keyed "BTC"
 				state := "WAITING"
-				if _, ok := pe.positions[sym]; ok {
+				if _, ok := pe.positions[shortSym]; ok {
 					state = "ACTIVE"
 				}
-				status += fmt.Sprintf(" [%s: %s]", sym[:3], state)
+				status += fmt.Sprintf(" [%s: %s]", shortSym, state)
 			}
 			log.Println(status)
 			pe.mu.Unlock()
@@ -984,7 +971,7 @@ func (pe *PredatorEngine) monitorPositions() {
 			for sym, pos := range pe.positions {
 				// Timeout (Force Exit after 60 mins for Scalp?? Or keep open?)
 				// Sniper mode usually implies waiting for targets. Removing hard timeout or extending it.
-				if time.Since(pos.StartTime).Seconds() > 3600 {
+				if pe.clock.Now().Sub(pos.StartTime).Seconds() > 3600 {
 					log.Printf("⌛ TIMEOUT: %s", sym)
 					go pe.closePosition(pos, "TIMEOUT")
 				}
@@ -1026,7 +1013,7 @@ func (pe *PredatorEngine) monitorPositions() {
 
 							// Cancel Old SL
 							if pos.SLOrderID != 0 {
-								pe.client.NewCancelOrderService().Symbol(NormalizeSymbol(pos.Symbol)).OrderID(pos.SLOrderID).Do(context.Background())
+								pe.gateway.CancelOrder(context.Background(), NormalizeSymbol(pos.Symbol), pos.SLOrderID)
 							}
 
 							// Determine tpSide for the new SL order
@@ -1041,14 +1028,15 @@ func (pe *PredatorEngine) monitorPositions() {
 
 							// Place New SL (STOP MARKET)
 							// Fixed -4120: Using ClosePosition(true) instead of Quantity
-							slRes, err := pe.client.NewCreateOrderService().
-								Symbol(normSymbol).
-								Side(tpSide).
-								Type(futures.OrderType("STOP_MARKET")).
-								StopPrice(bePriceStr).
-								ClosePosition(true). // AUTO-CLOSE
-								WorkingType(futures.WorkingTypeMarkPrice).
-								Do(context.Background())
+							slRes, err := pe.gateway.PlaceOrder(context.Background(), OrderRequest{
+								Symbol:        normSymbol,
+								Side:          tpSide,
+								Type:          futures.OrderType("STOP_MARKET"),
+								StopPrice:     bePriceStr,
+								ClosePosition: true, // AUTO-CLOSE
+								WorkingType:   futures.WorkingTypeMarkPrice,
+								ClientOrderID: newClientOrderID(predatorSLPrefix),
+							})
 
 							if err == nil {
 								pos.SLOrderID = slRes.OrderID
@@ -1082,9 +1070,7 @@ func (pe *PredatorEngine) monitorPositions() {
 					}
 
 					// Note: TP/SL are handled by Server Orders (OCO).
-					// But we still monitor for manual or unexpected fills via WebSocket (User Data Stream not impl here yet).
-					// If we detect price passed TP/SL significantly, we can cleanup.
-					// For now, rely on Server Orders.
+					// Fills and exchange-side closes arrive via the User Data Stream (ListenUserStream).
 				}
 			}
 			pe.mu.Unlock()
@@ -1097,7 +1083,7 @@ func (pe *PredatorEngine) MoveStopToBreakEven(pos *PredatorPosition) {
 
 	// 1. Cancel Old SL
 	if pos.SLOrderID != 0 {
-		pe.client.NewCancelOrderService().Symbol(normSymbol).OrderID(pos.SLOrderID).Do(context.Background())
+		pe.gateway.CancelOrder(context.Background(), normSymbol, pos.SLOrderID)
 	}
 
 	// 2. Calc New SL = Entry + $2
@@ -1110,14 +1096,6 @@ func (pe *PredatorEngine) MoveStopToBreakEven(pos *PredatorPosition) {
 		bePrice = pos.Entry - bonus
 	}
 
-	prec := "%.3f"
-	if strings.Contains(normSymbol, "SOL") {
-		prec = "%.0f"
-	}
-	if strings.Contains(normSymbol, "ETH") {
-		prec = "%.2f"
-	}
-
 	tpSide := futures.SideTypeSell
 	if pos.Side == "SHORT" {
 		tpSide = futures.SideTypeBuy
@@ -1125,15 +1103,16 @@ func (pe *PredatorEngine) MoveStopToBreakEven(pos *PredatorPosition) {
 
 	// 3. Place New STOP MARKET (Aggressive)
 	// Fixed -4120: Using ClosePosition(true)
-	res, err := pe.client.NewCreateOrderService().
-		Symbol(normSymbol).
-		Side(tpSide).
-		Type(futures.OrderType("STOP_MARKET")).
-		StopPrice(fmt.Sprintf(prec, bePrice)).
-		ClosePosition(true). // AUTO-CLOSE
-		WorkingType(futures.WorkingTypeMarkPrice).
-		PriceProtect(true).
-		Do(context.Background())
+	res, err := pe.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:        normSymbol,
+		Side:          tpSide,
+		Type:          futures.OrderType("STOP_MARKET"),
+		StopPrice:     pe.FormatPrice(normSymbol, bePrice),
+		ClosePosition: true, // AUTO-CLOSE
+		WorkingType:   futures.WorkingTypeMarkPrice,
+		PriceProtect:  true,
+		ClientOrderID: newClientOrderID(predatorSLPrefix),
+	})
 
 	if err == nil {
 		pe.mu.Lock()
@@ -1154,10 +1133,10 @@ func (pe *PredatorEngine) closePosition(pos *PredatorPosition, reason string) {
 
 	// 1. Cancel Open Orders (TP/SL)
 	if pos.TPOrderID != 0 {
-		pe.client.NewCancelOrderService().Symbol(normSymbol).OrderID(pos.TPOrderID).Do(context.Background())
+		pe.gateway.CancelOrder(context.Background(), normSymbol, pos.TPOrderID)
 	}
 	if pos.SLOrderID != 0 {
-		pe.client.NewCancelOrderService().Symbol(normSymbol).OrderID(pos.SLOrderID).Do(context.Background())
+		pe.gateway.CancelOrder(context.Background(), normSymbol, pos.SLOrderID)
 	}
 
 	// 2. Market Close
@@ -1166,18 +1145,18 @@ func (pe *PredatorEngine) closePosition(pos *PredatorPosition, reason string) {
 		side = futures.SideTypeBuy
 	}
 
-	pe.client.NewCreateOrderService().
-		Symbol(normSymbol).
-		Side(side).
-		Type(futures.OrderTypeMarket).
-		Quantity(fmt.Sprintf("%.3f", pos.Size)).
-		Do(context.Background())
+	pe.gateway.PlaceOrder(context.Background(), OrderRequest{
+		Symbol:   normSymbol,
+		Side:     side,
+		Type:     futures.OrderTypeMarket,
+		Quantity: fmt.Sprintf("%.3f", pos.Size),
+	})
 
 	pe.mu.Lock()
 	delete(pe.positions, pos.Symbol)
 	pe.mu.Unlock()
 
-	pe.guard.ReleaseTrade(pos.Symbol)
+	pe.risk.Release(RiskEnginePredator, NormalizeSymbol(pos.Symbol))
 
 	// Update Circuit Breaker
 	pe.mu.Lock()
@@ -1189,46 +1168,379 @@ func (pe *PredatorEngine) closePosition(pos *PredatorPosition, reason string) {
 		} else {
 			pnl = (pos.Entry - price) * pos.Size
 		}
-		pe.DailyRealizedPnL += pnl
+		pe.recordClose(pos, pnl, reason, "Est PnL")
+	}
+	pe.mu.Unlock()
+	pe.saveState()
+}
+
+// recordClose feeds a closed trade's PnL into the circuit breaker (caller holds pe.mu)
+func (pe *PredatorEngine) recordClose(pos *PredatorPosition, pnl float64, reason, label string) {
+	pe.DailyRealizedPnL += pnl
+	pe.risk.RecordPnL(RiskEnginePredator, pnl) // Account-level daily loss
+
+	if pnl < 0 {
+		pe.ConsecutiveLosses++
+		if pe.ConsecutiveLosses == 2 {
+			log.Printf("⚠️ STRIKE 2: Next trade reduced by 50%%.")
+		}
 
-		if pnl < 0 {
-			pe.ConsecutiveLosses++
-			if pe.ConsecutiveLosses == 2 {
-				log.Printf("⚠️ STRIKE 2: Next trade reduced by 50%%.")
+		if pe.ConsecutiveLosses >= 3 {
+			// LOCKDOWN
+			pe.SafetyModeUntil = pe.clock.Now().Add(predatorLockdown)
+			pe.ConsecutiveLosses = 0
+
+			log.Printf("🚨 CIRCUIT BREAKER: 3 Consecutive Losses. Predator Disabled for 2 Hours.")
+			if pe.notifier != nil {
+				pe.notifier.Notify("⚠️ **Predator Paused**\n3 losses in a row detected. Cooldown active for 2 hours.")
+				pe.notifier.SendAppPush(PublicSignal{
+					Symbol:     "SYSTEM",
+					Direction:  "PAUSED",
+					Stars:      3,
+					EntryZone:  "Lockdown",
+					Volatility: "High",
+				})
 			}
 
-			if pe.ConsecutiveLosses >= 3 {
-				// LOCKDOWN
-				pe.SafetyModeUntil = time.Now().Add(2 * time.Hour)
-				pe.ConsecutiveLosses = 0
+			// Cancel ALL Open Orders
+			go pe.StopAll()
+		}
+	} else {
+		pe.ConsecutiveLosses = 0
+	}
+
+	// Daily loss stop: RiskManager rejects new entries past the engine budget (RiskReasonEngineLoss)
+	log.Printf("💀 CLOSED %s (%s) | %s: $%.2f | Daily PnL: $%.2f", pos.Symbol, reason, label, pnl, pe.DailyRealizedPnL)
+}
+
+// ============================================================================
+// STARTUP RECONCILIATION (Adopt Live Positions)
+// ============================================================================
+
+// reconcile rebuilds PredatorPositions from live positions that carry our TP/SL
+// orders ("pred-" ClientOrderID). Untagged positions belong to someone else.
+func (pe *PredatorEngine) reconcile() {
+	snap, err := LoadExchangeSnapshot(pe.gateway)
+	if err != nil {
+		log.Printf("⚠️ PREDATOR RECONCILE FAILED: %v", err)
+		if pe.notifier != nil {
+			pe.notifier.Notify(fmt.Sprintf("⚠️ *PREDATOR RECONCILE FAILED*\n%v", err))
+		}
+		return
+	}
+
+	var adopted []string
+	for _, lp := range snap.Positions {
+		if len(lp.OrdersWithPrefix(predatorOrderPrefix)) == 0 {
+			continue
+		}
+		pos := pe.adoptPosition(lp)
+		adopted = append(adopted, fmt.Sprintf("%s %s %.4f @ %.4f (SL %.4f | TP %.4f)", pos.Side, pos.Symbol, pos.Size, pos.Entry, pos.StopLoss, pos.TakeProfit))
+	}
+
+	// TP legs are plain LIMIT orders: left alone they would re-open a closed position
+	orphans := 0
+	for symbol, orders := range snap.Orphans {
+		for _, o := range ordersWithPrefix(orders, predatorOrderPrefix) {
+			log.Printf("🗑️ PREDATOR RECONCILE: Cancelling orphan %s order %d (%s)", symbol, o.OrderID, o.ClientOrderID)
+			pe.gateway.CancelOrder(context.Background(), symbol, o.OrderID)
+			orphans++
+		}
+	}
+
+	log.Printf("♻️ PREDATOR RECONCILE: Adopted %d | Orphan Orders Cancelled %d", len(adopted), orphans)
+	pe.saveState() // Drops journaled positions that no longer exist
+	if pe.notifier != nil && (len(adopted) > 0 || orphans > 0) {
+		msg := fmt.Sprintf("♻️ *PREDATOR RECONCILIATION*\nAdopted: %d | Orphan Orders Cancelled: %d", len(adopted), orphans)
+		for _, line := range adopted {
+			msg += "\n• " + line
+		}
+		pe.notifier.Notify(msg)
+	}
+}
+
+// adoptPosition restores TP/SL ids and prices from our tagged orders and registers the trade
+func (pe *PredatorEngine) adoptPosition(lp *LivePosition) *PredatorPosition {
+	shortSym := Instruments.Canonical(lp.Symbol).Key() // Positions are keyed "BTC"
+
+	pos := &PredatorPosition{
+		Symbol:     shortSym,
+		Entry:      lp.Entry,
+		Size:       lp.Qty(),
+		Side:       lp.Side(),
+		StartTime:  pe.clock.Now(),
+		Leverage:   lp.Leverage,
+		MarginUsed: lp.Margin,
+		Tier:       "ADOPTED",
+	}
+	if pos.MarginUsed == 0 && pos.Leverage > 0 {
+		pos.MarginUsed = (lp.Entry * pos.Size) / float64(pos.Leverage)
+	}
+
+	for _, o := range lp.OrdersWithPrefix(predatorOrderPrefix) {
+		// Oldest leg approximates the entry time (keeps the 60 min timeout honest)
+		if placed := time.UnixMilli(o.Time); o.Time > 0 && placed.Before(pos.StartTime) {
+			pos.StartTime = placed
+		}
+		switch {
+		case strings.HasPrefix(o.ClientOrderID, predatorTPPrefix):
+			pos.TPOrderID = o.OrderID
+			pos.TakeProfit, _ = strconv.ParseFloat(o.Price, 64)
+		case strings.HasPrefix(o.ClientOrderID, predatorSLPrefix):
+			pos.SLOrderID = o.OrderID
+			pos.StopLoss, _ = strconv.ParseFloat(o.StopPrice, 64)
+			// Green Guard / Break-Even replace the initial STOP with a STOP_MARKET
+			pos.IsBreakEvenSet = o.Type == futures.OrderType("STOP_MARKET")
+		}
+	}
+
+	// Journaled position restores what orders cannot tell us
+	if saved, ok := pe.restoredPositions[shortSym]; ok && saved.Side == pos.Side {
+		pos.StartTime = saved.StartTime
+		pos.MaxPnL = saved.MaxPnL
+		pos.Score = saved.Score
+		pos.Tier = saved.Tier
+		pos.RealizedPnL = saved.RealizedPnL
+	}
 
-				log.Printf("🚨 CIRCUIT BREAKER: 3 Consecutive Losses. Predator Disabled for 2 Hours.")
+	log.Printf("♻️ PREDATOR ADOPTING %s %s %.4f @ %.4f (TP #%d | SL #%d)", pos.Side, lp.Symbol, pos.Size, pos.Entry, pos.TPOrderID, pos.SLOrderID)
+
+	pe.mu.Lock()
+	pe.positions[shortSym] = pos
+	pe.mu.Unlock()
+	pe.risk.Adopt(RiskEnginePredator, lp.Symbol, pos.Side, lp.Qty()*lp.Entry)
+	return pos
+}
+
+// ============================================================================
+// DURABLE STATE (Journal)
+// ============================================================================
+
+const predatorStateKey = "predator"
+
+// predatorState is the journaled form of the circuit breaker + open positions
+type predatorState struct {
+	Day               string                       `json:"day"`
+	DailyRealizedPnL  float64                      `json:"dailyRealizedPnL"`
+	ConsecutiveLosses int                          `json:"consecutiveLosses"`
+	SafetyModeUntil   time.Time                    `json:"safetyModeUntil"`
+	TradeCooldowns    map[string]time.Time         `json:"tradeCooldowns"`
+	Positions         map[string]*PredatorPosition `json:"positions"`
+}
+
+// AttachMarketBus sets the feed the workers subscribe to (call before Start)
+func (pe *PredatorEngine) AttachMarketBus(bus *MarketBus) {
+	pe.bus = bus
+}
+
+// AttachFunding enables the funding entry gate (call before Start)
+func (pe *PredatorEngine) AttachFunding(fm *FundingMonitor) {
+	pe.funding = fm
+}
+
+// AttachOpenInterest attaches the OI delta to broadcast signals (call before Start)
+func (pe *PredatorEngine) AttachOpenInterest(om *OpenInterestMonitor) {
+	pe.openInterest = om
+}
+
+// AttachStateStore restores the circuit breaker and journals every change from now on (call before Start)
+func (pe *PredatorEngine) AttachStateStore(store *StateStore) {
+	var st predatorState
+	restored := store.Load(predatorStateKey, &st)
+
+	pe.mu.Lock()
+	pe.store = store
+	if restored {
+		pe.ConsecutiveLosses = st.ConsecutiveLosses
+		pe.SafetyModeUntil = st.SafetyModeUntil
+		for sym, until := range st.TradeCooldowns {
+			pe.TradeCooldowns[sym] = until
+		}
+		pe.restoredPositions = st.Positions
+		if st.Day == store.TradingDay(time.Now()) {
+			pe.DailyRealizedPnL = st.DailyRealizedPnL
+		}
+	}
+	pe.mu.Unlock()
+
+	if restored {
+		log.Printf("💾 PREDATOR STATE RESTORED: Daily PnL $%.2f | Losses %d | Safety Until %s | %d position(s)",
+			pe.DailyRealizedPnL, pe.ConsecutiveLosses, pe.SafetyModeUntil.Format(time.Kitchen), len(st.Positions))
+	}
+
+	store.OnRollover(pe.resetDaily)
+}
+
+// resetDaily clears the daily PnL at the trading day boundary
+func (pe *PredatorEngine) resetDaily(day string) {
+	pe.mu.Lock()
+	pe.DailyRealizedPnL = 0
+	pe.mu.Unlock()
+
+	log.Printf("🌅 PREDATOR: Daily PnL reset for %s", day)
+	pe.saveState()
+}
+
+// saveState journals the circuit breaker and open positions (caller must NOT hold pe.mu).
+// saveMu spans snapshot + Save so the newest snapshot is always journaled last.
+func (pe *PredatorEngine) saveState() {
+	pe.saveMu.Lock()
+	defer pe.saveMu.Unlock()
+
+	pe.mu.Lock()
+	if pe.store == nil {
+		pe.mu.Unlock()
+		return
+	}
+
+	st := predatorState{
+		Day:               pe.store.TradingDay(time.Now()),
+		DailyRealizedPnL:  pe.DailyRealizedPnL,
+		ConsecutiveLosses: pe.ConsecutiveLosses,
+		SafetyModeUntil:   pe.SafetyModeUntil,
+		TradeCooldowns:    make(map[string]time.Time),
+		Positions:         make(map[string]*PredatorPosition, len(pe.positions)),
+	}
+	for sym, until := range pe.TradeCooldowns {
+		if pe.clock.Now().Before(until) {
+			st.TradeCooldowns[sym] = until
+		}
+	}
+	for sym, pos := range pe.positions {
+		copied := *pos
+		st.Positions[sym] = &copied
+	}
+	store := pe.store
+	pe.mu.Unlock()
+
+	if err := store.Save(predatorStateKey, st); err != nil {
+		log.Printf("⚠️ STATE SAVE FAILED (predator): %v", err)
+	}
+}
+
+// ============================================================================
+// USER DATA STREAM (TP/SL Fills, Liquidations)
+// ============================================================================
+
+// ListenUserStream closes positions as soon as their TP/SL fills or the exchange flattens them
+func (pe *PredatorEngine) ListenUserStream(stream *UserStreamManager) {
+	events := stream.Subscribe()
+
+	go func() {
+		for event := range events {
+			switch event.Event {
+			case futures.UserDataEventTypeOrderTradeUpdate:
+				pe.onOrderUpdate(event.OrderTradeUpdate)
+			case futures.UserDataEventTypeAccountUpdate:
+				pe.onAccountUpdate(event.AccountUpdate)
+			case futures.UserDataEventTypeMarginCall:
+				log.Printf("🚨 PREDATOR MARGIN CALL: %d position(s) at risk", len(event.MarginCallPositions))
 				if pe.notifier != nil {
-					pe.notifier.Notify("⚠️ **Predator Paused**\n3 losses in a row detected. Cooldown active for 2 hours.")
-					pe.notifier.SendAppPush(PublicSignal{
-						Symbol:     "SYSTEM",
-						Direction:  "PAUSED",
-						Stars:      3,
-						EntryZone:  "Lockdown",
-						Volatility: "High",
-					})
+					pe.notifier.Notify(fmt.Sprintf("🚨 *PREDATOR MARGIN CALL*\n%d position(s) near liquidation!", len(event.MarginCallPositions)))
 				}
-
-				// Cancel ALL Open Orders
-				go pe.StopAll()
 			}
-		} else {
-			pe.ConsecutiveLosses = 0
 		}
+	}()
+}
+
+// onOrderUpdate handles TP/SL fills (OCO: the sibling leg is cancelled)
+func (pe *PredatorEngine) onOrderUpdate(update futures.WsOrderTradeUpdate) {
+	shortSym := Instruments.Canonical(update.Symbol).Key() // Positions are keyed "BTC"
+
+	pe.mu.Lock()
+	pos, ok := pe.positions[shortSym]
+	if !ok || update.ID == 0 || (update.ID != pos.TPOrderID && update.ID != pos.SLOrderID) {
+		pe.mu.Unlock()
+		return
+	}
 
-		log.Printf("💀 CLOSED %s (%s) | Est PnL: $%.2f | Daily PnL: $%.2f", pos.Symbol, reason, pnl, pe.DailyRealizedPnL)
+	leg, sibling := "TP", pos.SLOrderID
+	if update.ID == pos.SLOrderID {
+		leg, sibling = "SL", pos.TPOrderID
+	}
+
+	if update.ExecutionType == futures.OrderExecutionTypeTrade {
+		rp, _ := strconv.ParseFloat(update.RealizedPnL, 64)
+		fee, _ := strconv.ParseFloat(update.Commission, 64)
+		pos.RealizedPnL += rp - fee
+	}
 
-		if pe.DailyRealizedPnL <= -100.0 {
-			log.Printf("🚨 DAILY LOSS LIMIT HIT. SHUTTING DOWN.")
-			pe.active = false
+	if update.Status == futures.OrderStatusTypePartiallyFilled {
+		log.Printf("🧩 PREDATOR %s PARTIAL: %s %s/%s", leg, pos.Symbol, update.AccumulatedFilledQty, update.OriginalQty)
+		pe.mu.Unlock()
+		return
+	}
+	if update.Status != futures.OrderStatusTypeFilled {
+		pe.mu.Unlock() // Cancelled/replaced by us (Green Guard, Break-Even)
+		return
+	}
+
+	delete(pe.positions, shortSym)
+	pe.recordClose(pos, pos.RealizedPnL, leg+" FILLED", "Realized PnL")
+	pe.mu.Unlock()
+	pe.saveState()
+
+	pe.risk.Release(RiskEnginePredator, NormalizeSymbol(pos.Symbol))
+	if sibling != 0 {
+		pe.gateway.CancelOrder(context.Background(), update.Symbol, sibling)
+	}
+}
+
+// onAccountUpdate catches positions flattened outside our TP/SL (liquidation, ADL, manual)
+func (pe *PredatorEngine) onAccountUpdate(update futures.WsAccountUpdate) {
+	for _, p := range update.Positions {
+		amt, _ := strconv.ParseFloat(p.Amount, 64)
+		if amt != 0 {
+			continue
+		}
+		shortSym := Instruments.Canonical(p.Symbol).Key()
+
+		pe.mu.Lock()
+		_, tracked := pe.positions[shortSym]
+		pe.mu.Unlock()
+		if tracked {
+			go pe.reconcileFlat(shortSym, string(update.Reason))
+		}
+	}
+}
+
+// reconcileFlat removes a position the exchange reports flat. Waits briefly so a
+// matching TP/SL ORDER_TRADE_UPDATE can close it with the exact realized PnL.
+func (pe *PredatorEngine) reconcileFlat(shortSym, reason string) {
+	time.Sleep(1 * time.Second)
+
+	pe.mu.Lock()
+	pos, ok := pe.positions[shortSym]
+	if !ok {
+		pe.mu.Unlock()
+		return
+	}
+	delete(pe.positions, shortSym)
+
+	pnl, label := pos.RealizedPnL, "Realized PnL"
+	if price, hasPrice := pe.currentPrices[shortSym]; pnl == 0 && hasPrice {
+		pnl, label = (price-pos.Entry)*pos.Size, "Est PnL"
+		if pos.Side == "SHORT" {
+			pnl = -pnl
 		}
 	}
+	pe.recordClose(pos, pnl, "EXCHANGE FLAT ("+reason+")", label)
 	pe.mu.Unlock()
+	pe.saveState()
+
+	pe.risk.Release(RiskEnginePredator, NormalizeSymbol(pos.Symbol))
+
+	// Orphaned TP/SL must not re-open a position
+	normSymbol := NormalizeSymbol(pos.Symbol)
+	if pos.TPOrderID != 0 {
+		pe.gateway.CancelOrder(context.Background(), normSymbol, pos.TPOrderID)
+	}
+	if pos.SLOrderID != 0 {
+		pe.gateway.CancelOrder(context.Background(), normSymbol, pos.SLOrderID)
+	}
+	if pe.notifier != nil {
+		pe.notifier.Notify(fmt.Sprintf("⚠️ *PREDATOR*: %s flattened by exchange (%s). PnL: $%.2f", pos.Symbol, reason, pnl))
+	}
 }
 
 // StopAll cancels all open orders for all tracked symbols.
@@ -1240,17 +1552,16 @@ func (pe *PredatorEngine) StopAll() {
 	for sym, pos := range pe.positions {
 		normSymbol := NormalizeSymbol(sym)
 		if pos.TPOrderID != 0 {
-			pe.client.NewCancelOrderService().Symbol(normSymbol).OrderID(pos.TPOrderID).Do(context.Background())
+			pe.gateway.CancelOrder(context.Background(), normSymbol, pos.TPOrderID)
 		}
 		if pos.SLOrderID != 0 {
-			pe.client.NewCancelOrderService().Symbol(normSymbol).OrderID(pos.SLOrderID).Do(context.Background())
+			pe.gateway.CancelOrder(context.Background(), normSymbol, pos.SLOrderID)
 		}
 	}
 	pe.mu.Unlock()
 
 	// 2. Also Cancel any rouge orders on target pairs
-	targets := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}
-	for _, sym := range targets {
-		pe.client.NewCancelAllOpenOrdersService().Symbol(sym).Do(context.Background())
+	for _, sym := range pe.registry.Symbols() {
+		pe.gateway.CancelAllOrders(context.Background(), sym)
 	}
 }
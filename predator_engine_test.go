@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPredatorAbortsEntryWhenMarginTypeFails(t *testing.T) {
+	gw := NewFakeGateway()
+	gw.FailNext("ChangeMarginType", errors.New("code=-4047, msg=Margin type cannot be changed if there exists open orders."))
+
+	pe := NewPredatorEngine(gw, nil, 0, 1, nil, 20, 0, 0, nil, nil, NewSymbolRegistry([]string{"BTCUSDT"}), NewRiskManager(RiskLimits{}, nil))
+	pe.executeTrade(&PredatorPosition{Symbol: "BTC", Side: "LONG", Entry: 65000, MarginUsed: 1000, Leverage: 20})
+
+	if len(gw.Placed) != 0 {
+		t.Fatalf("entry went out after a margin type failure: %+v", gw.Placed)
+	}
+}
+
+func TestPredatorAbortsEntryOverMaxLeverage(t *testing.T) {
+	gw := NewFakeGateway()
+
+	pe := NewPredatorEngine(gw, nil, 0, 1, nil, 20, 0, 0, nil, nil, NewSymbolRegistry([]string{"BTCUSDT"}), NewRiskManager(RiskLimits{MaxLeverage: 10}, nil))
+	pe.executeTrade(&PredatorPosition{Symbol: "BTC", Side: "LONG", Entry: 65000, MarginUsed: 1000, Leverage: 20})
+
+	if _, changed := gw.Leverage["BTCUSDT"]; changed {
+		t.Fatal("leverage above the risk limit reached the exchange")
+	}
+	if len(gw.Placed) != 0 {
+		t.Fatalf("entry went out after a leverage rejection: %+v", gw.Placed)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ============================================================================
+// PRICE BUCKETS (Per-Symbol, Tick-Aware Accumulation Levels)
+// ============================================================================
+// Iceberg / wall accumulation groups prints that hit "the same price". The
+// bucket width is BPS of the price, snapped to a 1-2-5 step (so it doesn't
+// shift with every tick of price) and rounded up to whole ticks when the
+// instrument's tick size is known:
+//
+//   BTC  @ 65000, 1 bps -> $10 buckets   (tick 0.1)
+//   DOGE @ 0.15,  1 bps -> $0.00002      (tick 0.00001)
+//   1000PEPE @ 0.01     -> $0.000001     (tick 0.0000001)
+//
+// BPS 0 buckets by exact tick.
+
+const defaultBucketBPS = 1.0 // 0.01% of price
+
+// priceBucketKey identifies one accumulation level of one symbol
+type priceBucketKey struct {
+	Symbol string
+	Bucket int64 // floor(price / step)
+}
+
+// String is the map key form ("BTC_6500") used for icebergs and debouncing
+func (k priceBucketKey) String() string {
+	return fmt.Sprintf("%s_%d", k.Symbol, k.Bucket)
+}
+
+// priceBucket maps a print to its symbol's accumulation level
+func priceBucket(symbol string, price, bps float64) priceBucketKey {
+	step := priceStep(Instruments.TickSize(symbol), price, bps)
+	if step <= 0 {
+		return priceBucketKey{Symbol: symbol}
+	}
+	return priceBucketKey{Symbol: symbol, Bucket: int64(math.Floor(price/step + 1e-9))}
+}
+
+// priceStep is the bucket width for price (tick 0 = unknown)
+func priceStep(tick, price, bps float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	step := niceStep(price * bps / 10000)
+	if tick <= 0 {
+		if step <= 0 {
+			return niceStep(price / 10000) // No tick, no width: 1 bps
+		}
+		return step
+	}
+	if step < tick {
+		return tick
+	}
+	return math.Ceil(step/tick-1e-9) * tick
+}
+
+// niceStep rounds x up to 1, 2 or 5 x 10^n
+func niceStep(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	p := math.Pow(10, math.Floor(math.Log10(x)))
+	switch m := x / p; {
+	case m <= 1:
+		return p
+	case m <= 2:
+		return 2 * p
+	case m <= 5:
+		return 5 * p
+	}
+	return 10 * p
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// STARTUP RECONCILIATION (Shared)
+// ============================================================================
+// Each engine tags its protective orders with a ClientOrderID prefix so that
+// after a restart it can tell its own positions apart (both engines may trade
+// the same account) and rebuild TP/SL state from the live orders.
+
+const (
+	ghostOrderPrefix    = "ghost-" // ExecutionService (GhostSession)
+	ghostTPPrefix       = ghostOrderPrefix + "tp-"
+	ghostSLPrefix       = ghostOrderPrefix + "sl-"
+	predatorOrderPrefix = "pred-" // PredatorEngine
+	predatorTPPrefix    = predatorOrderPrefix + "tp-"
+	predatorSLPrefix    = predatorOrderPrefix + "sl-"
+)
+
+// newClientOrderID builds a unique ClientOrderID (Binance limit: 36 chars)
+func newClientOrderID(prefix string) string {
+	return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+}
+
+// LivePosition is a non-zero exchange position with its open orders
+type LivePosition struct {
+	Symbol     string
+	Amount     float64 // Signed (negative = SHORT)
+	Entry      float64
+	Leverage   int
+	Margin     float64 // Isolated margin (0 for cross)
+	Orders     []*futures.Order
+	MarginType string
+}
+
+// Side returns "LONG" or "SHORT"
+func (lp *LivePosition) Side() string {
+	if lp.Amount < 0 {
+		return "SHORT"
+	}
+	return "LONG"
+}
+
+// Qty returns the absolute position size
+func (lp *LivePosition) Qty() float64 {
+	return math.Abs(lp.Amount)
+}
+
+// OrdersWithPrefix returns the open orders whose ClientOrderID starts with prefix
+func (lp *LivePosition) OrdersWithPrefix(prefix string) []*futures.Order {
+	return ordersWithPrefix(lp.Orders, prefix)
+}
+
+// ExchangeSnapshot is the account state loaded once at boot
+type ExchangeSnapshot struct {
+	Positions map[string]*LivePosition    // Symbol -> Position
+	Orphans   map[string][]*futures.Order // Symbol -> Open orders without a position
+}
+
+// LoadExchangeSnapshot fetches position risk + all open orders and groups them by symbol
+func LoadExchangeSnapshot(gateway Gateway) (*ExchangeSnapshot, error) {
+	risks, err := gateway.Positions(context.Background(), "")
+	if err != nil {
+		return nil, fmt.Errorf("position risk: %v", err)
+	}
+	orders, err := gateway.OpenOrders(context.Background(), "")
+	if err != nil {
+		return nil, fmt.Errorf("open orders: %v", err)
+	}
+
+	snap := &ExchangeSnapshot{
+		Positions: make(map[string]*LivePosition),
+		Orphans:   make(map[string][]*futures.Order),
+	}
+	for _, r := range risks {
+		amt, _ := strconv.ParseFloat(r.PositionAmt, 64)
+		if amt == 0 {
+			continue
+		}
+		entry, _ := strconv.ParseFloat(r.EntryPrice, 64)
+		lev, _ := strconv.Atoi(r.Leverage)
+		margin, _ := strconv.ParseFloat(r.IsolatedMargin, 64)
+		snap.Positions[r.Symbol] = &LivePosition{
+			Symbol:     r.Symbol,
+			Amount:     amt,
+			Entry:      entry,
+			Leverage:   lev,
+			Margin:     margin,
+			MarginType: r.MarginType,
+		}
+	}
+	for _, o := range orders {
+		if lp, ok := snap.Positions[o.Symbol]; ok {
+			lp.Orders = append(lp.Orders, o)
+		} else {
+			snap.Orphans[o.Symbol] = append(snap.Orphans[o.Symbol], o)
+		}
+	}
+	return snap, nil
+}
+
+func ordersWithPrefix(orders []*futures.Order, prefix string) []*futures.Order {
+	var res []*futures.Order
+	for _, o := range orders {
+		if strings.HasPrefix(o.ClientOrderID, prefix) {
+			res = append(res, o)
+		}
+	}
+	return res
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// REPLAY EXCHANGE (Recorded Feeds -> Analyzer, No Network)
+// ============================================================================
+// Reads the files written by FeedRecorder and hands every frame to the same
+// parser the live connector uses, so trades, depth diffs, book snapshots,
+// liquidations and mark prices reach the pipeline exactly as they did when
+// recorded.
+//
+// Pacing follows the recorded receive times divided by Speed (2 = twice as
+// fast). Speed 0 replays as fast as the pipeline consumes (channels apply
+// backpressure, so nothing is dropped). Clock (optional) follows the recorded
+// receive time, so debouncing and windows behave as they did live.
+
+// ReplayExchange implements Exchange and LiquidationExchange over a recording
+type ReplayExchange struct {
+	Dir   string
+	Speed float64   // Multiplier on recorded time (0 = as fast as possible)
+	Clock *SimClock // Advanced to each frame's receive time (nil = wall clock)
+
+	Funding *FundingMonitor // Receives Binance mark price frames (nil = skipped)
+
+	binance   *BinanceFutures // Live parsers (Registry filters liquidations as live)
+	bybit     *BybitV5
+	okx       *OKXFutures
+	kraken    *KrakenFutures
+	coinbase  *CoinbaseAdvanced
+	cryptoCom *CryptoCom
+	kucoin    *KuCoinFutures
+
+	liqOut   chan<- Alert
+	liqReady chan struct{}
+	done     chan struct{}
+
+	frames  atomic.Int64
+	skipped atomic.Int64
+	first   int64 // First / last recorded timestamp (ms)
+	last    int64
+}
+
+// NewReplayExchange creates a replay over every recording file in dir
+func NewReplayExchange(dir string, speed float64, registry *SymbolRegistry) *ReplayExchange {
+	return &ReplayExchange{
+		Dir:       dir,
+		Speed:     speed,
+		binance:   &BinanceFutures{Registry: registry},
+		bybit:     &BybitV5{Registry: registry},
+		okx:       &OKXFutures{Registry: registry},
+		kraken:    &KrakenFutures{},
+		coinbase:  &CoinbaseAdvanced{},
+		cryptoCom: &CryptoCom{},
+		kucoin:    &KuCoinFutures{Registry: registry},
+		liqReady:  make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Done is closed once every file has been replayed
+func (r *ReplayExchange) Done() <-chan struct{} {
+	return r.done
+}
+
+// StartLiquidations registers the liquidation sink (frames are pushed by Start)
+func (r *ReplayExchange) StartLiquidations(out chan<- Alert) {
+	r.liqOut = out
+	close(r.liqReady)
+}
+
+// Start replays all files in order, then closes Done
+func (r *ReplayExchange) Start(out chan<- Trade, analyzer *Analyzer) {
+	defer close(r.done)
+
+	// CoinManager starts liquidations right after trades: wait for the sink
+	var liqOut chan<- Alert
+	select {
+	case <-r.liqReady:
+		liqOut = r.liqOut
+	case <-time.After(2 * time.Second):
+		log.Println("⚠️ REPLAY: No liquidation sink registered. Liquidation frames will be skipped.")
+	}
+
+	if err := r.Each(func(frame RecordedFrame) {
+		r.dispatch(frame, out, liqOut, analyzer)
+	}); err != nil {
+		log.Printf("❌ REPLAY: %v", err)
+	}
+}
+
+// Each hands every recorded frame to fn in order, paced by Speed, with Clock
+// already at the frame's receive time
+func (r *ReplayExchange) Each(fn func(RecordedFrame)) error {
+	files, err := RecordingFiles(r.Dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no recordings in %s", r.Dir)
+	}
+	log.Printf("⏯️ REPLAY: %d files from %s (speed %s)", len(files), r.Dir, r.speedLabel())
+
+	var wallStart time.Time
+	for _, path := range files {
+		if err := r.replayFile(path, func(frame RecordedFrame) {
+			if r.first == 0 {
+				r.first = frame.Received
+				wallStart = time.Now()
+			}
+			r.last = frame.Received
+			r.pace(wallStart, frame.Received)
+			if r.Clock != nil {
+				r.Clock.SetMillis(frame.Received)
+			}
+			fn(frame)
+		}); err != nil {
+			log.Printf("⚠️ REPLAY: %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	log.Printf("⏹️ REPLAY COMPLETE: %d frames (%d skipped) covering %v",
+		r.frames.Load(), r.skipped.Load(), time.Duration(r.last-r.first)*time.Millisecond)
+	return nil
+}
+
+func (r *ReplayExchange) replayFile(path string, fn func(RecordedFrame)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 1<<20), 16<<20) // Depth snapshots are large
+	for scanner.Scan() {
+		var frame RecordedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			r.skipped.Add(1)
+			continue
+		}
+		fn(frame)
+	}
+
+	// A file cut off by a crash ends in a torn gzip block: keep what was read
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	return nil
+}
+
+// pace sleeps until the frame's recorded offset (scaled by Speed) has elapsed
+func (r *ReplayExchange) pace(wallStart time.Time, received int64) {
+	if r.Speed <= 0 {
+		return
+	}
+	offset := time.Duration(float64(received-r.first)/r.Speed) * time.Millisecond
+	if wait := time.Until(wallStart.Add(offset)); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// dispatch routes a frame to the parser of the connector that recorded it
+func (r *ReplayExchange) dispatch(frame RecordedFrame, out chan<- Trade, liqOut chan<- Alert, analyzer *Analyzer) {
+	message := []byte(frame.Data)
+	if len(message) == 0 {
+		message = []byte(frame.Text)
+	}
+	r.frames.Add(1)
+
+	switch frame.Exchange + "/" + frame.Stream {
+	case "Binance/futures":
+		r.binance.handleFrame(message, out, analyzer)
+	case "Binance/forceOrder":
+		if liqOut == nil {
+			r.skipped.Add(1)
+			return
+		}
+		r.binance.handleLiquidation(message, liqOut)
+	case "Binance/markPrice":
+		if r.Funding == nil {
+			r.skipped.Add(1)
+			return
+		}
+		r.Funding.handleFrame(message)
+	case "Binance/depthSnapshot":
+		var rec struct {
+			Symbol   string                 `json:"symbol"`
+			Snapshot *futures.DepthResponse `json:"snapshot"`
+		}
+		if err := json.Unmarshal(message, &rec); err != nil || rec.Snapshot == nil || analyzer.books == nil {
+			r.skipped.Add(1)
+			return
+		}
+		analyzer.books.LoadSnapshot(rec.Symbol, rec.Snapshot)
+	case "Bybit/publicTrade":
+		r.bybit.handleFrame(message, out)
+	case "Bybit/allLiquidation":
+		if liqOut == nil {
+			r.skipped.Add(1)
+			return
+		}
+		r.bybit.handleLiquidation(message, liqOut)
+	case "OKX/trades":
+		r.okx.handleFrame(message, out)
+	case "OKX/instruments":
+		if err := r.okx.setContractValues(message); err != nil {
+			r.skipped.Add(1)
+		}
+	case "OKX/liquidation-orders":
+		if liqOut == nil {
+			r.skipped.Add(1)
+			return
+		}
+		r.okx.handleLiquidation(message, liqOut)
+	case "Kraken/trade":
+		r.kraken.handleFrame(message, out)
+	case "Coinbase/market_trades":
+		r.coinbase.handleFrame(message, out)
+	case "Crypto.com/trade":
+		r.cryptoCom.handleFrame(message, out)
+	case "KuCoin/contracts":
+		if err := r.kucoin.setContracts(message); err != nil {
+			r.skipped.Add(1)
+		}
+	case "KuCoin/execution":
+		r.kucoin.handleFrame(message, out)
+	default:
+		r.skipped.Add(1) // e.g. Predator kline streams (not part of the Analyzer pipeline)
+	}
+}
+
+func (r *ReplayExchange) speedLabel() string {
+	if r.Speed <= 0 {
+		return "max"
+	}
+	return fmt.Sprintf("%gx", r.Speed)
+}
+
+// ============================================================================
+// REPLAY MODE (--replay <dir>)
+// ============================================================================
+
+// ReplayReport counts the alerts a replay produced
+type ReplayReport struct {
+	Trades int64
+	Alerts map[string]int
+}
+
+// RunReplay drives the detection stack (books, walls, liquidation monitor, Analyzer)
+// from a recording. No REST, no orders, no notifications.
+//
+// Frames are processed one at a time: each frame's trades and liquidations are
+// analyzed before the next frame moves the book or the clock, so a run is
+// deterministic for a given recording.
+func RunReplay(dir string, speed float64, symbols []string, cascade CascadeConfig, funding FundingConfig) *ReplayReport {
+	if len(symbols) == 0 {
+		symbols = DefaultSymbols // Never rank the universe over the network
+	}
+	registry := NewSymbolRegistry(symbols)
+
+	// Per-frame buffers (drained before the next frame)
+	tradeBuf := make(chan Trade, 4096)
+	liqBuf := make(chan Alert, 256)
+	alertBuf := make(chan Alert, 4096) // Funding / wall alerts raised while dispatching
+
+	// Event time drives every window (set before the first frame)
+	clock := NewSimClock(time.UnixMilli(0))
+
+	books := NewOrderBookManager()
+	books.ExternalSnapshots = true
+	liqMonitor := NewLiquidationMonitor(60*time.Second, cascade)
+	liqMonitor.SetClock(clock)
+	fundingMonitor := NewFundingMonitor(registry, funding, alertBuf)
+	fundingMonitor.SetClock(clock)
+	analyzer := NewAnalyzer(alertBuf, nil, nil, liqMonitor, nil, nil, nil, books)
+	analyzer.SetClock(clock)
+	analyzer.AttachFunding(fundingMonitor)
+	analyzer.cleanupTicker.Stop() // Swept on event time (below)
+	walls := NewWallTracker(books, DefaultWallConfig, alertBuf)
+	books.OnLevels(walls.OnLevels)
+
+	replay := NewReplayExchange(dir, speed, registry)
+	replay.Clock = clock
+	replay.Funding = fundingMonitor
+
+	report := &ReplayReport{Alerts: make(map[string]int)}
+	record := func(alert Alert) {
+		if alert.Type == "" {
+			return
+		}
+		report.Alerts[alert.Type]++
+		if alert.Level >= 4 {
+			log.Printf("[REPLAY ALERT L%d] %s", alert.Level, alert.Message)
+		}
+	}
+
+	var lastSweep int64
+	if err := replay.Each(func(frame RecordedFrame) {
+		// Analyzer cleanup (live: every 10s of wall time)
+		if frame.Received-lastSweep >= 10000 {
+			analyzer.cleanup()
+			lastSweep = frame.Received
+		}
+		analyzer.verifier.ResolveDue() // Spoof re-checks on event time
+
+		replay.dispatch(frame, tradeBuf, liqBuf, analyzer)
+
+		for len(liqBuf) > 0 {
+			alert := <-liqBuf
+			record(alert)
+			if cascadeAlert, ok := liqMonitor.Observe(alert); ok {
+				record(cascadeAlert)
+			}
+		}
+		for len(tradeBuf) > 0 {
+			trade := <-tradeBuf
+			report.Trades++
+			walls.OnTrade(trade)
+			record(analyzer.Analyze(trade))
+		}
+		for len(alertBuf) > 0 {
+			record(<-alertBuf)
+		}
+	}); err != nil {
+		log.Printf("❌ REPLAY: %v", err)
+	}
+
+	types := make([]string, 0, len(report.Alerts))
+	for t := range report.Alerts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	log.Printf("📊 REPLAY REPORT: %d trades", report.Trades)
+	for _, t := range types {
+		log.Printf("   %-14s %d", t, report.Alerts[t])
+	}
+	return report
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// RISK MANAGER (Account-Level, Shared by all Engines)
+// ============================================================================
+// ExecutionService and PredatorEngine can trade the same account. Every order
+// they place goes through a RiskGateway, which asks the RiskManager before any
+// exposure-increasing order reaches the venue. The manager enforces:
+//   - one engine per symbol (no two engines in the same market)
+//   - a global notional cap and max concurrent positions
+//   - a combined daily loss limit (plus per-engine budgets)
+//   - a max leverage
+// Rejections return a *RiskRejection naming the reason.
+
+const (
+	RiskEngineExec     = "EXEC"
+	RiskEnginePredator = "PREDATOR"
+)
+
+// Rejection reasons
+const (
+	RiskReasonDailyLoss    = "DAILY_LOSS_LIMIT"
+	RiskReasonEngineLoss   = "ENGINE_DAILY_LOSS"
+	RiskReasonSymbolLocked = "SYMBOL_LOCKED"
+	RiskReasonMaxPositions = "MAX_POSITIONS"
+	RiskReasonNotionalCap  = "NOTIONAL_CAP"
+	RiskReasonEngineBudget = "ENGINE_BUDGET"
+	RiskReasonMaxLeverage  = "MAX_LEVERAGE"
+	RiskReasonNoPrice      = "NO_PRICE"
+)
+
+// RiskLimits are the account-wide hard limits (0 = unlimited)
+type RiskLimits struct {
+	MaxNotional  float64 // Sum of all engines' position notional (USDT)
+	MaxPositions int     // Concurrent symbols across engines
+	MaxDailyLoss float64 // Combined realized loss per trading day (positive USDT)
+	MaxLeverage  int
+}
+
+// EngineBudget is an engine's share of the account (0 = no engine-level limit)
+type EngineBudget struct {
+	MaxPositions int
+	MaxNotional  float64
+	MaxDailyLoss float64
+}
+
+// RiskRejection explains why an order was blocked
+type RiskRejection struct {
+	Engine string
+	Symbol string
+	Reason string
+	Detail string
+}
+
+func (r *RiskRejection) Error() string {
+	return fmt.Sprintf("risk rejected %s %s (%s): %s", r.Engine, r.Symbol, r.Reason, r.Detail)
+}
+
+// riskHold is an engine's claim on a symbol
+type riskHold struct {
+	Engine   string
+	Side     string // "LONG" / "SHORT"
+	Notional float64
+	Since    time.Time
+}
+
+// RiskManager is the single gate for account exposure
+type RiskManager struct {
+	mu       sync.Mutex
+	limits   RiskLimits
+	budgets  map[string]EngineBudget
+	holds    map[string]*riskHold // Exchange Symbol -> Claim
+	dailyPnL map[string]float64   // Engine -> Realized PnL today
+
+	notifier   *NotificationService
+	lastNotify map[string]time.Time // "ENGINE:SYMBOL:REASON" -> Last alert (30s debounce)
+	clock      Clock
+
+	store  *StateStore
+	saveMu sync.Mutex // Serializes snapshot + Save
+}
+
+// NewRiskManager creates the account-level risk gate
+func NewRiskManager(limits RiskLimits, notifier *NotificationService) *RiskManager {
+	log.Printf("🛡️ RISK MANAGER: Notional Cap $%.0f | Max Positions %d | Daily Loss $%.0f | Max Leverage %dx",
+		limits.MaxNotional, limits.MaxPositions, limits.MaxDailyLoss, limits.MaxLeverage)
+	return &RiskManager{
+		limits:     limits,
+		budgets:    make(map[string]EngineBudget),
+		holds:      make(map[string]*riskHold),
+		dailyPnL:   make(map[string]float64),
+		notifier:   notifier,
+		lastNotify: make(map[string]time.Time),
+		clock:      RealClock,
+	}
+}
+
+// SetClock swaps the time source (hold ages and alert debounce). The trading
+// day stays on the StateStore's wall clock.
+func (rm *RiskManager) SetClock(c Clock) {
+	rm.mu.Lock()
+	rm.clock = clockOrReal(c)
+	rm.mu.Unlock()
+}
+
+// SetEngineBudget sets an engine's share of the account
+func (rm *RiskManager) SetEngineBudget(engine string, budget EngineBudget) {
+	rm.mu.Lock()
+	rm.budgets[engine] = budget
+	rm.mu.Unlock()
+}
+
+// Check reports whether engine may add notional to symbol (does not reserve).
+// Rejections are reported like Acquire's.
+func (rm *RiskManager) Check(engine, symbol string, notional float64) error {
+	rm.mu.Lock()
+	rejection := rm.checkLocked(engine, symbol, notional)
+	rm.mu.Unlock()
+
+	if rejection != nil {
+		rm.report(rejection)
+		return rejection // Never a typed nil: callers compare against nil
+	}
+	return nil
+}
+
+func (rm *RiskManager) checkLocked(engine, symbol string, notional float64) *RiskRejection {
+	reject := func(reason, detail string, args ...interface{}) *RiskRejection {
+		return &RiskRejection{Engine: engine, Symbol: symbol, Reason: reason, Detail: fmt.Sprintf(detail, args...)}
+	}
+
+	// 1. Daily Loss (Combined + Engine)
+	total := 0.0
+	for _, pnl := range rm.dailyPnL {
+		total += pnl
+	}
+	if rm.limits.MaxDailyLoss > 0 && -total >= rm.limits.MaxDailyLoss {
+		return reject(RiskReasonDailyLoss, "combined daily loss $%.2f >= $%.2f", -total, rm.limits.MaxDailyLoss)
+	}
+	budget := rm.budgets[engine]
+	if budget.MaxDailyLoss > 0 && -rm.dailyPnL[engine] >= budget.MaxDailyLoss {
+		return reject(RiskReasonEngineLoss, "%s daily loss $%.2f >= $%.2f", engine, -rm.dailyPnL[engine], budget.MaxDailyLoss)
+	}
+
+	// 2. Symbol Exclusivity
+	hold, held := rm.holds[symbol]
+	if held && hold.Engine != engine {
+		return reject(RiskReasonSymbolLocked, "%s already holds %s %s", hold.Engine, hold.Side, symbol)
+	}
+
+	// 3. Position Counts (only a new symbol adds one)
+	if !held {
+		engineCount := 0
+		for _, h := range rm.holds {
+			if h.Engine == engine {
+				engineCount++
+			}
+		}
+		if rm.limits.MaxPositions > 0 && len(rm.holds) >= rm.limits.MaxPositions {
+			return reject(RiskReasonMaxPositions, "%d/%d account positions open", len(rm.holds), rm.limits.MaxPositions)
+		}
+		if budget.MaxPositions > 0 && engineCount >= budget.MaxPositions {
+			return reject(RiskReasonEngineBudget, "%d/%d %s positions open", engineCount, budget.MaxPositions, engine)
+		}
+	}
+
+	// 4. Notional Caps (a re-entry replaces the symbol's reservation)
+	current := 0.0
+	if held {
+		current = hold.Notional
+	}
+	added := notional - current
+	if added <= 0 {
+		return nil
+	}
+	accountNotional, engineNotional := 0.0, 0.0
+	for _, h := range rm.holds {
+		accountNotional += h.Notional
+		if h.Engine == engine {
+			engineNotional += h.Notional
+		}
+	}
+	if rm.limits.MaxNotional > 0 && accountNotional+added > rm.limits.MaxNotional {
+		return reject(RiskReasonNotionalCap, "$%.2f + $%.2f > $%.2f account cap", accountNotional, added, rm.limits.MaxNotional)
+	}
+	if budget.MaxNotional > 0 && engineNotional+added > budget.MaxNotional {
+		return reject(RiskReasonEngineBudget, "$%.2f + $%.2f > $%.2f %s cap", engineNotional, added, budget.MaxNotional, engine)
+	}
+	return nil
+}
+
+// Acquire checks and reserves symbol for engine. One position per symbol per
+// engine: repeat entries (walk / failsafe) replace the reservation with the larger size.
+func (rm *RiskManager) Acquire(engine, symbol, side string, notional float64) error {
+	rm.mu.Lock()
+	rejection := rm.checkLocked(engine, symbol, notional)
+	if rejection == nil {
+		rm.holdLocked(engine, symbol, side, notional)
+	}
+	rm.mu.Unlock()
+
+	if rejection != nil {
+		rm.report(rejection)
+		return rejection
+	}
+	return nil
+}
+
+// Adopt registers an existing exchange position without limit checks (startup reconciliation)
+func (rm *RiskManager) Adopt(engine, symbol, side string, notional float64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if hold, ok := rm.holds[symbol]; ok && hold.Engine != engine {
+		log.Printf("⚠️ RISK: %s adopted %s already held by %s", engine, symbol, hold.Engine)
+	}
+	delete(rm.holds, symbol)
+	rm.holdLocked(engine, symbol, side, notional)
+}
+
+func (rm *RiskManager) holdLocked(engine, symbol, side string, notional float64) {
+	hold, ok := rm.holds[symbol]
+	if !ok {
+		rm.holds[symbol] = &riskHold{Engine: engine, Side: side, Notional: notional, Since: rm.clock.Now()}
+		return
+	}
+	hold.Side = side
+	if notional > hold.Notional {
+		hold.Notional = notional
+	}
+}
+
+// Release frees engine's claim on symbol (position closed or entry abandoned)
+func (rm *RiskManager) Release(engine, symbol string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if hold, ok := rm.holds[symbol]; ok && hold.Engine == engine {
+		delete(rm.holds, symbol)
+	}
+}
+
+// HeldSide returns the side engine holds on symbol ("" if none)
+func (rm *RiskManager) HeldSide(engine, symbol string) string {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if hold, ok := rm.holds[symbol]; ok && hold.Engine == engine {
+		return hold.Side
+	}
+	return ""
+}
+
+// CheckLeverage validates a leverage change
+func (rm *RiskManager) CheckLeverage(engine, symbol string, leverage int) error {
+	if rm.limits.MaxLeverage > 0 && leverage > rm.limits.MaxLeverage {
+		rejection := &RiskRejection{Engine: engine, Symbol: symbol, Reason: RiskReasonMaxLeverage,
+			Detail: fmt.Sprintf("%dx > %dx", leverage, rm.limits.MaxLeverage)}
+		rm.report(rejection)
+		return rejection
+	}
+	return nil
+}
+
+// RecordPnL books a closed trade's realized PnL against the daily limits
+func (rm *RiskManager) RecordPnL(engine string, pnl float64) {
+	rm.mu.Lock()
+	rm.dailyPnL[engine] += pnl
+	total := 0.0
+	for _, p := range rm.dailyPnL {
+		total += p
+	}
+	halted := rm.limits.MaxDailyLoss > 0 && -total >= rm.limits.MaxDailyLoss
+	rm.mu.Unlock()
+
+	if halted && pnl < 0 {
+		log.Printf("🚨 RISK: Combined daily loss $%.2f hit limit $%.2f. ALL ENGINES HALTED until next trading day.", -total, rm.limits.MaxDailyLoss)
+		if rm.notifier != nil {
+			rm.notifier.Notify(fmt.Sprintf("🚨 *ACCOUNT DAILY LOSS LIMIT*\nLoss: $%.2f / $%.2f\nAll engines halted until the daily reset.", -total, rm.limits.MaxDailyLoss))
+		}
+	}
+	rm.saveState()
+}
+
+// report logs a rejection and alerts Telegram (debounced per engine/symbol/reason)
+func (rm *RiskManager) report(r *RiskRejection) {
+	log.Printf("🛑 RISK REJECTED [%s] %s: %s (%s)", r.Engine, r.Symbol, r.Reason, r.Detail)
+
+	key := r.Engine + ":" + r.Symbol + ":" + r.Reason
+	rm.mu.Lock()
+	now := rm.clock.Now()
+	last := rm.lastNotify[key]
+	notify := now.Sub(last) > 30*time.Second
+	if notify {
+		rm.lastNotify[key] = now
+	}
+	rm.mu.Unlock()
+
+	if notify && rm.notifier != nil {
+		rm.notifier.Notify(fmt.Sprintf("🛑 *RISK REJECTED* (%s)\n%s: %s\n%s", r.Engine, r.Symbol, r.Reason, r.Detail))
+	}
+}
+
+// Status returns a one-block summary for /status
+func (rm *RiskManager) Status() string {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	total, notional := 0.0, 0.0
+	for _, p := range rm.dailyPnL {
+		total += p
+	}
+	symbols := make([]string, 0, len(rm.holds))
+	for sym, h := range rm.holds {
+		notional += h.Notional
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🛡️ *Account Risk*: $%.0f / $%.0f notional | %d / %d positions | Day PnL $%.2f (limit -$%.0f)\n",
+		notional, rm.limits.MaxNotional, len(rm.holds), rm.limits.MaxPositions, total, rm.limits.MaxDailyLoss))
+	for _, sym := range symbols {
+		h := rm.holds[sym]
+		sb.WriteString(fmt.Sprintf("- %s: %s %s $%.0f\n", sym, h.Engine, h.Side, h.Notional))
+	}
+	return sb.String()
+}
+
+// ============================================================================
+// RISK STATE (Journal)
+// ============================================================================
+
+const riskStateKey = "risk"
+
+type riskState struct {
+	Day      string             `json:"day"`
+	DailyPnL map[string]float64 `json:"dailyPnL"`
+}
+
+// AttachStateStore restores today's PnL and resets it at the trading day boundary
+func (rm *RiskManager) AttachStateStore(store *StateStore) {
+	var st riskState
+	rm.mu.Lock()
+	rm.store = store
+	if store.Load(riskStateKey, &st) && st.Day == store.TradingDay(time.Now()) {
+		for engine, pnl := range st.DailyPnL {
+			rm.dailyPnL[engine] = pnl
+		}
+	}
+	rm.mu.Unlock()
+
+	store.OnRollover(func(day string) {
+		rm.mu.Lock()
+		rm.dailyPnL = make(map[string]float64)
+		rm.mu.Unlock()
+		log.Printf("🌅 RISK: Daily PnL reset for %s", day)
+		rm.saveState()
+	})
+}
+
+// saveState journals the daily PnL (saveMu orders snapshot + Save: newest lands last)
+func (rm *RiskManager) saveState() {
+	rm.saveMu.Lock()
+	defer rm.saveMu.Unlock()
+
+	rm.mu.Lock()
+	if rm.store == nil {
+		rm.mu.Unlock()
+		return
+	}
+	st := riskState{Day: rm.store.TradingDay(time.Now()), DailyPnL: make(map[string]float64, len(rm.dailyPnL))}
+	for engine, pnl := range rm.dailyPnL {
+		st.DailyPnL[engine] = pnl
+	}
+	store := rm.store
+	rm.mu.Unlock()
+
+	if err := store.Save(riskStateKey, st); err != nil {
+		log.Printf("⚠️ STATE SAVE FAILED (risk): %v", err)
+	}
+}
+
+// ============================================================================
+// RISK GATEWAY (Enforcement Point)
+// ============================================================================
+
+// RiskGateway wraps an engine's Gateway so no order path can bypass the RiskManager
+type RiskGateway struct {
+	Gateway
+	risk   *RiskManager
+	engine string
+}
+
+// NewRiskGateway wraps gateway for engine
+func NewRiskGateway(gateway Gateway, risk *RiskManager, engine string) *RiskGateway {
+	return &RiskGateway{Gateway: gateway, risk: risk, engine: engine}
+}
+
+// PlaceOrder reserves risk for exposure-increasing orders; reduce-only / closing orders always pass
+func (g *RiskGateway) PlaceOrder(ctx context.Context, req OrderRequest) (*futures.CreateOrderResponse, error) {
+	if req.ReduceOnly || req.ClosePosition {
+		return g.Gateway.PlaceOrder(ctx, req)
+	}
+
+	side := "LONG"
+	if req.Side == futures.SideTypeSell {
+		side = "SHORT"
+	}
+
+	// Opposite side of our own position = exit (market close, TP limit)
+	heldSide := g.risk.HeldSide(g.engine, req.Symbol)
+	if heldSide != "" && heldSide != side {
+		return g.Gateway.PlaceOrder(ctx, req)
+	}
+
+	notional, err := g.orderNotional(ctx, req)
+	if err != nil {
+		rejection := &RiskRejection{Engine: g.engine, Symbol: req.Symbol, Reason: RiskReasonNoPrice, Detail: err.Error()}
+		g.risk.report(rejection)
+		return nil, rejection
+	}
+	if err := g.risk.Acquire(g.engine, req.Symbol, side, notional); err != nil {
+		return nil, err
+	}
+
+	res, err := g.Gateway.PlaceOrder(ctx, req)
+	if err != nil && heldSide == "" {
+		g.risk.Release(g.engine, req.Symbol) // Nothing was opened
+	}
+	return res, err
+}
+
+// ChangeLeverage enforces the max leverage
+func (g *RiskGateway) ChangeLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := g.risk.CheckLeverage(g.engine, symbol, leverage); err != nil {
+		return err
+	}
+	return g.Gateway.ChangeLeverage(ctx, symbol, leverage)
+}
+
+func (g *RiskGateway) orderNotional(ctx context.Context, req OrderRequest) (float64, error) {
+	qty, _ := strconv.ParseFloat(req.Quantity, 64)
+	price, _ := strconv.ParseFloat(req.Price, 64)
+	if price == 0 {
+		price, _ = strconv.ParseFloat(req.StopPrice, 64)
+	}
+	if price == 0 {
+		prices, err := g.Gateway.Prices(ctx, req.Symbol)
+		if err != nil || len(prices) == 0 {
+			return 0, fmt.Errorf("cannot price market order: %v", err)
+		}
+		price, _ = strconv.ParseFloat(prices[0].Price, 64)
+	}
+	return qty * price, nil
+}
@@ -14,6 +14,7 @@ type SignalAggregator struct {
 	pushCooldowns  map[string]time.Time     // Symbol -> Last Push Time
 	bucketDuration time.Duration
 	cooldownDur    time.Duration
+	clock          Clock
 }
 
 // SignalBucket collects signals for a symbol over a short window
@@ -31,6 +32,7 @@ func NewSignalAggregator(distributor *AppSignalDistributor) *SignalAggregator {
 		pushCooldowns:  make(map[string]time.Time),
 		bucketDuration: 30 * time.Second, // 30s Window
 		cooldownDur:    5 * time.Minute,  // 5m Global Cooldown
+		clock:          RealClock,
 	}
 
 	// Start Flush Loop
@@ -39,6 +41,13 @@ func NewSignalAggregator(distributor *AppSignalDistributor) *SignalAggregator {
 	return sa
 }
 
+// SetClock swaps the time source (buckets and cooldowns)
+func (sa *SignalAggregator) SetClock(c Clock) {
+	sa.mu.Lock()
+	sa.clock = clockOrReal(c)
+	sa.mu.Unlock()
+}
+
 // Ingest receives a sanitized public signal
 func (sa *SignalAggregator) Ingest(sig PublicSignal) {
 	sa.mu.Lock()
@@ -49,7 +58,7 @@ func (sa *SignalAggregator) Ingest(sig PublicSignal) {
 	if !exists {
 		bucket = &SignalBucket{
 			Signals:          []PublicSignal{},
-			StartTime:        time.Now(),
+			StartTime:        sa.clock.Now(),
 			AccumulatedCount: 0,
 		}
 		sa.symbolBuckets[sig.Symbol] = bucket
@@ -74,13 +83,13 @@ func (sa *SignalAggregator) flush() {
 	sa.mu.Lock()
 	defer sa.mu.Unlock()
 
-	now := time.Now()
+	now := sa.clock.Now()
 
 	for symbol, bucket := range sa.symbolBuckets {
 		// Check if bucket expired
 		if now.Sub(bucket.StartTime) >= sa.bucketDuration {
 			// PROCESS BUCKET
-			sa.processBucket(symbol, bucket)
+			sa.processBucket(symbol, bucket, now)
 
 			// Remove from map
 			delete(sa.symbolBuckets, symbol)
@@ -88,14 +97,14 @@ func (sa *SignalAggregator) flush() {
 	}
 }
 
-func (sa *SignalAggregator) processBucket(symbol string, bucket *SignalBucket) {
+func (sa *SignalAggregator) processBucket(symbol string, bucket *SignalBucket, now time.Time) {
 	if bucket.AccumulatedCount == 0 {
 		return
 	}
 
 	// CHECK COOLDOWN
 	if lastPush, ok := sa.pushCooldowns[symbol]; ok {
-		if time.Since(lastPush) < sa.cooldownDur {
+		if now.Sub(lastPush) < sa.cooldownDur {
 			// Cooldown active - Skip
 			log.Printf("⏳ AGGREGATOR: %s skipped (Cooldown active)", symbol)
 			return
@@ -117,7 +126,7 @@ func (sa *SignalAggregator) processBucket(symbol string, bucket *SignalBucket) {
 			EntryZone:  "VARIOUS",
 			Stars:      avgStars,
 			Volatility: bucket.Signals[0].Volatility,
-			Timestamp:  time.Now().Unix(),
+			Timestamp:  now.Unix(),
 		}
 
 		// Inject Summary Message logic here (usually handled by PushService formatting)
@@ -126,7 +135,7 @@ func (sa *SignalAggregator) processBucket(symbol string, bucket *SignalBucket) {
 		summarySig.EntryZone = "💰 HEAVY ACCUMULATION"
 
 		log.Printf("💰 AGGREGATOR: %s Heavy Accumulation (%d signals). Sending Summary.", symbol, bucket.AccumulatedCount)
-		sa.send(summarySig)
+		sa.send(summarySig, now)
 
 	} else {
 		// 2. NORMAL FLOW (Single Signal)
@@ -139,13 +148,13 @@ func (sa *SignalAggregator) processBucket(symbol string, bucket *SignalBucket) {
 		// "Filter out 'Crash Warnings' if ... < 40".
 		// We don't have raw Score here, only Stars. Assuming Distributor handled it.
 
-		sa.send(lastSig)
+		sa.send(lastSig, now)
 	}
 }
 
-func (sa *SignalAggregator) send(sig PublicSignal) {
+func (sa *SignalAggregator) send(sig PublicSignal, now time.Time) {
 	// Update Cooldown
-	sa.pushCooldowns[sig.Symbol] = time.Now()
+	sa.pushCooldowns[sig.Symbol] = now
 
 	// Pass to PushService
 	if sa.distributor.pushService != nil {
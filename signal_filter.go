@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"sync"
+	"time"
 )
 
 // SignalFilter validates potential trade signals against strict institutional criteria
@@ -12,10 +13,12 @@ type SignalFilter struct {
 	lastTradeTime map[string]int64   // Symbol -> Timestamp of last cleared trade
 
 	// Configuration
-	ClusterTimeWindow  int64   // e.g. 60000ms (1 minute)
-	ClusterPriceRange  float64 // e.g. 0.0015 (0.15%)
-	RequiredClusterCnt int     // e.g. 3
-	MinVolumeRatio     float64 // e.g. 1.5 (Buyers must outweigh Sellers 1.5x)
+	ClusterTimeWindow  int64         // e.g. 60000ms (1 minute)
+	ClusterPriceRange  float64       // e.g. 0.0015 (0.15%)
+	RequiredClusterCnt int           // e.g. 3
+	MinVolumeRatio     float64       // e.g. 1.5 (Buyers must outweigh Sellers 1.5x)
+	FlowWindow         time.Duration // e.g. 1m (CVD window the ratio is read from)
+	MinOIChangePct     float64       // e.g. 0.005 (OI +/-0.5% = new money / closes)
 }
 
 func NewSignalFilter() *SignalFilter {
@@ -26,33 +29,24 @@ func NewSignalFilter() *SignalFilter {
 		ClusterPriceRange:  0.0015,
 		RequiredClusterCnt: 3,
 		MinVolumeRatio:     1.5,
+		FlowWindow:         time.Minute,
+		MinOIChangePct:     0.005,
 	}
 }
 
 // Validate checks if a trade signal is part of a valid Institutional Cluster
+// flow is the candidate symbol's own CVD over FlowWindow.
+// oi (optional) weights the candidate: new money counts more than position closes
 // Returns: isValid, activeRatio, clusterScore
-func (sf *SignalFilter) Validate(candidate Trade, buyVol, sellVol float64, isIceberg bool, liquidationVol float64) (bool, float64, float64) {
+func (sf *SignalFilter) Validate(candidate Trade, flow CVDFlow, isIceberg bool, liquidationVol float64, oi *OIDelta) (bool, float64, float64) {
 	sf.mu.Lock()
 	defer sf.mu.Unlock()
 
 	symbol := candidate.Symbol
 	now := candidate.Timestamp
 
-	// Calculate Ratio early for reporting
-	activeRatio := 0.0
-	if candidate.Side == "buy" {
-		if sellVol > 0 {
-			activeRatio = buyVol / sellVol
-		} else {
-			activeRatio = 999.0 // Infinite
-		}
-	} else {
-		if buyVol > 0 {
-			activeRatio = sellVol / buyVol
-		} else {
-			activeRatio = 999.0 // Infinite
-		}
-	}
+	// Calculate Ratio early for reporting (this symbol's flow, not the market's)
+	activeRatio := flow.Ratio(candidate.Side)
 
 	// 0. PRIORITY OVERRIDE (Iceberg > $500k OR Iceberg + Liq > 10k)
 	// User req: Iceberg > $500k bypass
@@ -106,6 +100,15 @@ func (sf *SignalFilter) Validate(candidate Trade, buyVol, sellVol float64, isIce
 	if liquidationVol > 5000 {
 		candidateScore += 1.5
 	}
+	// Rising OI = Fresh Positions (+0.5). Falling OI = Closes / Short Covering (-0.5).
+	if oi != nil && sf.MinOIChangePct > 0 {
+		if oi.ChangePct >= sf.MinOIChangePct {
+			candidateScore += 0.5
+		} else if oi.ChangePct <= -sf.MinOIChangePct {
+			candidateScore -= 0.5
+			log.Printf("📉 SIGNAL FILTER: %s OI %.2f%% (positions closing). Weight reduced.", symbol, oi.ChangePct*100)
+		}
+	}
 
 	for _, t := range potentialCluster {
 		priceDiff := (t.Price - candidate.Price) / candidate.Price
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// SPOOF VERIFIER (Async Book Re-Check Before Approval)
+// ============================================================================
+// A validated iceberg signal is held for Delay, then the local book is read
+// again. The signal goes forward only if the liquidity it was built on is
+// still there:
+//
+//   1. Book in sync (no verdict from a stale book).
+//   2. Level held: the hidden ask still caps price (SHORT) / the hidden bid
+//      still floors it (LONG), within BandBPS.
+//   3. Not pulled: resting size near the level kept MinRetain of what was
+//      visible at detection (skipped when nothing was visible: pure hidden).
+//
+// Verification runs on timers, never on the trade goroutine. One candidate
+// per symbol/side is in flight; extras and overflow are dropped.
+//
+// With an event-time Clock (replay / backtest: SetClock) nothing runs on wall
+// timers: candidates wait in a queue until ResolveDue is called with the clock
+// past their due time, so the re-check sees the book Delay after detection.
+
+// SpoofVerifyConfig holds the re-check parameters
+type SpoofVerifyConfig struct {
+	Delay      time.Duration // Hold before re-reading the book (e.g. 800ms)
+	BandBPS    float64       // Level tolerance in bps (5 = 0.05%)
+	MinRetain  float64       // Resting notional kept near the level (0.5 = 50%)
+	MaxPending int           // Candidates in flight
+}
+
+// DefaultSpoofVerifyConfig is used until SetConfig is called
+var DefaultSpoofVerifyConfig = SpoofVerifyConfig{
+	Delay:      800 * time.Millisecond,
+	BandBPS:    5,
+	MinRetain:  0.5,
+	MaxPending: 64,
+}
+
+// SpoofVerifyStats counts verdicts (for /ping)
+type SpoofVerifyStats struct {
+	Pending   int   `json:"pending"`
+	Confirmed int64 `json:"confirmed"`
+	Rejected  int64 `json:"rejected"`
+	Dropped   int64 `json:"dropped"`
+}
+
+type spoofCandidate struct {
+	key         string // "BTCUSDT_SHORT"
+	cfg         SpoofVerifyConfig
+	due         time.Time // Event-time re-check (queued mode)
+	sig         Signal
+	bookSide    string  // Where the hidden liquidity sits: "ask" (SHORT) / "bid" (LONG)
+	level       float64 // Iceberg price
+	restingThen float64 // Visible notional near the level at detection
+}
+
+// SpoofVerifier re-checks candidates after a delay and forwards survivors
+type SpoofVerifier struct {
+	books   *OrderBookManager
+	forward func(Signal)
+
+	mu      sync.Mutex
+	cfg     SpoofVerifyConfig
+	pending map[string]bool // "BTCUSDT_SHORT" -> In flight
+	clock   Clock
+	queued  bool              // Event-time clock: re-checks wait for ResolveDue
+	queue   []*spoofCandidate // Oldest (earliest due) first
+
+	confirmed atomic.Int64
+	rejected  atomic.Int64
+	dropped   atomic.Int64
+}
+
+// NewSpoofVerifier creates a verifier; forward receives confirmed signals
+func NewSpoofVerifier(books *OrderBookManager, cfg SpoofVerifyConfig, forward func(Signal)) *SpoofVerifier {
+	return &SpoofVerifier{
+		books:   books,
+		forward: forward,
+		cfg:     cfg,
+		pending: make(map[string]bool),
+		clock:   RealClock,
+	}
+}
+
+// SetClock swaps the time source. Any clock but the wall clock queues
+// re-checks for ResolveDue instead of using timers.
+func (v *SpoofVerifier) SetClock(c Clock) {
+	v.mu.Lock()
+	v.clock = clockOrReal(c)
+	v.queued = v.clock != RealClock
+	v.mu.Unlock()
+}
+
+// ResolveDue runs every queued re-check whose due time has passed (event-time mode)
+func (v *SpoofVerifier) ResolveDue() {
+	v.mu.Lock()
+	now := v.clock.Now()
+	n := 0
+	for n < len(v.queue) && !v.queue[n].due.After(now) {
+		n++
+	}
+	due := append([]*spoofCandidate(nil), v.queue[:n]...)
+	v.queue = v.queue[n:]
+	v.mu.Unlock()
+
+	for _, c := range due {
+		v.resolve(c)
+	}
+}
+
+// SetConfig replaces the re-check parameters (applies to new candidates)
+func (v *SpoofVerifier) SetConfig(cfg SpoofVerifyConfig) {
+	v.mu.Lock()
+	v.cfg = cfg
+	v.mu.Unlock()
+}
+
+// Submit snapshots the level and schedules the re-check (never blocks)
+func (v *SpoofVerifier) Submit(sig Signal) {
+	key := sig.Symbol + "_" + sig.Side
+
+	v.mu.Lock()
+	cfg := v.cfg
+	queued, now := v.queued, v.clock.Now()
+	if v.pending[key] {
+		v.mu.Unlock()
+		v.dropped.Add(1)
+		log.Printf("⏳ SPOOF CHECK: %s %s already verifying. Dropped duplicate.", sig.Side, sig.Symbol)
+		return
+	}
+	if cfg.MaxPending > 0 && len(v.pending) >= cfg.MaxPending {
+		v.mu.Unlock()
+		v.dropped.Add(1)
+		log.Printf("⚠️ SPOOF CHECK: %d candidates in flight. Dropped %s %s.", len(v.pending), sig.Side, sig.Symbol)
+		return
+	}
+	v.pending[key] = true
+	v.mu.Unlock()
+
+	c := &spoofCandidate{key: key, cfg: cfg, due: now.Add(cfg.Delay), sig: sig, bookSide: "bid", level: sig.Entry}
+	if sig.Side == "SHORT" {
+		c.bookSide = "ask"
+	}
+	if v.books != nil {
+		c.restingThen = v.books.NotionalNear(sig.Symbol, c.bookSide, c.level, cfg.BandBPS/10000)
+	}
+
+	log.Printf("⏳ VERIFYING SPOOF (%s %s)... re-checking book in %v", sig.Side, sig.Symbol, cfg.Delay)
+	if queued {
+		v.mu.Lock()
+		v.queue = append(v.queue, c)
+		v.mu.Unlock()
+		return
+	}
+	time.AfterFunc(cfg.Delay, func() {
+		v.resolve(c)
+	})
+}
+
+// Stats returns the verdict counters
+func (v *SpoofVerifier) Stats() SpoofVerifyStats {
+	v.mu.Lock()
+	pending := len(v.pending)
+	v.mu.Unlock()
+	return SpoofVerifyStats{
+		Pending:   pending,
+		Confirmed: v.confirmed.Load(),
+		Rejected:  v.rejected.Load(),
+		Dropped:   v.dropped.Load(),
+	}
+}
+
+func (v *SpoofVerifier) resolve(c *spoofCandidate) {
+	err := v.check(c, c.cfg)
+
+	v.mu.Lock()
+	delete(v.pending, c.key)
+	v.mu.Unlock()
+
+	if err != nil {
+		v.rejected.Add(1)
+		log.Printf("👻 SPOOF CHECK FAILED: %s %s dropped (%v)", c.sig.Side, c.sig.Symbol, err)
+		return
+	}
+	v.confirmed.Add(1)
+	log.Printf("✅ SPOOF CHECK PASSED: %s %s @ %.6f still defended", c.sig.Side, c.sig.Symbol, c.level)
+	v.forward(c.sig)
+}
+
+// check re-reads the book and returns why the candidate failed (nil = confirmed)
+func (v *SpoofVerifier) check(c *spoofCandidate, cfg SpoofVerifyConfig) error {
+	if v.books == nil {
+		return nil // No book to verify against (forward as before)
+	}
+	depth, ok := v.books.BestBidAsk(c.sig.Symbol)
+	if !ok {
+		return fmt.Errorf("book not in sync")
+	}
+
+	band := cfg.BandBPS / 10000
+	if c.bookSide == "ask" && depth.BestAsk > c.level*(1+band) {
+		return fmt.Errorf("ask wall broken: best ask %.6f above %.6f", depth.BestAsk, c.level)
+	}
+	if c.bookSide == "bid" && depth.BestBid < c.level*(1-band) {
+		return fmt.Errorf("bid wall broken: best bid %.6f below %.6f", depth.BestBid, c.level)
+	}
+
+	if c.restingThen > 0 && cfg.MinRetain > 0 {
+		restingNow := v.books.NotionalNear(c.sig.Symbol, c.bookSide, c.level, band)
+		if restingNow < c.restingThen*cfg.MinRetain {
+			return fmt.Errorf("liquidity pulled: $%.0f -> $%.0f near %.6f", c.restingThen, restingNow, c.level)
+		}
+	}
+	return nil
+}
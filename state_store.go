@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// STATE STORE (Append-Only Journal + Snapshots)
+// ============================================================================
+// Engines persist their risk counters as one JSON value per key. Every Save is
+// appended to journal.jsonl and fsynced, so a crash loses nothing that was
+// acknowledged. Periodic snapshots compact the journal: snapshot.json is
+// written to a temp file and renamed, then the journal is truncated. Replaying
+// a journal over a newer snapshot is harmless (last write wins).
+
+const (
+	stateSnapshotFile   = "snapshot.json"
+	stateJournalFile    = "journal.jsonl"
+	stateCompactEvery   = 500 // Journal records before an early snapshot
+	stateSnapshotPeriod = 5 * time.Minute
+	stateRolloverCheck  = 30 * time.Second
+)
+
+// journalRecord is one line of journal.jsonl
+type journalRecord struct {
+	Key   string          `json:"k"`
+	Value json.RawMessage `json:"v"`
+	Time  int64           `json:"t"`
+}
+
+// StateStore is an embedded, file-backed key/value store for engine state
+type StateStore struct {
+	dir       string
+	resetHour int // Daily counters reset at this UTC hour
+
+	mu      sync.Mutex
+	state   map[string]json.RawMessage
+	journal *os.File
+	records int // Journal records since last snapshot
+
+	rolloverMu sync.Mutex
+	day        string
+	onRollover []func(day string)
+}
+
+// OpenStateStore loads snapshot + journal from dir (created if missing)
+func OpenStateStore(dir string, resetHourUTC int) (*StateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &StateStore{
+		dir:       dir,
+		resetHour: resetHourUTC,
+		state:     make(map[string]json.RawMessage),
+	}
+
+	// 1. Snapshot
+	if data, err := os.ReadFile(filepath.Join(dir, stateSnapshotFile)); err == nil {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return nil, fmt.Errorf("corrupt snapshot: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// 2. Replay Journal
+	replayed, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := os.OpenFile(filepath.Join(dir, stateJournalFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s.journal = journal
+	s.records = replayed
+	s.day = s.TradingDay(time.Now())
+
+	log.Printf("💾 STATE STORE: %s (%d keys, %d journal records, day %s)", dir, len(s.state), replayed, s.day)
+	return s, nil
+}
+
+func (s *StateStore) replay() (int, error) {
+	path := filepath.Join(s.dir, stateJournalFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	// A crash mid-append leaves a line without '\n': cut it so new records start clean
+	if complete := bytes.LastIndexByte(data, '\n') + 1; complete < len(data) {
+		log.Printf("⚠️ STATE STORE: Dropping torn journal tail (%d bytes)", len(data)-complete)
+		if err := os.Truncate(path, int64(complete)); err != nil {
+			return 0, err
+		}
+		data = data[:complete]
+	}
+
+	count := 0
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var rec journalRecord
+		if err := json.Unmarshal(line, &rec); err != nil || rec.Key == "" {
+			log.Printf("⚠️ STATE STORE: Skipping corrupt journal record #%d", count+1)
+			continue
+		}
+		s.state[rec.Key] = rec.Value
+		count++
+	}
+	return count, nil
+}
+
+// Load decodes the latest value for key into v. Returns false if the key was never saved.
+func (s *StateStore) Load(key string, v interface{}) bool {
+	s.mu.Lock()
+	raw, ok := s.state[key]
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		log.Printf("⚠️ STATE STORE: Cannot decode %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// Save durably records the value for key (journal append + fsync)
+func (s *StateStore) Save(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(journalRecord{Key: key, Value: value, Time: time.Now().UnixMilli()})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.journal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := s.journal.Sync(); err != nil {
+		return err
+	}
+	s.state[key] = value
+	s.records++
+
+	if s.records >= stateCompactEvery {
+		return s.snapshotLocked()
+	}
+	return nil
+}
+
+// Snapshot writes all keys atomically and truncates the journal
+func (s *StateStore) Snapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshotLocked()
+}
+
+func (s *StateStore) snapshotLocked() error {
+	if s.records == 0 {
+		return nil
+	}
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+
+	// Write temp + fsync + rename: readers see the old or the new snapshot, never half
+	path := filepath.Join(s.dir, stateSnapshotFile)
+	tmp, err := os.CreateTemp(s.dir, stateSnapshotFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if dir, err := os.Open(s.dir); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	// Snapshot is durable: start a fresh journal
+	if err := s.journal.Truncate(0); err != nil {
+		return err
+	}
+	s.records = 0
+	return nil
+}
+
+// TradingDay returns the trading day for t (days start at the reset hour, UTC)
+func (s *StateStore) TradingDay(t time.Time) string {
+	return t.UTC().Add(-time.Duration(s.resetHour) * time.Hour).Format("2006-01-02")
+}
+
+// OnRollover registers a callback fired when a new trading day starts
+func (s *StateStore) OnRollover(fn func(day string)) {
+	s.rolloverMu.Lock()
+	s.onRollover = append(s.onRollover, fn)
+	s.rolloverMu.Unlock()
+}
+
+// Start runs periodic snapshots and the daily rollover check
+func (s *StateStore) Start() {
+	go func() {
+		snapshotTicker := time.NewTicker(stateSnapshotPeriod)
+		rolloverTicker := time.NewTicker(stateRolloverCheck)
+		defer snapshotTicker.Stop()
+		defer rolloverTicker.Stop()
+
+		for {
+			select {
+			case <-snapshotTicker.C:
+				if err := s.Snapshot(); err != nil {
+					log.Printf("⚠️ STATE STORE: Snapshot failed: %v", err)
+				}
+			case now := <-rolloverTicker.C:
+				s.checkRollover(now)
+			}
+		}
+	}()
+}
+
+func (s *StateStore) checkRollover(now time.Time) {
+	day := s.TradingDay(now)
+
+	s.rolloverMu.Lock()
+	if day == s.day {
+		s.rolloverMu.Unlock()
+		return
+	}
+	s.day = day
+	callbacks := append([]func(string){}, s.onRollover...)
+	s.rolloverMu.Unlock()
+
+	log.Printf("🌅 NEW TRADING DAY (%s): Resetting daily counters", day)
+	for _, fn := range callbacks {
+		fn(day)
+	}
+}
+
+// Close writes a final snapshot and closes the journal
+func (s *StateStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.snapshotLocked(); err != nil {
+		return err
+	}
+	return s.journal.Close()
+}
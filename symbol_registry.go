@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// ============================================================================
+// SYMBOL REGISTRY (Shared Monitored Universe)
+// ============================================================================
+
+// DefaultSymbols is the fallback universe when neither config nor exchangeInfo is available
+var DefaultSymbols = []string{
+	"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT",
+	"SUIUSDT", "AVAXUSDT", "ADAUSDT", "DOGEUSDT", "LINKUSDT",
+	"HYPEUSDT", "FETUSDT", "TAOUSDT", "ARBUSDT", "OPUSDT",
+	"PEPEUSDT", "WIFUSDT", "SHIBUSDT", "TRXUSDT", "LTCUSDT",
+	"NEARUSDT", "INJUSDT", "APTUSDT", "RENDERUSDT", "SEIUSDT",
+}
+
+// SymbolRegistry holds the monitored symbols ("BTCUSDT") and notifies listeners on change
+type SymbolRegistry struct {
+	mu        sync.RWMutex
+	symbols   []string        // Ordered (volume rank or config order)
+	set       map[string]bool // Fast lookup
+	listeners []chan struct{} // Coalesced "universe changed" ticks
+	risk      *RiskManager    // Open positions pin their symbol (nil = no check)
+}
+
+// NewSymbolRegistry creates a registry seeded with symbols
+func NewSymbolRegistry(symbols []string) *SymbolRegistry {
+	r := &SymbolRegistry{set: make(map[string]bool)}
+	for _, s := range symbols {
+		r.add(s)
+	}
+	return r
+}
+
+// LoadSymbolRegistry builds the universe from config, falling back to the top
+// `size` USDT perpetuals by 24h quote volume, then to DefaultSymbols.
+func LoadSymbolRegistry(configured []string, size int) *SymbolRegistry {
+	if len(configured) > 0 {
+		log.Printf("📋 SYMBOL REGISTRY: %d symbols from config", len(configured))
+		return NewSymbolRegistry(configured)
+	}
+
+	ranked, err := fetchTopSymbolsByVolume(size)
+	if err != nil || len(ranked) == 0 {
+		log.Printf("⚠️ SYMBOL REGISTRY: exchangeInfo load failed (%v). Using defaults.", err)
+		return NewSymbolRegistry(DefaultSymbols)
+	}
+	log.Printf("📋 SYMBOL REGISTRY: Top %d by 24h volume: %s", len(ranked), strings.Join(ranked, ", "))
+	return NewSymbolRegistry(ranked)
+}
+
+// fetchTradableSymbols returns the TRADING USDT perpetuals from exchangeInfo
+func fetchTradableSymbols(client *futures.Client) (map[string]bool, error) {
+	info, err := client.NewExchangeInfoService().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	tradable := make(map[string]bool)
+	for _, s := range info.Symbols {
+		if s.Status == "TRADING" && s.ContractType == futures.ContractTypePerpetual && s.QuoteAsset == "USDT" {
+			tradable[s.Symbol] = true
+		}
+	}
+	return tradable, nil
+}
+
+// fetchTopSymbolsByVolume ranks TRADING USDT perpetuals by 24h quote volume
+func fetchTopSymbolsByVolume(size int) ([]string, error) {
+	// Universe is always mainnet (ExecutionService may have flipped the testnet flag)
+	client := binance.NewFuturesClient("", "").SetApiEndpoint(futures.BaseApiMainUrl)
+
+	tradable, err := fetchTradableSymbols(client)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := client.NewListPriceChangeStatsService().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	type ranked struct {
+		Symbol string
+		Volume float64
+	}
+	var candidates []ranked
+	for _, st := range stats {
+		if !tradable[st.Symbol] {
+			continue
+		}
+		vol, _ := strconv.ParseFloat(st.QuoteVolume, 64)
+		candidates = append(candidates, ranked{Symbol: st.Symbol, Volume: vol})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Volume > candidates[j].Volume })
+
+	if size > 0 && len(candidates) > size {
+		candidates = candidates[:size]
+	}
+	symbols := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		symbols = append(symbols, c.Symbol)
+	}
+	return symbols, nil
+}
+
+// Symbols returns a copy of the current universe ("BTCUSDT", ...)
+func (r *SymbolRegistry) Symbols() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, len(r.symbols))
+	copy(out, r.symbols)
+	return out
+}
+
+// Contains reports whether a symbol ("BTC" or "BTCUSDT") is monitored
+func (r *SymbolRegistry) Contains(symbol string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.set[NormalizeSymbol(symbol)]
+}
+
+// AttachRisk makes Remove refuse symbols an engine still holds a position on
+func (r *SymbolRegistry) AttachRisk(rm *RiskManager) {
+	r.mu.Lock()
+	r.risk = rm
+	r.mu.Unlock()
+}
+
+// Add inserts a TRADING USDT perpetual at runtime and notifies listeners
+func (r *SymbolRegistry) Add(symbol string) error {
+	symbol = NormalizeSymbol(strings.TrimSpace(symbol))
+	if r.Contains(symbol) {
+		return fmt.Errorf("%s already monitored", symbol)
+	}
+
+	// Validate against mainnet exchangeInfo: a typo would resubscribe every connector to dead streams
+	tradable, err := fetchTradableSymbols(binance.NewFuturesClient("", "").SetApiEndpoint(futures.BaseApiMainUrl))
+	if err != nil {
+		return fmt.Errorf("exchangeInfo unavailable, cannot validate %s: %v", symbol, err)
+	}
+	if !tradable[symbol] {
+		return fmt.Errorf("%s is not a TRADING USDT perpetual", symbol)
+	}
+
+	r.mu.Lock()
+	added := r.add(symbol)
+	r.mu.Unlock()
+
+	if !added {
+		return fmt.Errorf("%s already monitored", symbol)
+	}
+	log.Printf("➕ SYMBOL REGISTRY: Added %s", symbol)
+	r.notify()
+	return nil
+}
+
+// Remove drops a symbol at runtime and notifies listeners. A symbol with an
+// open position is refused: its feeds drive the trailing / breakeven logic.
+func (r *SymbolRegistry) Remove(symbol string) error {
+	symbol = NormalizeSymbol(symbol)
+
+	r.mu.Lock()
+	if !r.set[symbol] {
+		r.mu.Unlock()
+		return fmt.Errorf("%s not monitored", symbol)
+	}
+	if r.risk != nil {
+		for _, engine := range []string{RiskEngineExec, RiskEnginePredator} {
+			if side := r.risk.HeldSide(engine, symbol); side != "" {
+				r.mu.Unlock()
+				return fmt.Errorf("%s has an open %s %s position; close it first", symbol, engine, side)
+			}
+		}
+	}
+	delete(r.set, symbol)
+	for i, s := range r.symbols {
+		if s == symbol {
+			r.symbols = append(r.symbols[:i], r.symbols[i+1:]...)
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	log.Printf("➖ SYMBOL REGISTRY: Removed %s", symbol)
+	r.notify()
+	return nil
+}
+
+// Subscribe returns a channel that ticks (coalesced) whenever the universe changes
+func (r *SymbolRegistry) Subscribe() <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	r.mu.Lock()
+	r.listeners = append(r.listeners, ch)
+	r.mu.Unlock()
+	return ch
+}
+
+// add inserts without notifying (caller holds the lock)
+func (r *SymbolRegistry) add(symbol string) bool {
+	symbol = NormalizeSymbol(strings.TrimSpace(symbol))
+	if symbol == "USDT" || r.set[symbol] {
+		return false
+	}
+	Instruments.AddCanonical(symbol) // Pin it: venue prints of this asset resolve here
+	r.set[symbol] = true
+	r.symbols = append(r.symbols, symbol)
+	return true
+}
+
+func (r *SymbolRegistry) notify() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ch := range r.listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// closeOnChange closes conn when the registry changes so the caller's reconnect
+// loop resubscribes with the new universe. Call the returned func once the
+// read loop exits.
+func closeOnChange(changes <-chan struct{}, conn interface{ Close() error }, tag string) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-changes:
+			log.Printf("[%s] Symbol universe changed. Resubscribing...", tag)
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// drainChanges clears a pending change tick before the caller re-reads Symbols()
+func drainChanges(changes <-chan struct{}) {
+	select {
+	case <-changes:
+	default:
+	}
+}
@@ -22,12 +22,13 @@ const (
 
 // TrendAnalyzer handles technical analysis
 type TrendAnalyzer struct {
-	client *futures.Client
+	gateway    Gateway
+	RetryDelay time.Duration // Pause before the kline retry (0 for in-memory gateways)
 }
 
 // NewTrendAnalyzer creates the service
-func NewTrendAnalyzer(client *futures.Client) *TrendAnalyzer {
-	return &TrendAnalyzer{client: client}
+func NewTrendAnalyzer(gateway Gateway) *TrendAnalyzer {
+	return &TrendAnalyzer{gateway: gateway, RetryDelay: 500 * time.Millisecond}
 }
 
 // TrendResult holds the analysis
@@ -40,13 +41,9 @@ type TrendResult struct {
 	IsCounter bool
 }
 
-// NormalizeSymbol ensures the symbol ends with USDT
+// NormalizeSymbol returns the canonical instrument ID ("BTC" -> "BTCUSDT", "BTCUSDC", "1000PEPEUSDT")
 func NormalizeSymbol(symbol string) string {
-	symbol = strings.ToUpper(symbol)
-	if !strings.HasSuffix(symbol, "USDT") {
-		return symbol + "USDT"
-	}
-	return symbol
+	return Instruments.Canonical(symbol).Symbol
 }
 
 // GetMarketTrend analyzes multiple timeframes
@@ -91,19 +88,15 @@ func (ta *TrendAnalyzer) analyzeTimeframe(symbol string, interval string) TrendS
 	var err error
 
 	for i := 0; i < 2; i++ {
-		klines, err = ta.client.NewKlinesService().
-			Symbol(validSymbol).
-			Interval(interval).
-			Limit(30).
-			Do(context.Background())
+		klines, err = ta.gateway.Klines(context.Background(), validSymbol, interval, 30)
 
 		if err == nil && len(klines) >= 25 {
 			break // Success
 		}
 
 		// Wait before retry if failed
-		if i == 0 {
-			time.Sleep(500 * time.Millisecond)
+		if i == 0 && ta.RetryDelay > 0 {
+			time.Sleep(ta.RetryDelay)
 		}
 	}
 
@@ -135,11 +128,7 @@ func (ta *TrendAnalyzer) GetEMA(symbol string, interval string, period int) floa
 	validSymbol := NormalizeSymbol(symbol)
 
 	// Fetch Klines (needs at least period + 10 for smoothing)
-	klines, err := ta.client.NewKlinesService().
-		Symbol(validSymbol).
-		Interval(interval).
-		Limit(period + 20).
-		Do(context.Background())
+	klines, err := ta.gateway.Klines(context.Background(), validSymbol, interval, period+20)
 
 	if err != nil || len(klines) < period {
 		return 0.0
@@ -158,11 +147,7 @@ func (ta *TrendAnalyzer) GetEMA(symbol string, interval string, period int) floa
 func (ta *TrendAnalyzer) calculateRSI(symbol string, interval string, period int) float64 {
 	validSymbol := NormalizeSymbol(symbol)
 
-	klines, err := ta.client.NewKlinesService().
-		Symbol(validSymbol).
-		Interval(interval).
-		Limit(period * 2). // Need enough data
-		Do(context.Background())
+	klines, err := ta.gateway.Klines(context.Background(), validSymbol, interval, period*2) // Need enough data
 
 	if err != nil || len(klines) < period+1 {
 		return 50.0
@@ -199,11 +184,7 @@ func (ta *TrendAnalyzer) calculateRSI(symbol string, interval string, period int
 func (ta *TrendAnalyzer) CalculateATR(symbol string, interval string) float64 {
 	validSymbol := NormalizeSymbol(symbol)
 
-	klines, err := ta.client.NewKlinesService().
-		Symbol(validSymbol).
-		Interval(interval).
-		Limit(15).
-		Do(context.Background())
+	klines, err := ta.gateway.Klines(context.Background(), validSymbol, interval, 15)
 
 	if err != nil || len(klines) < 15 {
 		return 0.0
@@ -233,7 +214,7 @@ func (ta *TrendAnalyzer) IsHighVolatility(symbol string, interval string) bool {
 	atr := ta.CalculateATR(validSymbol, interval)
 
 	// Get Current Price
-	prices, _ := ta.client.NewListPricesService().Symbol(validSymbol).Do(context.Background())
+	prices, _ := ta.gateway.Prices(context.Background(), validSymbol)
 	if len(prices) == 0 {
 		return false
 	}
@@ -250,11 +231,7 @@ func (ta *TrendAnalyzer) CalculateVelocity(symbol string) float64 {
 	validSymbol := NormalizeSymbol(symbol)
 
 	// Get last 5 1m candles
-	klines, err := ta.client.NewKlinesService().
-		Symbol(validSymbol).
-		Interval("1m").
-		Limit(5).
-		Do(context.Background())
+	klines, err := ta.gateway.Klines(context.Background(), validSymbol, "1m", 5)
 
 	if err != nil || len(klines) < 2 {
 		return 0.0
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+// ============================================================================
+// USER DATA STREAM (listenKey)
+// ============================================================================
+// Pushes ORDER_TRADE_UPDATE / ACCOUNT_UPDATE / MARGIN_CALL to subscribers so
+// engines react to fills and liquidations without polling. The listenKey is
+// renewed every 30 minutes (Binance expires it after 60) and the socket is
+// redialed with a fresh key on any error or listenKeyExpired.
+//
+// Binance issues ONE listenKey per account: every manager on the same API key
+// (EXEC, PREDATOR) shares it. The key is therefore never closed on reconnect;
+// StartUserStream hands back the live key (or a new one once it expired).
+
+const userStreamKeepalive = 30 * time.Minute
+
+// UserStreamManager owns one listenKey + websocket for a Gateway
+type UserStreamManager struct {
+	gateway Gateway
+	tag     string // Log prefix ("EXEC", "PREDATOR")
+
+	mu          sync.RWMutex
+	subscribers []chan *futures.WsUserDataEvent
+	connected   bool
+}
+
+// NewUserStreamManager creates the manager (call Start to connect)
+func NewUserStreamManager(gateway Gateway, tag string) *UserStreamManager {
+	return &UserStreamManager{gateway: gateway, tag: tag}
+}
+
+// Subscribe returns a buffered channel of user data events (slow readers drop events)
+func (m *UserStreamManager) Subscribe() <-chan *futures.WsUserDataEvent {
+	ch := make(chan *futures.WsUserDataEvent, 256)
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, ch)
+	m.mu.Unlock()
+	return ch
+}
+
+// IsConnected reports whether the stream is currently live (callers may fall back to polling)
+func (m *UserStreamManager) IsConnected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.connected
+}
+
+// Start runs the connect / read / reconnect loop in the background
+func (m *UserStreamManager) Start() {
+	go m.run()
+}
+
+func (m *UserStreamManager) run() {
+	for {
+		listenKey, err := m.gateway.StartUserStream(context.Background())
+		if err != nil {
+			log.Printf("[UserStream %s] listenKey error: %v. Retrying in 5s...", m.tag, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		url := m.gateway.UserStreamURL(listenKey)
+		if url == "" {
+			log.Printf("[UserStream %s] Gateway has no user stream. Fill events disabled.", m.tag)
+			return
+		}
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			log.Printf("[UserStream %s] Connection error: %v. Retrying in 5s...", m.tag, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		log.Printf("📡 [UserStream %s] Connected (Fills / Account / Margin Calls)", m.tag)
+		m.setConnected(true)
+
+		// Keepalive: renew the listenKey until the read loop exits
+		done := make(chan struct{})
+		go m.keepalive(listenKey, conn, done)
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				log.Printf("[UserStream %s] Read error: %v. Reconnecting...", m.tag, err)
+				break
+			}
+
+			event := new(futures.WsUserDataEvent)
+			if err := json.Unmarshal(message, event); err != nil {
+				continue // Unknown event types (ALGO_UPDATE etc. on older schemas)
+			}
+
+			if event.Event == futures.UserDataEventTypeListenKeyExpired {
+				log.Printf("[UserStream %s] listenKey expired. Reconnecting...", m.tag)
+				break
+			}
+			m.publish(event)
+		}
+
+		close(done)
+		conn.Close()
+		m.setConnected(false)
+		time.Sleep(1 * time.Second)
+	}
+}
+
+// keepalive renews listenKey; a failed renewal closes conn to force a fresh key
+func (m *UserStreamManager) keepalive(listenKey string, conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(userStreamKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := m.gateway.KeepaliveUserStream(context.Background(), listenKey); err != nil {
+				log.Printf("⚠️ [UserStream %s] Keepalive failed: %v. Forcing reconnect.", m.tag, err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (m *UserStreamManager) publish(event *futures.WsUserDataEvent) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("⚠️ [UserStream %s] Subscriber full. Dropped %s.", m.tag, event.Event)
+		}
+	}
+}
+
+func (m *UserStreamManager) setConnected(connected bool) {
+	m.mu.Lock()
+	m.connected = connected
+	m.mu.Unlock()
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// WALL TRACKER (Order-Book Spoof Detection)
+// ============================================================================
+// Follows every large resting level near price from the depth diffs: when it
+// appeared, its peak size, and how much traded AT that price while it stood.
+// When the level goes (below GoneRatio of peak), the verdict is:
+//
+//   - WALL_ABSORBED: >= AbsorbedRatio of the peak traded at the level, or
+//     price printed through it. The wall was real and got eaten.
+//   - SPOOF:         the size vanished without trading. Pulled.
+//
+// Trades and depth arrive on separate streams, so a gone wall waits Grace
+// (event time) for late prints before it is judged. A wall that refills
+// within Grace is still standing (flicker, not a pull).
+//
+// Times are exchange event times: lifetimes are the same live and in replay.
+// After a book (re)load, levels already resting in the snapshot are seeded on
+// the next update with the snapshot time as their start.
+
+// WallConfig holds the wall thresholds
+type WallConfig struct {
+	MinNotional   float64       // Level notional to count as a wall (USD)
+	Multiple      float64       // ... and this many times the average level near price
+	BandPct       float64       // Only levels within this distance of price (0.01 = 1%)
+	GoneRatio     float64       // Below this fraction of peak = wall gone
+	AbsorbedRatio float64       // Traded / peak at or above this = absorbed
+	Grace         time.Duration // Late trade window before the verdict
+	Cooldown      time.Duration // Per symbol / alert type
+}
+
+// DefaultWallConfig is the live default
+var DefaultWallConfig = WallConfig{
+	MinNotional:   250000,
+	Multiple:      5,
+	BandPct:       0.01,
+	GoneRatio:     0.2,
+	AbsorbedRatio: 0.5,
+	Grace:         500 * time.Millisecond,
+	Cooldown:      30 * time.Second,
+}
+
+const wallAvgRefresh = 5 * time.Second // Average level size is recomputed this often (event time)
+
+type wallKey struct {
+	Side  string // "bid" / "ask"
+	Price float64
+}
+
+type wallState struct {
+	side      string
+	price     float64
+	appeared  int64 // Event time (ms)
+	qty       float64
+	peakQty   float64
+	tradedQty float64 // Printed at this price while standing
+	through   bool    // Price printed beyond the level
+	goneAt    int64   // 0 = standing
+}
+
+type wallSymbol struct {
+	walls     map[wallKey]*wallState
+	lastPrice float64 // Last trade (band reference)
+	avgLevel  float64 // Average level notional within the band
+	avgAt     int64
+	lastAlert map[string]int64 // Alert type -> event time
+	seed      bool             // Book reloaded: adopt resting walls on the next update
+	seedAt    int64            // Snapshot event time (ms)
+}
+
+// WallTracker watches large resting levels and classifies their exit
+type WallTracker struct {
+	books  *OrderBookManager
+	alerts chan<- Alert
+	cfg    WallConfig
+
+	mu      sync.Mutex
+	symbols map[string]*wallSymbol // "BTCUSDT" -> State
+}
+
+// NewWallTracker creates a tracker; wire OnLevels to the book and OnTrade to Binance trades
+func NewWallTracker(books *OrderBookManager, cfg WallConfig, alerts chan<- Alert) *WallTracker {
+	return &WallTracker{
+		books:   books,
+		alerts:  alerts,
+		cfg:     cfg,
+		symbols: make(map[string]*wallSymbol),
+	}
+}
+
+// OnTrade credits prints to the walls they hit (Binance trades: the book's venue)
+func (w *WallTracker) OnTrade(trade Trade) {
+	if trade.Exchange != "Binance" || trade.Price <= 0 {
+		return
+	}
+	symbol := NormalizeSymbol(trade.Symbol)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st := w.symbol(symbol)
+	st.lastPrice = trade.Price
+
+	for _, wall := range st.walls {
+		// Taker buys lift asks, taker sells hit bids
+		if (trade.Side == "buy") != (wall.side == "ask") {
+			continue
+		}
+		switch {
+		case samePrice(trade.Price, wall.price):
+			wall.tradedQty += trade.Size
+		case wall.side == "ask" && trade.Price > wall.price, wall.side == "bid" && trade.Price < wall.price:
+			wall.through = true
+		}
+	}
+	w.resolveLocked(symbol, st, trade.Timestamp)
+}
+
+// OnLevels follows the book's level changes (OrderBookManager.OnLevels)
+func (w *WallTracker) OnLevels(u LevelUpdate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if u.Reset {
+		// Book reloaded: what vanished in the gap is unknowable
+		st := w.symbol(u.Symbol)
+		st.walls = make(map[wallKey]*wallState)
+		st.seed, st.seedAt = true, u.EventTime
+		return
+	}
+
+	st := w.symbol(u.Symbol)
+	if st.lastPrice <= 0 {
+		return // No reference price yet
+	}
+	if st.seed {
+		st.avgAt = 0 // Fresh average for the fresh book
+	}
+	threshold := w.thresholdLocked(u.Symbol, st, u.EventTime)
+	if st.seed {
+		st.seed = false
+		w.seedLocked(u.Symbol, st, threshold, u.EventTime)
+	}
+
+	for _, c := range u.Changes {
+		key := wallKey{Side: c.Side, Price: c.Price}
+		wall, tracked := st.walls[key]
+
+		if !tracked {
+			if c.Qty*c.Price < threshold || math.Abs(c.Price-st.lastPrice)/st.lastPrice > w.cfg.BandPct {
+				continue
+			}
+			st.walls[key] = &wallState{side: c.Side, price: c.Price, appeared: u.EventTime, qty: c.Qty, peakQty: c.Qty}
+			continue
+		}
+
+		wall.qty = c.Qty
+		if c.Qty > wall.peakQty {
+			wall.peakQty = c.Qty
+		}
+		if c.Qty < wall.peakQty*w.cfg.GoneRatio {
+			if wall.goneAt == 0 {
+				wall.goneAt = u.EventTime
+			}
+		} else {
+			wall.goneAt = 0 // Refilled within Grace: still standing
+		}
+	}
+
+	// Price walked away: stop following (a far cancel is a requote, not a spoof)
+	for key, wall := range st.walls {
+		if wall.goneAt == 0 && math.Abs(wall.price-st.lastPrice)/st.lastPrice > 2*w.cfg.BandPct {
+			delete(st.walls, key)
+		}
+	}
+	w.resolveLocked(u.Symbol, st, u.EventTime)
+}
+
+// resolveLocked judges walls that have been gone longer than Grace (caller holds the lock)
+func (w *WallTracker) resolveLocked(symbol string, st *wallSymbol, now int64) {
+	grace := w.cfg.Grace.Milliseconds()
+	for key, wall := range st.walls {
+		if wall.goneAt == 0 || now-wall.goneAt < grace {
+			continue
+		}
+		delete(st.walls, key)
+		w.emitLocked(symbol, st, wall)
+	}
+}
+
+func (w *WallTracker) emitLocked(symbol string, st *wallSymbol, wall *wallState) {
+	lifetime := time.Duration(wall.goneAt-wall.appeared) * time.Millisecond
+	peakNotional := wall.peakQty * wall.price
+	filled := 0.0
+	if wall.peakQty > 0 {
+		filled = math.Min(wall.tradedQty/wall.peakQty, 1)
+	}
+
+	alertType := "SPOOF"
+	if filled >= w.cfg.AbsorbedRatio || wall.through {
+		alertType = "WALL_ABSORBED"
+	}
+	if last, ok := st.lastAlert[alertType]; ok && wall.goneAt-last < w.cfg.Cooldown.Milliseconds() {
+		return
+	}
+	st.lastAlert[alertType] = wall.goneAt
+
+	shortSym := Instruments.Canonical(symbol).Key() // "BTC", "BTCUSDC"
+	priceStr := fmt.Sprintf("$%.2f", wall.price)
+	if wall.price < 1.0 {
+		priceStr = fmt.Sprintf("$%.8f", wall.price)
+	}
+
+	var msg string
+	level := 4
+	if alertType == "SPOOF" {
+		msg = fmt.Sprintf("👻 SPOOF: $%.0f %s wall on %s at %s pulled after %.1fs (%.0f%% filled)", peakNotional, wall.side, shortSym, priceStr, lifetime.Seconds(), filled*100)
+		if peakNotional >= 2*w.cfg.MinNotional {
+			level = 5
+		}
+	} else {
+		msg = fmt.Sprintf("🧱 WALL ABSORBED: $%.0f %s wall on %s at %s eaten after %.1fs (%.0f%% filled)", peakNotional, wall.side, shortSym, priceStr, lifetime.Seconds(), filled*100)
+	}
+	log.Println(msg)
+
+	if w.alerts == nil {
+		return
+	}
+	// Side: the side the wall stood on as a trade side (bid wall = buyers)
+	side := "buy"
+	if wall.side == "ask" {
+		side = "sell"
+	}
+	alert := Alert{
+		Type:           alertType,
+		Level:          level,
+		Symbol:         shortSym,
+		Message:        msg,
+		FormattedValue: fmt.Sprintf("%.1fs", lifetime.Seconds()),
+		Data: Trade{
+			Symbol:    shortSym,
+			Price:     wall.price,
+			Size:      wall.peakQty,
+			Notional:  peakNotional,
+			Side:      side,
+			Exchange:  "Binance",
+			Timestamp: wall.goneAt,
+		},
+		Volume: peakNotional,
+		Ratio:  filled,
+	}
+	select {
+	case w.alerts <- alert:
+	default:
+		log.Printf("⚠️ WALL TRACKER: Alert channel full, dropped %s %s", alertType, shortSym)
+	}
+}
+
+// seedLocked starts tracking the walls already resting in the book (caller holds the lock)
+func (w *WallTracker) seedLocked(symbol string, st *wallSymbol, threshold float64, now int64) {
+	if w.books == nil {
+		return
+	}
+	since := st.seedAt
+	if since <= 0 || since > now {
+		since = now
+	}
+	bids, asks := w.books.LevelsWithin(symbol, w.cfg.BandPct)
+	for side, levels := range map[string][]BookLevel{"bid": bids, "ask": asks} {
+		for _, l := range levels {
+			if l.Qty*l.Price < threshold {
+				continue
+			}
+			st.walls[wallKey{Side: side, Price: l.Price}] = &wallState{side: side, price: l.Price, appeared: since, qty: l.Qty, peakQty: l.Qty}
+		}
+	}
+}
+
+// thresholdLocked is the wall size for symbol: MinNotional or Multiple x the average level
+func (w *WallTracker) thresholdLocked(symbol string, st *wallSymbol, now int64) float64 {
+	if w.books != nil && w.cfg.Multiple > 0 && now-st.avgAt >= wallAvgRefresh.Milliseconds() {
+		st.avgAt = now
+		bids, asks := w.books.LevelsWithin(symbol, w.cfg.BandPct)
+		total, n := 0.0, 0
+		for _, l := range append(bids, asks...) {
+			total += l.Price * l.Qty
+			n++
+		}
+		if n > 0 {
+			st.avgLevel = total / float64(n)
+		}
+	}
+	return math.Max(w.cfg.MinNotional, w.cfg.Multiple*st.avgLevel)
+}
+
+func (w *WallTracker) symbol(symbol string) *wallSymbol {
+	st, ok := w.symbols[symbol]
+	if !ok {
+		st = &wallSymbol{walls: make(map[wallKey]*wallState), lastAlert: make(map[string]int64)}
+		w.symbols[symbol] = st
+	}
+	return st
+}
+
+// samePrice compares prices that went through different float paths (book vs normalized trade)
+func samePrice(a, b float64) bool {
+	return math.Abs(a-b) <= math.Max(math.Abs(a), math.Abs(b))*1e-9
+}